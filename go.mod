@@ -2,7 +2,10 @@ module github.com/William-Bohm/langchain-go
 
 go 1.20
 
-require github.com/tiktoken-go/tokenizer v0.1.0
+require (
+	github.com/mattn/go-sqlite3 v1.14.17
+	github.com/tiktoken-go/tokenizer v0.1.0
+)
 
 require (
 	cloud.google.com/go v0.110.0 // indirect
@@ -65,6 +68,7 @@ require (
 	github.com/jackc/pgservicefile v0.0.0-20221227161230-091c0ba34f0a // indirect
 	github.com/jackc/pgtype v1.14.0 // indirect
 	github.com/jackc/pgx/v4 v4.18.1 // indirect
+	github.com/jackc/puddle v1.3.0 // indirect
 	github.com/jbenet/go-context v0.0.0-20150711004518-d14ea06fba99 // indirect
 	github.com/jmespath/go-jmespath v0.4.0 // indirect
 	github.com/joho/godotenv v1.5.1 // indirect
@@ -1,6 +1,7 @@
 package agentSchema
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"github.com/William-Bohm/langchain-go/langchain-go/callbacks/callbackSchema"
@@ -45,13 +46,17 @@ func (a *Agent) _ConstructScratchpad(intermediateSteps []AgentAction) string {
 	return thoughts
 }
 
-func (a *Agent) Plan(intermediateSteps []AgentAction, kwargs map[string]interface{}) (AgentAction, error) {
+func (a *Agent) Plan(intermediateSteps []AgentAction, kwargs map[string]interface{}) (interface{}, error) {
+	return a.PlanWithContext(context.Background(), intermediateSteps, kwargs)
+}
+
+func (a *Agent) PlanWithContext(ctx context.Context, intermediateSteps []AgentAction, kwargs map[string]interface{}) (interface{}, error) {
 	fullInputs := a.GetFullInputs(intermediateSteps, kwargs)
-	fullOutput, err := a.llmChain.Predict(fullInputs)
+	fullOutput, err := a.llmChain.PredictWithContext(ctx, fullInputs)
 	if err != nil {
-		return AgentAction{}, err
+		return nil, err
 	}
-	return a.outputParser.Parse(fullOutput).(AgentAction), nil
+	return a.outputParser.Parse(fullOutput), nil
 }
 
 func (a *Agent) GetFullInputs(intermediateSteps []AgentAction, kwargs map[string]interface{}) map[string]interface{} {
@@ -1,15 +1,11 @@
 package agentSchema
 
-type AgentAction struct {
-	Tool      string
-	ToolInput interface{}
-	Log       string
-}
+import "github.com/William-Bohm/langchain-go/langchain-go/rootSchema"
 
-type AgentFinish struct {
-	ReturnValues map[string]interface{}
-	Log          string
-}
+// AgentAction and AgentFinish live in rootSchema so callbacks can take them without importing agents.
+type AgentAction = rootSchema.AgentAction
+
+type AgentFinish = rootSchema.AgentFinish
 
 type AgentStep struct {
 	AgentAction
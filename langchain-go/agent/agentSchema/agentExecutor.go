@@ -1,6 +1,7 @@
 package agentSchema
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"github.com/William-Bohm/langchain-go/langchain-go/callbacks/callbackSchema"
@@ -24,7 +25,7 @@ type AgentExecutor struct {
 }
 
 func NewAgentExecutor(agent BaseAgent, tools []toolSchema.BaseTool, callbackManager callbackSchema.BaseCallbackManager, verbose bool) *AgentExecutor {
-	return &AgentExecutor{
+	a := &AgentExecutor{
 		BaseChain: chains.BaseChain{
 			Memory:          memory.NewConversationBufferMemory(chatMessageHistories.NewChatMessageHistory()),
 			CallbackManager: callbackManager,
@@ -37,6 +38,8 @@ func NewAgentExecutor(agent BaseAgent, tools []toolSchema.BaseTool, callbackMana
 		maxExecutionTime:        0,
 		earlyStoppingMethod:     "force",
 	}
+	a.CallFunc = a.call
+	return a
 }
 
 func (a *AgentExecutor) Save(filePath string) error {
@@ -78,14 +81,23 @@ func (a *AgentExecutor) ShouldContinue(iterations int, timeElapsed float64) bool
 }
 
 func (a *AgentExecutor) TakeNextStep(nameToToolMap map[string]toolSchema.BaseTool, colorMapping map[string]string, inputs map[string]interface{}, intermediateSteps []IntermediateStep) (interface{}, error) {
-	output := a.agent.(BaseAgent).Plan(intermediateSteps, inputs)
+	return a.TakeNextStepWithContext(context.Background(), nameToToolMap, colorMapping, inputs, intermediateSteps)
+}
+
+func (a *AgentExecutor) TakeNextStepWithContext(ctx context.Context, nameToToolMap map[string]toolSchema.BaseTool, colorMapping map[string]string, inputs map[string]interface{}, intermediateSteps []IntermediateStep) (interface{}, error) {
+	output, err := a.agent.(BaseAgent).PlanWithContext(ctx, intermediateSteps, inputs)
+	if err != nil {
+		return nil, err
+	}
 	switch v := output.(type) {
 	case AgentFinish:
 		return v, nil
 	case AgentAction:
 		return a.runTool(nameToToolMap, colorMapping, inputs, []AgentAction{v})
+	case []AgentAction:
+		return a.runTool(nameToToolMap, colorMapping, inputs, v)
 	default:
-		return a.runTool(nameToToolMap, colorMapping, inputs, v.([]AgentAction))
+		return nil, fmt.Errorf("agent plan returned %T, expected AgentAction, []AgentAction or AgentFinish", output)
 	}
 }
 
@@ -109,6 +121,70 @@ func (a *AgentExecutor) runTool(nameToToolMap map[string]toolSchema.BaseTool, co
 }
 
 func (a *AgentExecutor) Call(inputs map[string]interface{}) (map[string]interface{}, error) {
+	return a.CallWithContext(context.Background(), inputs)
+}
+
+// CallWithContext runs the agent loop until it finishes, hits its limits or ctx is done.
+// A done context stops the loop between steps and cancels any in-flight LLM request.
+func (a *AgentExecutor) CallWithContext(ctx context.Context, inputs map[string]interface{}) (map[string]interface{}, error) {
+	return a.call(ctx, inputs)
+}
+
+// Execute, Run, Apply and ApplyConcurrent are redeclared because the embedded Chain and BaseChain
+// both have them, which leaves them ambiguous. They run the agent loop through BaseChain.CallFunc.
+
+func (a *AgentExecutor) Execute(inputs map[string]interface{}, returnOnlyOutputs bool) (interface{}, error) {
+	return a.BaseChain.ExecuteWithContext(context.Background(), inputs, returnOnlyOutputs)
+}
+
+func (a *AgentExecutor) ExecuteWithContext(ctx context.Context, inputs map[string]interface{}, returnOnlyOutputs bool) (interface{}, error) {
+	return a.BaseChain.ExecuteWithContext(ctx, inputs, returnOnlyOutputs)
+}
+
+func (a *AgentExecutor) Run(args ...interface{}) (string, error) {
+	return a.RunWithContext(context.Background(), args...)
+}
+
+// RunWithContext runs the agent on a single input map and returns its only output.
+func (a *AgentExecutor) RunWithContext(ctx context.Context, args ...interface{}) (string, error) {
+	outputKeys := a.OutputKeys()
+	if len(outputKeys) != 1 {
+		return "", errors.New("`Run` not supported when there is not exactly one output key. Got " + fmt.Sprint(outputKeys))
+	}
+	if len(args) > 1 {
+		return "", errors.New("`Run` supported with either one positional argument or no arguments but not more than one. Got args: " + fmt.Sprintf("%v", args))
+	}
+	var inputs map[string]interface{}
+	if len(args) == 1 {
+		var ok bool
+		if inputs, ok = args[0].(map[string]interface{}); !ok {
+			return "", fmt.Errorf("`Run` takes a map[string]interface{}, got %T", args[0])
+		}
+	}
+	outputs, err := a.BaseChain.CallWithContext(ctx, inputs)
+	if err != nil {
+		return "", err
+	}
+	output, ok := outputs[outputKeys[0]].(string)
+	if !ok {
+		return "", fmt.Errorf("output %s is a %T, not a string", outputKeys[0], outputs[outputKeys[0]])
+	}
+	return output, nil
+}
+
+func (a *AgentExecutor) Apply(inputList []map[string]interface{}) ([]map[string]string, error) {
+	return a.BaseChain.ApplyWithContext(context.Background(), inputList)
+}
+
+func (a *AgentExecutor) ApplyWithContext(ctx context.Context, inputList []map[string]interface{}) ([]map[string]string, error) {
+	return a.BaseChain.ApplyWithContext(ctx, inputList)
+}
+
+func (a *AgentExecutor) ApplyConcurrent(ctx context.Context, inputList []map[string]interface{}, maxWorkers int) []chains.ApplyResult {
+	return a.BaseChain.ApplyConcurrent(ctx, inputList, maxWorkers)
+}
+
+func (a *AgentExecutor) call(ctx context.Context, inputs map[string]interface{}) (map[string]interface{}, error) {
 	nameToToolMap := make(map[string]toolSchema.BaseTool)
 	var colors []string
 	for _, tool := range a.tools {
@@ -120,7 +196,10 @@ func (a *AgentExecutor) Call(inputs map[string]interface{}) (map[string]interfac
 	iterations := 0
 	startTime := time.Now()
 	for a.ShouldContinue(iterations, float64(time.Since(startTime).Milliseconds())/1000) {
-		nextStepOutput, err := a.TakeNextStep(nameToToolMap, colorMapping, inputs, intermediateSteps)
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		nextStepOutput, err := a.TakeNextStepWithContext(ctx, nameToToolMap, colorMapping, inputs, intermediateSteps)
 		if err != nil {
 			return nil, err
 		}
@@ -1,6 +1,7 @@
 package agentSchema
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"os"
@@ -15,7 +16,9 @@ type BaseMultiActionAgent struct {
 type BaseAgent interface {
 	ReturnValues() []string
 	GetAllowedTools() []string
-	Plan(intermediateSteps []IntermediateStep, kwargs map[string]interface{}) (AgentAction, error)
+	// Plan returns the next AgentAction, a []AgentAction, or an AgentFinish
+	Plan(intermediateSteps []IntermediateStep, kwargs map[string]interface{}) (interface{}, error)
+	PlanWithContext(ctx context.Context, intermediateSteps []IntermediateStep, kwargs map[string]interface{}) (interface{}, error)
 	InputKeys() []string
 	ReturnStoppedResponse(earlyStoppingMethod string, intermediateSteps []IntermediateStep, kwargs map[string]interface{}) (AgentFinish, error)
 	AgentType() string
@@ -11,7 +11,7 @@ import (
 )
 
 type SingleActionAgent interface {
-	Plan(intermediateSteps []IntermediateStep, kwargs map[string]interface{}) (AgentAction, error)
+	Plan(intermediateSteps []IntermediateStep, kwargs map[string]interface{}) (interface{}, error)
 	InputKeys() []string
 	Dict(kwargs map[string]interface{}) map[string]interface{}
 }
@@ -21,9 +21,9 @@ type BaseSingleActionAgent struct {
 	BaseAgent
 }
 
-func (b *BaseSingleActionAgent) FromConfig(config map[string]interface{}) *BaseSingleActionAgent {
+func (b *BaseSingleActionAgent) FromConfig(config map[string]interface{}) (BaseAgent, error) {
 	//  abstract class
-	return &BaseSingleActionAgent{}
+	return nil, errors.New("not implemented")
 }
 
 func (b *BaseSingleActionAgent) ReturnValues() []string {
@@ -45,7 +45,7 @@ func (b *BaseSingleActionAgent) ReturnStoppedResponse(earlyStoppingMethod string
 	}
 }
 
-func (b *BaseSingleActionAgent) FromLLMAndTools(llm llmSchema.BaseLanguageModel, tools []toolSchema.BaseTool, callbackManger callbackSchema.BaseCallbackManager, kwargs map[string]interface{}) (*BaseSingleActionAgent, error) {
+func (b *BaseSingleActionAgent) FromLLMAndTools(llm llmSchema.BaseLanguageModel, tools []toolSchema.BaseTool, callbackManger callbackSchema.BaseCallbackManager, kwargs map[string]interface{}) (BaseAgent, error) {
 	return nil, errors.New("not implemented")
 }
 
@@ -0,0 +1,90 @@
+package agentSchema
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/William-Bohm/langchain-go/langchain-go/llm/openai/openaiClient"
+	"github.com/William-Bohm/langchain-go/langchain-go/rootSchema"
+	"github.com/William-Bohm/langchain-go/langchain-go/tools/toolSchema"
+)
+
+// OpenAIFunctionsAgentOutputParser turns chat model replies into agent steps using the structured
+// function_call / tool_calls fields of the message instead of parsing the text.
+type OpenAIFunctionsAgentOutputParser struct{}
+
+// Parse only sees text, and a reply without a function call is the final answer.
+func (p *OpenAIFunctionsAgentOutputParser) Parse(text string) interface{} {
+	return AgentFinish{
+		ReturnValues: map[string]interface{}{"output": text},
+		Log:          text,
+	}
+}
+
+// ParseAIMessage returns an AgentAction for a function_call, a []AgentAction for tool_calls, and an
+// AgentFinish holding the message content when the model did not call anything.
+func (p *OpenAIFunctionsAgentOutputParser) ParseAIMessage(message *rootSchema.AIMessage) (interface{}, error) {
+	if functionCall, ok := message.AdditionalKwargs["function_call"].(*openaiClient.FunctionCall); ok {
+		return functionCallToAgentAction(*functionCall, message.Content, message)
+	}
+
+	if toolCalls, ok := message.AdditionalKwargs["tool_calls"].([]openaiClient.ToolCall); ok && len(toolCalls) > 0 {
+		actions := make([]AgentAction, len(toolCalls))
+		for i, toolCall := range toolCalls {
+			// replay every call as its own function_call message so it can be answered by a function message
+			callMessage := rootSchema.NewAIMessage("")
+			functionCall := toolCall.Function
+			callMessage.AdditionalKwargs["function_call"] = &functionCall
+
+			action, err := functionCallToAgentAction(toolCall.Function, message.Content, callMessage)
+			if err != nil {
+				return nil, err
+			}
+			actions[i] = action
+		}
+		return actions, nil
+	}
+
+	return p.Parse(message.Content), nil
+}
+
+func functionCallToAgentAction(functionCall openaiClient.FunctionCall, content string, message *rootSchema.AIMessage) (AgentAction, error) {
+	toolInput, err := parseFunctionArguments(functionCall.Arguments)
+	if err != nil {
+		return AgentAction{}, fmt.Errorf("could not parse tool input for %s: %w", functionCall.Name, err)
+	}
+
+	contentMsg := ""
+	if content != "" {
+		contentMsg = fmt.Sprintf("responded: %s\n", content)
+	}
+	log := fmt.Sprintf("\nInvoking: `%s` with `%v`\n%s\n", functionCall.Name, toolInput, contentMsg)
+
+	return AgentAction{
+		Tool:       functionCall.Name,
+		ToolInput:  toolInput,
+		Log:        log,
+		MessageLog: []rootSchema.BaseMessageInterface{message},
+	}, nil
+}
+
+// parseFunctionArguments decodes the JSON arguments of a call. Single-input tools get their
+// SingleInputArgName argument back as a plain string, everything else a map.
+func parseFunctionArguments(arguments string) (interface{}, error) {
+	if arguments == "" {
+		return map[string]interface{}{}, nil
+	}
+
+	var args map[string]interface{}
+	if err := json.Unmarshal([]byte(arguments), &args); err != nil {
+		return nil, err
+	}
+
+	if arg, ok := args[toolSchema.SingleInputArgName]; ok && len(args) == 1 {
+		if s, ok := arg.(string); ok {
+			return s, nil
+		}
+		return fmt.Sprint(arg), nil
+	}
+	return args, nil
+}
@@ -9,4 +9,5 @@ const (
 	conversationalReactDescription     AgentType = "conversational-react-description"
 	chatZeroShotReactDescription       AgentType = "chat-zero-shot-react-description"
 	chatConversationalReactDescription AgentType = "chat-conversational-react-description"
+	openaiFunctions                    AgentType = "openai-functions"
 )
@@ -4,13 +4,14 @@ import (
 	"errors"
 	"github.com/William-Bohm/langchain-go/langchain-go/agent/agentSchema"
 	"github.com/William-Bohm/langchain-go/langchain-go/callbacks/callbackSchema"
-	"github.com/William-Bohm/langchain-go/langchain-go/llm/llmSchema"
 	"github.com/William-Bohm/langchain-go/langchain-go/tools/toolSchema"
 )
 
+// InitializeAgent builds an AgentExecutor for the agent type or saved agent. llm is passed to the
+// agent class as is, see AgentClass for the model each type expects.
 func InitializeAgent(
 	tools []toolSchema.BaseTool,
-	llm llmSchema.BaseLanguageModel,
+	llm interface{},
 	agent AgentType,
 	callbackManager callbackSchema.BaseCallbackManager,
 	agentPath string,
@@ -29,68 +29,77 @@ var (
 	hubPathRe  = regexp.MustCompile(`lc(?P<ref>@[^:]+)?://(?P<path>.*)`)
 )
 
-var AGENT_TO_CLASS = map[AgentType]agentSchema.BaseSingleActionAgent{
+// AgentClass builds agents of one registered type, from a model and tools or from a saved config.
+// The model is an llmSchema.BaseLanguageModel for the text agents and a FunctionCallingModel, such
+// as chat_models.ChatOpenAI, for the openai-functions agent, so each class checks the type it needs.
+type AgentClass interface {
+	FromLLMAndTools(llm interface{}, tools []toolSchema.BaseTool, callbackManager callbackSchema.BaseCallbackManager, kwargs map[string]interface{}) (agentSchema.BaseAgent, error)
+	FromConfig(config map[string]interface{}) (agentSchema.BaseAgent, error)
+}
+
+var AGENT_TO_CLASS = map[AgentType]AgentClass{
 	zeroShotReactDescription:           &ZeroShotAgent{},
 	reactDocstore:                      &ReActDocstoreAgent{},
 	selfAskWithSearch:                  &SelfAskWithSearchAgent{},
 	conversationalReactDescription:     &ConversationalAgent{},
 	chatZeroShotReactDescription:       &ChatAgent{},
 	chatConversationalReactDescription: &ConversationalChatAgent{},
+	openaiFunctions:                    &OpenAIFunctionsAgent{},
 }
 
 const URL_BASE = "https://raw.githubusercontent.com/hwchase17/langchain-hub/master/agents/"
 
-func LoadAgentFromTools(config map[string]interface{}, llm llmSchema.BaseLLM, tools []toolSchema.BaseTool, kwargs map[string]interface{}) (*agentSchema.BaseSingleActionAgent, error) {
+func LoadAgentFromTools(config map[string]interface{}, llm llmSchema.BaseLLM, tools []toolSchema.BaseTool, kwargs map[string]interface{}) (agentSchema.BaseAgent, error) {
 	configType := config["_type"].(AgentType)
 	delete(config, "_type")
 	if _, ok := AGENT_TO_CLASS[configType]; !ok {
-		return &agentSchema.BaseSingleActionAgent{}, errors.New("Loading " + string(configType) + " agent not supported")
+		return nil, errors.New("Loading " + string(configType) + " agent not supported")
 	}
 	agentClass := AGENT_TO_CLASS[configType]
 	combinedConfig := mapTools.MergeMaps(config, kwargs)
 	return agentClass.FromLLMAndTools(llm, tools, combinedConfig["callbackManger"].(callbackSchema.BaseCallbackManager), combinedConfig)
 }
 
-func LoadAgentFromConfig(config map[string]interface{}, llm llmSchema.BaseLLM, tools []toolSchema.BaseTool, kwargs map[string]interface{}) (*agentSchema.BaseSingleActionAgent, error) {
+func LoadAgentFromConfig(config map[string]interface{}, llm llmSchema.BaseLLM, tools []toolSchema.BaseTool, kwargs map[string]interface{}) (agentSchema.BaseAgent, error) {
 	var err error
 	if _, ok := config["_type"]; !ok {
-		return &agentSchema.BaseSingleActionAgent{}, errors.New("Must specify an agent Type in config")
+		return nil, errors.New("Must specify an agent Type in config")
 	}
 	loadFromTools := config["load_from_llm_and_tools"].(bool)
 	delete(config, "load_from_llm_and_tools")
 	if loadFromTools {
 		if llm.Id == "" && llm.CallbackManager == "" {
-			return &agentSchema.BaseSingleActionAgent{}, errors.New("If `load_from_llm_and_tools` is set to True, then LLM must be provided. Make sure the LLM has an assigned ID and/or CallbackManger!")
+			return nil, errors.New("If `load_from_llm_and_tools` is set to True, then LLM must be provided. Make sure the LLM has an assigned ID and/or CallbackManger!")
 		}
 		if tools == nil {
-			return &agentSchema.BaseSingleActionAgent{}, errors.New("If `load_from_llm_and_tools` is set to True, then tools must be provided")
+			return nil, errors.New("If `load_from_llm_and_tools` is set to True, then tools must be provided")
 		}
 		return LoadAgentFromTools(config, llm, tools, kwargs)
 	}
 	configType := config["_type"].(AgentType)
 	delete(config, "_type")
 	if _, ok := AGENT_TO_CLASS[configType]; !ok {
-		return &agentSchema.BaseSingleActionAgent{}, errors.New("Loading " + string(configType) + " agent not supported")
+		return nil, errors.New("Loading " + string(configType) + " agent not supported")
 	}
 	agentClass := AGENT_TO_CLASS[configType]
 	if _, ok := config["llm_chain"]; ok {
 		config["llm_chain"], err = chains.LoadChainFromConfig(config["llm_chain"].(map[string]interface{}), kwargs)
 		if err != nil {
-			return &agentSchema.BaseSingleActionAgent{}, err
+			return nil, err
 		}
 	} else if _, ok := config["llm_chain_path"]; ok {
 		config["llm_chain"], err = chains.LoadChain(config["llm_chain_path"].(string), kwargs)
 	} else {
-		return &agentSchema.BaseSingleActionAgent{}, errors.New("One of `llm_chain` and `llm_chain_path` should be specified.")
+		return nil, errors.New("One of `llm_chain` and `llm_chain_path` should be specified.")
 	}
 	combinedConfig := mapTools.MergeMaps(config, kwargs)
-	return agentClass.FromConfig(combinedConfig), nil
+	return agentClass.FromConfig(combinedConfig)
 }
 
-func LoadAgent(path string, kwargs map[string]interface{}) (*agentSchema.BaseSingleActionAgent, error) {
+func LoadAgent(path string, kwargs map[string]interface{}) (agentSchema.BaseAgent, error) {
 	if hubResult, err := LoadAgentFromHub(path, LoadAgentFromFile, "agents", []string{"json", "yaml"}, map[string]interface{}{}); hubResult != nil {
 		if err != nil {
-			return &agentSchema.BaseSingleActionAgent{}, err
+			return nil, err
 		}
 		return hubResult, nil
 	} else {
@@ -98,7 +107,7 @@ func LoadAgent(path string, kwargs map[string]interface{}) (*agentSchema.BaseSin
 	}
 }
 
-func LoadAgentFromFile(file string, kwargs map[string]interface{}) (*agentSchema.BaseSingleActionAgent, error) {
+func LoadAgentFromFile(file string, kwargs map[string]interface{}) (agentSchema.BaseAgent, error) {
 	var config map[string]interface{}
 	filePath := path.Ext(file)
 	if filePath == ".json" {
@@ -114,20 +123,20 @@ func LoadAgentFromFile(file string, kwargs map[string]interface{}) (*agentSchema
 			return nil, err
 		}
 	} else {
-		return &agentSchema.BaseSingleActionAgent{}, errors.New("File type must be json or yaml")
+		return nil, errors.New("File type must be json or yaml")
 	}
 	return LoadAgentFromConfig(config, llmSchema.BaseLLM{}, []toolSchema.BaseTool{}, kwargs)
 }
 
 func LoadAgentFromHub(
 	path string,
-	loader func(string, map[string]interface{}) (*agentSchema.BaseSingleActionAgent, error),
+	loader func(string, map[string]interface{}) (agentSchema.BaseAgent, error),
 	validPrefix string,
 	validSuffixes []string,
 	kwargs map[string]interface{},
-) (*agentSchema.BaseSingleActionAgent, error) {
+) (agentSchema.BaseAgent, error) {
 	if _, err := url.ParseRequestURI(path); err != nil || !hubPathRe.MatchString(path) {
-		return &agentSchema.BaseSingleActionAgent{}, nil
+		return nil, nil
 	}
 
 	matches := hubPathRe.FindStringSubmatch(path)
@@ -140,17 +149,17 @@ func LoadAgentFromHub(
 	remotePathStr := matches[2]
 	remotePath := filepath.Clean(remotePathStr)
 	if strings.Split(remotePath, "/")[0] != validPrefix {
-		return &agentSchema.BaseSingleActionAgent{}, nil
+		return nil, nil
 	}
 	if !contains(validSuffixes, filepath.Ext(remotePath)) {
-		return &agentSchema.BaseSingleActionAgent{}, fmt.Errorf("Unsupported file type.")
+		return nil, fmt.Errorf("Unsupported file type.")
 	}
 
 	fullURL := urlBase + "/" + ref + "/" + remotePath
 
 	resp, err := http.Get(fullURL)
 	if err != nil || resp.StatusCode != 200 {
-		return &agentSchema.BaseSingleActionAgent{}, fmt.Errorf("Could not find file at %s", fullURL)
+		return nil, fmt.Errorf("Could not find file at %s", fullURL)
 	}
 	defer func(Body io.ReadCloser) {
 		err := Body.Close()
@@ -0,0 +1,127 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/William-Bohm/langchain-go/langchain-go/agent/agentSchema"
+	"github.com/William-Bohm/langchain-go/langchain-go/callbacks/callbackSchema"
+	"github.com/William-Bohm/langchain-go/langchain-go/llm/openai/openaiClient"
+	"github.com/William-Bohm/langchain-go/langchain-go/rootSchema"
+	"github.com/William-Bohm/langchain-go/langchain-go/tools/toolSchema"
+)
+
+const defaultOpenAIFunctionsSystemMessage = "You are a helpful AI assistant."
+
+// FunctionCallingModel is a chat model that can be offered functions, e.g. chat_models.ChatOpenAI.
+type FunctionCallingModel interface {
+	CallWithFunctions(ctx context.Context, messages []rootSchema.BaseMessageInterface, functions []openaiClient.FunctionDefinition) (*rootSchema.AIMessage, error)
+}
+
+// OpenAIFunctionsAgent picks tools through OpenAI function calling. Every tool is sent as a
+// function and the model's function_call is read directly, so no ReAct text format or regex
+// parsing is involved.
+type OpenAIFunctionsAgent struct {
+	agentSchema.BaseSingleActionAgent
+	LLM           FunctionCallingModel
+	Tools         []toolSchema.BaseTool
+	SystemMessage string
+	OutputParser  *agentSchema.OpenAIFunctionsAgentOutputParser
+	functions     []openaiClient.FunctionDefinition
+}
+
+func NewOpenAIFunctionsAgent(llm FunctionCallingModel, tools []toolSchema.BaseTool, systemMessage string) *OpenAIFunctionsAgent {
+	if systemMessage == "" {
+		systemMessage = defaultOpenAIFunctionsSystemMessage
+	}
+	return &OpenAIFunctionsAgent{
+		LLM:           llm,
+		Tools:         tools,
+		SystemMessage: systemMessage,
+		OutputParser:  &agentSchema.OpenAIFunctionsAgentOutputParser{},
+		functions:     toolSchema.FormatToolsToOpenAIFunctions(tools),
+	}
+}
+
+// FromLLMAndTools builds the agent from a FunctionCallingModel such as chat_models.ChatOpenAI.
+// kwargs["system_message"] overrides the default system message.
+func (a *OpenAIFunctionsAgent) FromLLMAndTools(llm interface{}, tools []toolSchema.BaseTool, callbackManager callbackSchema.BaseCallbackManager, kwargs map[string]interface{}) (agentSchema.BaseAgent, error) {
+	model, ok := llm.(FunctionCallingModel)
+	if !ok {
+		return nil, fmt.Errorf("the openai-functions agent requires a FunctionCallingModel, got %T", llm)
+	}
+	systemMessage, _ := kwargs["system_message"].(string)
+	return NewOpenAIFunctionsAgent(model, tools, systemMessage), nil
+}
+
+// FromConfig rebuilds nothing, the agent holds a live model and tools rather than a serializable LLM chain.
+func (a *OpenAIFunctionsAgent) FromConfig(config map[string]interface{}) (agentSchema.BaseAgent, error) {
+	return nil, errors.New("the openai-functions agent can not be loaded from a config, use FromLLMAndTools")
+}
+
+func (a *OpenAIFunctionsAgent) Dict(kwargs map[string]interface{}) map[string]interface{} {
+	dict := map[string]interface{}{
+		"_type":          a.AgentType(),
+		"system_message": a.SystemMessage,
+	}
+	for k, v := range kwargs {
+		dict[k] = v
+	}
+	return dict
+}
+
+func (a *OpenAIFunctionsAgent) AgentType() string {
+	return string(openaiFunctions)
+}
+
+func (a *OpenAIFunctionsAgent) InputKeys() []string {
+	return []string{"input"}
+}
+
+func (a *OpenAIFunctionsAgent) GetAllowedTools() []string {
+	toolNames := make([]string, len(a.Tools))
+	for i, tool := range a.Tools {
+		toolNames[i] = tool.Name
+	}
+	return toolNames
+}
+
+func (a *OpenAIFunctionsAgent) Plan(intermediateSteps []agentSchema.IntermediateStep, kwargs map[string]interface{}) (interface{}, error) {
+	return a.PlanWithContext(context.Background(), intermediateSteps, kwargs)
+}
+
+// PlanWithContext sends the conversation so far and returns an AgentAction, []AgentAction or AgentFinish.
+func (a *OpenAIFunctionsAgent) PlanWithContext(ctx context.Context, intermediateSteps []agentSchema.IntermediateStep, kwargs map[string]interface{}) (interface{}, error) {
+	input, ok := kwargs["input"].(string)
+	if !ok {
+		return nil, errors.New("openai-functions agent expects a string `input`")
+	}
+
+	messages := []rootSchema.BaseMessageInterface{
+		rootSchema.NewSystemMessage(a.SystemMessage),
+		rootSchema.NewHumanMessage(input),
+	}
+	messages = append(messages, formatToOpenAIFunctionMessages(intermediateSteps)...)
+
+	message, err := a.LLM.CallWithFunctions(ctx, messages, a.functions)
+	if err != nil {
+		return nil, err
+	}
+	return a.OutputParser.ParseAIMessage(message)
+}
+
+// formatToOpenAIFunctionMessages replays each step as the assistant's function call followed by
+// a function message with the tool's observation.
+func formatToOpenAIFunctionMessages(intermediateSteps []agentSchema.IntermediateStep) []rootSchema.BaseMessageInterface {
+	var messages []rootSchema.BaseMessageInterface
+	for _, step := range intermediateSteps {
+		if len(step.MessageLog) > 0 {
+			messages = append(messages, step.MessageLog...)
+		} else {
+			messages = append(messages, rootSchema.NewAIMessage(step.Log))
+		}
+		messages = append(messages, rootSchema.NewFunctionMessage(step.Output, step.Tool))
+	}
+	return messages
+}
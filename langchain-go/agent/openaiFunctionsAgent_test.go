@@ -0,0 +1,85 @@
+package agent
+
+import (
+	"context"
+	"testing"
+
+	"github.com/William-Bohm/langchain-go/langchain-go/agent/agentSchema"
+	"github.com/William-Bohm/langchain-go/langchain-go/chat_models"
+	"github.com/William-Bohm/langchain-go/langchain-go/llm/openai/openaiClient"
+	"github.com/William-Bohm/langchain-go/langchain-go/rootSchema"
+	"github.com/William-Bohm/langchain-go/langchain-go/tools/toolSchema"
+)
+
+// ChatOpenAI is the model the openai-functions agent is initialised with outside tests.
+var _ FunctionCallingModel = (*chat_models.ChatOpenAI)(nil)
+
+// fakeFunctionCallingModel answers with its replies in turn and records what it was sent.
+type fakeFunctionCallingModel struct {
+	replies   []*rootSchema.AIMessage
+	messages  [][]rootSchema.BaseMessageInterface
+	functions [][]openaiClient.FunctionDefinition
+}
+
+func (m *fakeFunctionCallingModel) CallWithFunctions(ctx context.Context, messages []rootSchema.BaseMessageInterface, functions []openaiClient.FunctionDefinition) (*rootSchema.AIMessage, error) {
+	m.messages = append(m.messages, messages)
+	m.functions = append(m.functions, functions)
+	reply := m.replies[0]
+	m.replies = m.replies[1:]
+	return reply, nil
+}
+
+func TestOpenAIFunctionsAgentLoadAndPlan(t *testing.T) {
+	call := rootSchema.NewAIMessage("")
+	call.AdditionalKwargs["function_call"] = &openaiClient.FunctionCall{Name: "search", Arguments: `{"query": "go", "limit": 2}`}
+	model := &fakeFunctionCallingModel{replies: []*rootSchema.AIMessage{call, rootSchema.NewAIMessage("Go is a language.")}}
+	tools := []toolSchema.BaseTool{{
+		Name:        "search",
+		Description: "Searches the web.",
+		ArgsSchema:  map[string]interface{}{"query": map[string]interface{}{"type": "string"}},
+	}}
+
+	if _, err := InitializeAgent(tools, model, openaiFunctions, nil, "", nil, nil); err != nil {
+		t.Fatalf("InitializeAgent: %v", err)
+	}
+	loaded, err := AGENT_TO_CLASS[openaiFunctions].FromLLMAndTools(model, tools, nil, map[string]interface{}{"system_message": "Be brief."})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := AGENT_TO_CLASS[openaiFunctions].FromLLMAndTools("not a model", tools, nil, nil); err == nil {
+		t.Error("loading from a model without function calling got no error")
+	}
+
+	inputs := map[string]interface{}{"input": "What is go?"}
+	step, err := loaded.Plan(nil, inputs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	action, ok := step.(agentSchema.AgentAction)
+	if !ok {
+		t.Fatalf("got %#v, want an AgentAction", step)
+	}
+	if args, ok := action.ToolInput.(map[string]interface{}); action.Tool != "search" || !ok || args["query"] != "go" {
+		t.Errorf("got action %+v", action)
+	}
+	if sent := model.messages[0]; len(sent) != 2 || sent[0].GetContent() != "Be brief." || sent[1].GetContent() != "What is go?" {
+		t.Errorf("first plan sent %v", sent)
+	}
+	if functions := model.functions[0]; len(functions) != 1 || functions[0].Name != "search" {
+		t.Errorf("first plan offered %+v", functions)
+	}
+
+	step, err = loaded.Plan([]agentSchema.IntermediateStep{{AgentAction: action, Output: "Go is a language."}}, inputs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	finish, ok := step.(agentSchema.AgentFinish)
+	if !ok || finish.ReturnValues["output"] != "Go is a language." {
+		t.Fatalf("got %#v, want the final answer", step)
+	}
+	// the step is replayed as the model's function call answered by a function message
+	sent := model.messages[1]
+	if len(sent) != 4 || sent[2] != call || sent[3].Type() != rootSchema.Function || sent[3].GetContent() != "Go is a language." {
+		t.Errorf("second plan sent %v", sent)
+	}
+}
@@ -0,0 +1,110 @@
+package cache
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/William-Bohm/langchain-go/langchain-go/llm/llmSchema"
+)
+
+// cacheBackends returns an opener per backend. Every call opens a new handle on the same storage,
+// so the persistent backends are checked across runs.
+func cacheBackends(t *testing.T) map[string]func() llmSchema.BaseCache {
+	dir := t.TempDir()
+	return map[string]func() llmSchema.BaseCache{
+		"inMemory": func() func() llmSchema.BaseCache {
+			c := NewInMemoryCache(0)
+			return func() llmSchema.BaseCache { return c }
+		}(),
+		"jsonFile": func() llmSchema.BaseCache {
+			c, err := NewJSONFileCache(filepath.Join(dir, "cache", "llm.json"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			return c
+		},
+		"sqlite": func() llmSchema.BaseCache {
+			c, err := NewSQLiteCache(filepath.Join(dir, "llm.db"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(func() { c.Close() })
+			return c
+		},
+	}
+}
+
+func TestCacheBackends(t *testing.T) {
+	generations := []llmSchema.Generation{
+		{Text: "Hello!", GenerationInfo: map[string]interface{}{"finish_reason": "stop"}},
+		{Text: "Hi!"},
+	}
+	for name, open := range cacheBackends(t) {
+		t.Run(name, func(t *testing.T) {
+			c := open()
+			if got, ok, err := c.Lookup("Say hi", "gpt-3.5"); err != nil || ok || got != nil {
+				t.Fatalf("empty cache got %v, %v, %v", got, ok, err)
+			}
+			if err := c.Update("Say hi", "gpt-3.5", generations); err != nil {
+				t.Fatal(err)
+			}
+			if err := c.Update("Say hi", "gpt-4", generations[1:]); err != nil {
+				t.Fatal(err)
+			}
+
+			c = open()
+			got, ok, err := c.Lookup("Say hi", "gpt-3.5")
+			if err != nil || !ok || !reflect.DeepEqual(got, generations) {
+				t.Errorf("got %+v, %v, %v, want %+v", got, ok, err, generations)
+			}
+			// the llm string is part of the key
+			if got, ok, _ := c.Lookup("Say hi", "gpt-4"); !ok || !reflect.DeepEqual(got, generations[1:]) {
+				t.Errorf("gpt-4 got %+v, %v", got, ok)
+			}
+			if _, ok, _ := c.Lookup("Say hello", "gpt-3.5"); ok {
+				t.Error("another prompt hit the cache")
+			}
+
+			// updating replaces the generations instead of adding to them
+			if err := c.Update("Say hi", "gpt-3.5", generations[:1]); err != nil {
+				t.Fatal(err)
+			}
+			if got, _, _ := c.Lookup("Say hi", "gpt-3.5"); !reflect.DeepEqual(got, generations[:1]) {
+				t.Errorf("after update got %+v", got)
+			}
+			got[0].Text = "changed"
+			if got, _, _ := c.Lookup("Say hi", "gpt-3.5"); got[0].Text != "Hello!" {
+				t.Error("changing a looked up generation changed the cache")
+			}
+
+			if err := c.Clear(); err != nil {
+				t.Fatal(err)
+			}
+			if _, ok, _ := open().Lookup("Say hi", "gpt-4"); ok {
+				t.Error("cleared cache still hit")
+			}
+		})
+	}
+}
+
+func TestInMemoryCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	c := NewInMemoryCache(2)
+	generations := []llmSchema.Generation{{Text: "out"}}
+	c.Update("a", "llm", generations)
+	c.Update("b", "llm", generations)
+	c.Lookup("a", "llm")
+	c.Update("c", "llm", generations)
+
+	if c.Len() != 2 {
+		t.Errorf("got %d entries, want 2", c.Len())
+	}
+	if _, ok, _ := c.Lookup("b", "llm"); ok {
+		t.Error("b was used least recently but not evicted")
+	}
+	for _, prompt := range []string{"a", "c"} {
+		if _, ok, _ := c.Lookup(prompt, "llm"); !ok {
+			t.Errorf("%s was evicted", prompt)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+
+	"github.com/William-Bohm/langchain-go/langchain-go/llm/llmSchema"
+)
+
+const DefaultInMemoryCacheSize = 1000
+
+type cacheKey struct {
+	prompt    string
+	llmString string
+}
+
+type cacheEntry struct {
+	key         cacheKey
+	generations []llmSchema.Generation
+}
+
+// InMemoryCache is an LRU cache that keeps at most MaxSize entries and evicts the least recently used one.
+type InMemoryCache struct {
+	MaxSize int
+	mu      sync.Mutex
+	order   *list.List
+	entries map[cacheKey]*list.Element
+}
+
+// NewInMemoryCache creates an LRU cache. A maxSize of 0 or less uses DefaultInMemoryCacheSize.
+func NewInMemoryCache(maxSize int) *InMemoryCache {
+	if maxSize <= 0 {
+		maxSize = DefaultInMemoryCacheSize
+	}
+	return &InMemoryCache{
+		MaxSize: maxSize,
+		order:   list.New(),
+		entries: make(map[cacheKey]*list.Element),
+	}
+}
+
+func (c *InMemoryCache) Lookup(prompt string, llmString string) ([]llmSchema.Generation, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	element, ok := c.entries[cacheKey{prompt, llmString}]
+	if !ok {
+		return nil, false, nil
+	}
+	c.order.MoveToFront(element)
+	return copyGenerations(element.Value.(*cacheEntry).generations), true, nil
+}
+
+func (c *InMemoryCache) Update(prompt string, llmString string, returnVal []llmSchema.Generation) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := cacheKey{prompt, llmString}
+	if element, ok := c.entries[key]; ok {
+		element.Value.(*cacheEntry).generations = copyGenerations(returnVal)
+		c.order.MoveToFront(element)
+		return nil
+	}
+	c.entries[key] = c.order.PushFront(&cacheEntry{key: key, generations: copyGenerations(returnVal)})
+	for c.order.Len() > c.MaxSize {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+	return nil
+}
+
+func (c *InMemoryCache) Clear() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.order.Init()
+	c.entries = make(map[cacheKey]*list.Element)
+	return nil
+}
+
+// Len returns the number of cached entries.
+func (c *InMemoryCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// copyGenerations keeps callers from mutating cached entries through the returned slice.
+func copyGenerations(generations []llmSchema.Generation) []llmSchema.Generation {
+	if generations == nil {
+		return nil
+	}
+	copied := make([]llmSchema.Generation, len(generations))
+	copy(copied, generations)
+	return copied
+}
@@ -0,0 +1,94 @@
+package cache
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/William-Bohm/langchain-go/langchain-go/llm/llmSchema"
+)
+
+// JSONFileCache keeps the cache in memory and writes it to a JSON file after every update,
+// so cached generations survive between runs.
+type JSONFileCache struct {
+	Path    string
+	mu      sync.Mutex
+	entries map[string]map[string][]llmSchema.Generation // llmString -> prompt -> generations
+}
+
+// NewJSONFileCache loads the cache at path, starting empty if the file does not exist yet.
+func NewJSONFileCache(path string) (*JSONFileCache, error) {
+	c := &JSONFileCache{
+		Path:    filepath.Clean(path),
+		entries: make(map[string]map[string][]llmSchema.Generation),
+	}
+	data, err := os.ReadFile(c.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return c, nil
+	}
+	if err := json.Unmarshal(data, &c.entries); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *JSONFileCache) Lookup(prompt string, llmString string) ([]llmSchema.Generation, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	generations, ok := c.entries[llmString][prompt]
+	if !ok {
+		return nil, false, nil
+	}
+	return copyGenerations(generations), true, nil
+}
+
+func (c *JSONFileCache) Update(prompt string, llmString string, returnVal []llmSchema.Generation) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.entries[llmString]; !ok {
+		c.entries[llmString] = make(map[string][]llmSchema.Generation)
+	}
+	c.entries[llmString][prompt] = copyGenerations(returnVal)
+	return c.save()
+}
+
+func (c *JSONFileCache) Clear() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = make(map[string]map[string][]llmSchema.Generation)
+	return c.save()
+}
+
+// save writes to a temporary file first so a crash never leaves a half-written cache behind.
+func (c *JSONFileCache) save() error {
+	dirPath := filepath.Dir(c.Path)
+	if err := os.MkdirAll(dirPath, 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(c.entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dirPath, filepath.Base(c.Path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), c.Path)
+}
@@ -0,0 +1,190 @@
+package cache
+
+import (
+	"errors"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/William-Bohm/langchain-go/langchain-go/embedding/embeddingSchema"
+	"github.com/William-Bohm/langchain-go/langchain-go/llm/llmSchema"
+	"github.com/William-Bohm/langchain-go/langchain-go/vectorstore"
+)
+
+const DefaultSemanticScoreThreshold = 0.95
+
+type semanticEntry struct {
+	prompt      string
+	llmString   string
+	embedding   []float64
+	generations []llmSchema.Generation
+	createdAt   time.Time
+}
+
+// SemanticCache answers a prompt with the generations of the most similar cached prompt, as long as
+// their embeddings have a cosine similarity of at least ScoreThreshold and were produced with the
+// same llmString. Entries older than TTL are dropped, and past MaxSize the oldest ones are evicted.
+type SemanticCache struct {
+	Embeddings     embeddingSchema.BaseEmbeddings
+	ScoreThreshold float64       `comment:"Minimum cosine similarity for a cached prompt to count as a hit."`
+	MaxSize        int           `comment:"Maximum number of cached prompts. 0 means no limit."`
+	TTL            time.Duration `comment:"How long an entry stays valid. 0 means forever."`
+	mu             sync.Mutex
+	entries        []*semanticEntry // oldest first
+	// embeddings of prompts Lookup missed, so the Update that usually follows a miss does not
+	// embed the same prompt again
+	missed map[string][]float64
+	now    func() time.Time
+}
+
+// maxMissedEmbeddings bounds missed for callers that look up without ever updating.
+const maxMissedEmbeddings = 1000
+
+func NewSemanticCache(embeddings embeddingSchema.BaseEmbeddings, options ...SemanticCacheOption) (*SemanticCache, error) {
+	if embeddings == nil {
+		return nil, errors.New("semantic cache needs an embeddings model")
+	}
+	c := &SemanticCache{
+		Embeddings:     embeddings,
+		ScoreThreshold: DefaultSemanticScoreThreshold,
+		MaxSize:        DefaultInMemoryCacheSize,
+		TTL:            0,
+		missed:         map[string][]float64{},
+		now:            time.Now,
+	}
+	for _, opt := range options {
+		if err := opt(c); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+func (c *SemanticCache) Lookup(prompt string, llmString string) ([]llmSchema.Generation, bool, error) {
+	embedding, err := c.Embeddings.EmbedQuery(prompt)
+	if err != nil {
+		return nil, false, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.evictExpired()
+
+	var best *semanticEntry
+	bestScore := math.Inf(-1)
+	for _, entry := range c.entries {
+		if entry.llmString != llmString {
+			continue
+		}
+		score := vectorstore.CosineSimilarity(embedding, entry.embedding)
+		if score >= c.ScoreThreshold && score > bestScore {
+			best, bestScore = entry, score
+		}
+	}
+	if best == nil {
+		if len(c.missed) >= maxMissedEmbeddings {
+			c.missed = map[string][]float64{}
+		}
+		c.missed[prompt] = embedding
+		return nil, false, nil
+	}
+	return copyGenerations(best.generations), true, nil
+}
+
+// Update caches returnVal for prompt, reusing the embedding of a preceding Lookup miss if there was one.
+func (c *SemanticCache) Update(prompt string, llmString string, returnVal []llmSchema.Generation) error {
+	c.mu.Lock()
+	embedding, ok := c.missed[prompt]
+	delete(c.missed, prompt)
+	c.mu.Unlock()
+	if !ok {
+		var err error
+		embedding, err = c.Embeddings.EmbedQuery(prompt)
+		if err != nil {
+			return err
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, entry := range c.entries {
+		if entry.prompt == prompt && entry.llmString == llmString {
+			c.entries = append(c.entries[:i], c.entries[i+1:]...)
+			break
+		}
+	}
+	c.entries = append(c.entries, &semanticEntry{
+		prompt:      prompt,
+		llmString:   llmString,
+		embedding:   embedding,
+		generations: copyGenerations(returnVal),
+		createdAt:   c.now(),
+	})
+	c.evictExpired()
+	if c.MaxSize > 0 && len(c.entries) > c.MaxSize {
+		c.entries = c.entries[len(c.entries)-c.MaxSize:]
+	}
+	return nil
+}
+
+func (c *SemanticCache) Clear() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = nil
+	c.missed = map[string][]float64{}
+	return nil
+}
+
+// Len returns the number of cached prompts, including ones that have expired but were not evicted yet.
+func (c *SemanticCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
+
+// evictExpired drops entries older than TTL. Entries are kept oldest first, so it stops at the first live one.
+func (c *SemanticCache) evictExpired() {
+	if c.TTL <= 0 {
+		return
+	}
+	cutoff := c.now().Add(-c.TTL)
+	i := 0
+	for i < len(c.entries) && c.entries[i].createdAt.Before(cutoff) {
+		i++
+	}
+	c.entries = c.entries[i:]
+}
+
+type SemanticCacheOption func(*SemanticCache) error
+
+func ScoreThreshold(threshold float64) SemanticCacheOption {
+	return func(c *SemanticCache) error {
+		if threshold < -1 || threshold > 1 {
+			return errors.New("score threshold must be a cosine similarity between -1 and 1")
+		}
+		c.ScoreThreshold = threshold
+		return nil
+	}
+}
+
+// MaxSize limits the number of cached prompts; 0 disables the limit.
+func MaxSize(size int) SemanticCacheOption {
+	return func(c *SemanticCache) error {
+		if size < 0 {
+			return errors.New("max size can not be negative")
+		}
+		c.MaxSize = size
+		return nil
+	}
+}
+
+// TTL sets how long an entry stays valid; 0 keeps entries until they are evicted by MaxSize.
+func TTL(ttl time.Duration) SemanticCacheOption {
+	return func(c *SemanticCache) error {
+		if ttl < 0 {
+			return errors.New("ttl can not be negative")
+		}
+		c.TTL = ttl
+		return nil
+	}
+}
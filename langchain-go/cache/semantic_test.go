@@ -0,0 +1,112 @@
+package cache
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/William-Bohm/langchain-go/langchain-go/embedding"
+	"github.com/William-Bohm/langchain-go/langchain-go/llm/llmSchema"
+	"github.com/William-Bohm/langchain-go/langchain-go/vectorstore"
+)
+
+// countingEmbeddings counts the queries it embeds.
+type countingEmbeddings struct {
+	*embedding.HashEmbeddings
+	queries int
+}
+
+func (e *countingEmbeddings) EmbedQuery(text string) ([]float64, error) {
+	e.queries++
+	return e.HashEmbeddings.EmbedQuery(text)
+}
+
+func newTestSemanticCache(t *testing.T, threshold float64, options ...SemanticCacheOption) (*SemanticCache, *countingEmbeddings) {
+	hash, err := embedding.NewHashEmbeddings()
+	if err != nil {
+		t.Fatal(err)
+	}
+	embeddings := &countingEmbeddings{HashEmbeddings: hash}
+	c, err := NewSemanticCache(embeddings, append([]SemanticCacheOption{ScoreThreshold(threshold)}, options...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c, embeddings
+}
+
+func TestSemanticCacheScoreThreshold(t *testing.T) {
+	const cached, similar = "What is the capital of France?", "What is the capital city of France?"
+	hash, _ := embedding.NewHashEmbeddings()
+	cachedEmbedding, _ := hash.EmbedQuery(cached)
+	similarEmbedding, _ := hash.EmbedQuery(similar)
+	score := vectorstore.CosineSimilarity(cachedEmbedding, similarEmbedding)
+	if score <= 0 || score >= 1 {
+		t.Fatalf("prompts have similarity %v, want them close but not equal", score)
+	}
+
+	generations := []llmSchema.Generation{{Text: "Paris"}}
+	tests := []struct {
+		name      string
+		threshold float64
+		llmString string
+		wantHit   bool
+	}{
+		{"at the threshold", score, "gpt-3.5", true},
+		{"below the threshold", score - 0.1, "gpt-3.5", true},
+		{"just above the threshold", math.Nextafter(score, 2), "gpt-3.5", false},
+		{"another llm", score, "gpt-4", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := newTestSemanticCache(t, tt.threshold)
+			if err := c.Update(cached, "gpt-3.5", generations); err != nil {
+				t.Fatal(err)
+			}
+			got, hit, err := c.Lookup(similar, tt.llmString)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if hit != tt.wantHit {
+				t.Fatalf("got hit %v, want %v", hit, tt.wantHit)
+			}
+			if hit && (len(got) != 1 || got[0].Text != "Paris") {
+				t.Errorf("got %+v", got)
+			}
+		})
+	}
+}
+
+func TestSemanticCacheReusesMissEmbedding(t *testing.T) {
+	c, embeddings := newTestSemanticCache(t, 0.99)
+	if _, hit, _ := c.Lookup("Tell me a joke", "llm"); hit {
+		t.Fatal("empty cache hit")
+	}
+	if err := c.Update("Tell me a joke", "llm", []llmSchema.Generation{{Text: "no"}}); err != nil {
+		t.Fatal(err)
+	}
+	if embeddings.queries != 1 {
+		t.Errorf("embedded %d queries, want the miss's embedding reused by Update", embeddings.queries)
+	}
+	if _, hit, _ := c.Lookup("Tell me a joke", "llm"); !hit {
+		t.Error("the same prompt missed")
+	}
+}
+
+func TestSemanticCacheTTL(t *testing.T) {
+	now := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+	c, _ := newTestSemanticCache(t, 0.99, TTL(time.Hour))
+	c.now = func() time.Time { return now }
+
+	c.Update("Tell me a joke", "llm", []llmSchema.Generation{{Text: "no"}})
+	now = now.Add(time.Hour)
+	if _, hit, _ := c.Lookup("Tell me a joke", "llm"); !hit {
+		t.Error("entry expired at its TTL")
+	}
+	now = now.Add(time.Second)
+	if _, hit, _ := c.Lookup("Tell me a joke", "llm"); hit {
+		t.Error("entry outlived its TTL")
+	}
+	if c.Len() != 0 {
+		t.Errorf("%d expired entries kept", c.Len())
+	}
+}
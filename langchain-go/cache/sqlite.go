@@ -0,0 +1,101 @@
+package cache
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	"github.com/William-Bohm/langchain-go/langchain-go/llm/llmSchema"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+const sqliteCacheSchema = `CREATE TABLE IF NOT EXISTS full_llm_cache (
+	prompt TEXT NOT NULL,
+	llm TEXT NOT NULL,
+	idx INTEGER NOT NULL,
+	response TEXT NOT NULL,
+	PRIMARY KEY (prompt, llm, idx)
+)`
+
+// SQLiteCache stores generations in an embedded SQLite database, one row per generation.
+type SQLiteCache struct {
+	DB *sql.DB
+}
+
+// NewSQLiteCache opens (or creates) the database at databasePath, e.g. ".langchain.db".
+func NewSQLiteCache(databasePath string) (*SQLiteCache, error) {
+	db, err := sql.Open("sqlite3", databasePath)
+	if err != nil {
+		return nil, err
+	}
+	c, err := NewSQLiteCacheFromDB(db)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// NewSQLiteCacheFromDB uses an already opened database and creates the cache table if needed.
+func NewSQLiteCacheFromDB(db *sql.DB) (*SQLiteCache, error) {
+	if _, err := db.Exec(sqliteCacheSchema); err != nil {
+		return nil, err
+	}
+	return &SQLiteCache{DB: db}, nil
+}
+
+func (c *SQLiteCache) Lookup(prompt string, llmString string) ([]llmSchema.Generation, bool, error) {
+	rows, err := c.DB.Query("SELECT response FROM full_llm_cache WHERE prompt = ? AND llm = ? ORDER BY idx", prompt, llmString)
+	if err != nil {
+		return nil, false, err
+	}
+	defer rows.Close()
+
+	var generations []llmSchema.Generation
+	for rows.Next() {
+		var response string
+		if err := rows.Scan(&response); err != nil {
+			return nil, false, err
+		}
+		var generation llmSchema.Generation
+		if err := json.Unmarshal([]byte(response), &generation); err != nil {
+			return nil, false, err
+		}
+		generations = append(generations, generation)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, false, err
+	}
+	return generations, len(generations) > 0, nil
+}
+
+func (c *SQLiteCache) Update(prompt string, llmString string, returnVal []llmSchema.Generation) error {
+	tx, err := c.DB.Begin()
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DELETE FROM full_llm_cache WHERE prompt = ? AND llm = ?", prompt, llmString); err != nil {
+		tx.Rollback()
+		return err
+	}
+	for i, generation := range returnVal {
+		response, err := json.Marshal(generation)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		if _, err := tx.Exec("INSERT INTO full_llm_cache (prompt, llm, idx, response) VALUES (?, ?, ?, ?)", prompt, llmString, i, string(response)); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (c *SQLiteCache) Clear() error {
+	_, err := c.DB.Exec("DELETE FROM full_llm_cache")
+	return err
+}
+
+func (c *SQLiteCache) Close() error {
+	return c.DB.Close()
+}
@@ -1,8 +1,10 @@
 package callbackSchema
 
 import (
-	"github.com/William-Bohm/langchain-go/langchain-go/agent/agentSchema"
+	"sync"
+
 	"github.com/William-Bohm/langchain-go/langchain-go/llm/llmSchema"
+	"github.com/William-Bohm/langchain-go/langchain-go/rootSchema"
 )
 
 type BaseCallbackHandler interface {
@@ -21,8 +23,8 @@ type BaseCallbackHandler interface {
 	OnToolEnd(output string, verbose bool, args ...interface{})
 	OnToolError(err error, verbose bool, args ...interface{})
 	OnText(text string, verbose bool, args ...interface{})
-	OnAgentAction(action agentSchema.AgentAction, verbose bool, args ...interface{})
-	OnAgentFinish(finish agentSchema.AgentFinish, verbose bool, args ...interface{})
+	OnAgentAction(action rootSchema.AgentAction, verbose bool, args ...interface{})
+	OnAgentFinish(finish rootSchema.AgentFinish, verbose bool, args ...interface{})
 }
 
 type BaseCallbackManager interface {
@@ -33,7 +35,10 @@ type BaseCallbackManager interface {
 	SetHandlers(handlers []BaseCallbackHandler)
 }
 
+// CallbackManager is safe for concurrent use. Events are passed to the handlers one at a time, so
+// handlers need no locking of their own, but must not call back into the manager.
 type CallbackManager struct {
+	mu       sync.Mutex
 	handlers []BaseCallbackHandler
 }
 
@@ -67,6 +72,8 @@ func NewCallbackManager(handlers []BaseCallbackHandler) *CallbackManager {
 }
 
 func (c *CallbackManager) OnLLMStart(serialized map[string]interface{}, prompts []string, verbose bool, args ...interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	for _, handler := range c.handlers {
 		if !handler.IgnoreLLM() {
 			if verbose || handler.AlwaysVerbose() {
@@ -77,6 +84,8 @@ func (c *CallbackManager) OnLLMStart(serialized map[string]interface{}, prompts
 }
 
 func (c *CallbackManager) OnLLMNewToken(token string, verbose bool, args ...interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	for _, handler := range c.handlers {
 		if !handler.IgnoreLLM() {
 			if verbose || handler.AlwaysVerbose() {
@@ -87,6 +96,8 @@ func (c *CallbackManager) OnLLMNewToken(token string, verbose bool, args ...inte
 }
 
 func (c *CallbackManager) OnLLMEnd(response llmSchema.LLMResult, verbose bool, args ...interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	for _, handler := range c.handlers {
 		if !handler.IgnoreLLM() {
 			if verbose || handler.AlwaysVerbose() {
@@ -97,6 +108,8 @@ func (c *CallbackManager) OnLLMEnd(response llmSchema.LLMResult, verbose bool, a
 }
 
 func (c *CallbackManager) OnLLMError(err error, verbose bool, args ...interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	for _, handler := range c.handlers {
 		if !handler.IgnoreLLM() {
 			if verbose || handler.AlwaysVerbose() {
@@ -106,7 +119,35 @@ func (c *CallbackManager) OnLLMError(err error, verbose bool, args ...interface{
 	}
 }
 
+// OnLLMCacheHit is forwarded to the handlers that implement llmSchema.CacheEventHandler.
+func (c *CallbackManager) OnLLMCacheHit(prompt string, llmString string, verbose bool, args ...interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, handler := range c.handlers {
+		if cacheHandler, ok := handler.(llmSchema.CacheEventHandler); ok && !handler.IgnoreLLM() {
+			if verbose || handler.AlwaysVerbose() {
+				cacheHandler.OnLLMCacheHit(prompt, llmString, verbose, args)
+			}
+		}
+	}
+}
+
+// OnLLMCacheMiss is forwarded to the handlers that implement llmSchema.CacheEventHandler.
+func (c *CallbackManager) OnLLMCacheMiss(prompt string, llmString string, verbose bool, args ...interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, handler := range c.handlers {
+		if cacheHandler, ok := handler.(llmSchema.CacheEventHandler); ok && !handler.IgnoreLLM() {
+			if verbose || handler.AlwaysVerbose() {
+				cacheHandler.OnLLMCacheMiss(prompt, llmString, verbose, args)
+			}
+		}
+	}
+}
+
 func (c *CallbackManager) OnChainStart(serialized map[string]interface{}, inputs map[string]interface{}, verbose bool, args ...interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	for _, handler := range c.handlers {
 		if !handler.IgnoreChain() {
 			if verbose || handler.AlwaysVerbose() {
@@ -117,6 +158,8 @@ func (c *CallbackManager) OnChainStart(serialized map[string]interface{}, inputs
 }
 
 func (c *CallbackManager) OnChainEnd(outputs map[string]interface{}, verbose bool, args ...interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	for _, handler := range c.handlers {
 		if !handler.IgnoreChain() {
 			if verbose || handler.AlwaysVerbose() {
@@ -127,6 +170,8 @@ func (c *CallbackManager) OnChainEnd(outputs map[string]interface{}, verbose boo
 }
 
 func (c *CallbackManager) OnChainError(err error, verbose bool, args ...interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	for _, handler := range c.handlers {
 		if !handler.IgnoreChain() {
 			if verbose || handler.AlwaysVerbose() {
@@ -137,6 +182,8 @@ func (c *CallbackManager) OnChainError(err error, verbose bool, args ...interfac
 }
 
 func (c *CallbackManager) OnToolStart(serialized map[string]interface{}, input_str string, verbose bool, args ...interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	for _, handler := range c.handlers {
 		if !handler.IgnoreAgent() {
 			if verbose || handler.AlwaysVerbose() {
@@ -146,7 +193,9 @@ func (c *CallbackManager) OnToolStart(serialized map[string]interface{}, input_s
 	}
 }
 
-func (c *CallbackManager) OnAgentAction(action agentSchema.AgentAction, verbose bool, args ...interface{}) {
+func (c *CallbackManager) OnAgentAction(action rootSchema.AgentAction, verbose bool, args ...interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	for _, handler := range c.handlers {
 		if !handler.IgnoreAgent() {
 			if verbose || handler.AlwaysVerbose() {
@@ -157,6 +206,8 @@ func (c *CallbackManager) OnAgentAction(action agentSchema.AgentAction, verbose
 }
 
 func (c *CallbackManager) OnToolEnd(output string, verbose bool, args ...interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	for _, handler := range c.handlers {
 		if !handler.IgnoreAgent() {
 			if verbose || handler.AlwaysVerbose() {
@@ -167,6 +218,8 @@ func (c *CallbackManager) OnToolEnd(output string, verbose bool, args ...interfa
 }
 
 func (c *CallbackManager) OnToolError(err error, verbose bool, args ...interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	for _, handler := range c.handlers {
 		if !handler.IgnoreAgent() {
 			if verbose || handler.AlwaysVerbose() {
@@ -177,6 +230,8 @@ func (c *CallbackManager) OnToolError(err error, verbose bool, args ...interface
 }
 
 func (c *CallbackManager) OnText(text string, verbose bool, args ...interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	for _, handler := range c.handlers {
 		if verbose || handler.AlwaysVerbose() {
 			handler.OnText(text, verbose, args)
@@ -184,7 +239,9 @@ func (c *CallbackManager) OnText(text string, verbose bool, args ...interface{})
 	}
 }
 
-func (c *CallbackManager) OnAgentFinish(finish agentSchema.AgentFinish, verbose bool, args ...interface{}) {
+func (c *CallbackManager) OnAgentFinish(finish rootSchema.AgentFinish, verbose bool, args ...interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	for _, handler := range c.handlers {
 		if !handler.IgnoreAgent() {
 			if verbose || handler.AlwaysVerbose() {
@@ -195,10 +252,14 @@ func (c *CallbackManager) OnAgentFinish(finish agentSchema.AgentFinish, verbose
 }
 
 func (c *CallbackManager) AddHandler(handler BaseCallbackHandler) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	c.handlers = append(c.handlers, handler)
 }
 
 func (c *CallbackManager) RemoveHandler(handler BaseCallbackHandler) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	for i, h := range c.handlers {
 		if h == handler {
 			c.handlers = append(c.handlers[:i], c.handlers[i+1:]...)
@@ -208,6 +269,8 @@ func (c *CallbackManager) RemoveHandler(handler BaseCallbackHandler) {
 }
 
 func (c *CallbackManager) SetHandlers(handlers []BaseCallbackHandler) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	c.handlers = handlers
 }
 
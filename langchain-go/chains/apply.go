@@ -0,0 +1,101 @@
+package chains
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// DefaultMaxWorkers is the worker limit used by ApplyConcurrent when maxWorkers is not positive.
+const DefaultMaxWorkers = 4
+
+// ApplyResult is the outcome of one input in a batch run. Exactly one of Output and Err is set.
+type ApplyResult struct {
+	Output map[string]string
+	Err    error
+}
+
+// ApplyConcurrent runs every input through the chain using at most maxWorkers goroutines.
+// Results are returned in the same order as inputList and a failing input does not stop the
+// rest of the batch, its error is recorded on its ApplyResult instead. Inputs that have not
+// started when ctx is done are marked with ctx.Err().
+// Each input goes through CallWithContext, so the callback manager still sees an
+// OnChainStart/OnChainEnd (or OnChainError) pair per item. It is called from several goroutines
+// at once, so it must be safe for concurrent use, as callbackSchema.CallbackManager is.
+func (bc *BaseChain) ApplyConcurrent(ctx context.Context, inputList []map[string]interface{}, maxWorkers int) []ApplyResult {
+	return bc.applyConcurrentWith(ctx, bc.call, inputList, maxWorkers)
+}
+
+func (bc *BaseChain) applyConcurrentWith(ctx context.Context, call CallFunc, inputList []map[string]interface{}, maxWorkers int) []ApplyResult {
+	if maxWorkers <= 0 {
+		maxWorkers = DefaultMaxWorkers
+	}
+
+	results := make([]ApplyResult, len(inputList))
+	sem := make(chan struct{}, maxWorkers)
+	var wg sync.WaitGroup
+
+	for i, inputs := range inputList {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			for j := i; j < len(inputList); j++ {
+				results[j] = ApplyResult{Err: ctx.Err()}
+			}
+			wg.Wait()
+			return results
+		}
+
+		wg.Add(1)
+		go func(i int, inputs map[string]interface{}) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			output, err := bc.executeWith(ctx, call, inputs, false)
+			if err != nil {
+				results[i] = ApplyResult{Err: err}
+				return
+			}
+			outputStr, err := toStringMap(output)
+			if err != nil {
+				results[i] = ApplyResult{Err: err}
+				return
+			}
+			results[i] = ApplyResult{Output: outputStr}
+		}(i, inputs)
+	}
+
+	wg.Wait()
+	return results
+}
+
+// ApplyResultErrors collects the non-nil errors of a batch run, keyed by input index.
+func ApplyResultErrors(results []ApplyResult) map[int]error {
+	errs := map[int]error{}
+	for i, result := range results {
+		if result.Err != nil {
+			errs[i] = result.Err
+		}
+	}
+	return errs
+}
+
+func toStringMap(output interface{}) (map[string]string, error) {
+	switch o := output.(type) {
+	case map[string]string:
+		return o, nil
+	case map[string]interface{}:
+		outputStr := make(map[string]string, len(o))
+		for k, v := range o {
+			if s, ok := v.(string); ok {
+				outputStr[k] = s
+			} else {
+				outputStr[k] = fmt.Sprint(v)
+			}
+		}
+		return outputStr, nil
+	default:
+		return nil, errors.New("output must be a map[string]string")
+	}
+}
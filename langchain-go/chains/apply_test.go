@@ -0,0 +1,123 @@
+package chains
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/William-Bohm/langchain-go/langchain-go/callbacks/callbackSchema"
+	"github.com/William-Bohm/langchain-go/langchain-go/llm/llmSchema"
+	"github.com/William-Bohm/langchain-go/langchain-go/rootSchema"
+)
+
+// countingHandler counts chain events without locking, so the race detector flags a callback
+// manager that calls it from several goroutines at once.
+type countingHandler struct {
+	starts, ends, errors int
+}
+
+func (h *countingHandler) AlwaysVerbose() bool                                               { return true }
+func (h *countingHandler) IgnoreLLM() bool                                                   { return false }
+func (h *countingHandler) IgnoreChain() bool                                                 { return false }
+func (h *countingHandler) IgnoreAgent() bool                                                 { return false }
+func (h *countingHandler) OnLLMStart(map[string]interface{}, []string, bool, ...interface{}) {}
+func (h *countingHandler) OnLLMNewToken(string, bool, ...interface{})                        {}
+func (h *countingHandler) OnLLMEnd(llmSchema.LLMResult, bool, ...interface{})                {}
+func (h *countingHandler) OnLLMError(error, bool, ...interface{})                            {}
+func (h *countingHandler) OnChainEnd(map[string]interface{}, bool, ...interface{})           { h.ends++ }
+func (h *countingHandler) OnChainError(error, bool, ...interface{})                          { h.errors++ }
+func (h *countingHandler) OnChainStart(map[string]interface{}, map[string]interface{}, bool, ...interface{}) {
+	h.starts++
+}
+func (h *countingHandler) OnToolStart(map[string]interface{}, string, bool, ...interface{}) {}
+func (h *countingHandler) OnToolEnd(string, bool, ...interface{})                           {}
+func (h *countingHandler) OnToolError(error, bool, ...interface{})                          {}
+func (h *countingHandler) OnText(string, bool, ...interface{})                              {}
+func (h *countingHandler) OnAgentAction(rootSchema.AgentAction, bool, ...interface{})       {}
+func (h *countingHandler) OnAgentFinish(rootSchema.AgentFinish, bool, ...interface{})       {}
+
+// newTestChain returns a chain echoing its "in" input as "out", failing on "fail". The counter
+// tracks how many calls are running at once and the highest number seen.
+func newTestChain(handler *countingHandler, running, peak *int32) *BaseChain {
+	return &BaseChain{
+		CallbackManager: callbackSchema.NewCallbackManager([]callbackSchema.BaseCallbackHandler{handler}),
+		CallFunc: func(ctx context.Context, inputs map[string]interface{}) (map[string]interface{}, error) {
+			now := atomic.AddInt32(running, 1)
+			defer atomic.AddInt32(running, -1)
+			for {
+				highest := atomic.LoadInt32(peak)
+				if now <= highest || atomic.CompareAndSwapInt32(peak, highest, now) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+			if inputs["in"] == "fail" {
+				return nil, errors.New("failed")
+			}
+			return map[string]interface{}{"out": inputs["in"]}, nil
+		},
+	}
+}
+
+func TestApplyConcurrent(t *testing.T) {
+	var inputs []map[string]interface{}
+	for i := 0; i < 20; i++ {
+		in := fmt.Sprint(i)
+		if i%7 == 3 {
+			in = "fail"
+		}
+		inputs = append(inputs, map[string]interface{}{"in": in})
+	}
+
+	for _, maxWorkers := range []int{1, 3, 0} {
+		t.Run(fmt.Sprintf("max workers %d", maxWorkers), func(t *testing.T) {
+			handler := &countingHandler{}
+			var running, peak int32
+			results := newTestChain(handler, &running, &peak).ApplyConcurrent(context.Background(), inputs, maxWorkers)
+
+			if len(results) != len(inputs) {
+				t.Fatalf("got %d results for %d inputs", len(results), len(inputs))
+			}
+			for i, result := range results {
+				if inputs[i]["in"] == "fail" {
+					if result.Err == nil || result.Output != nil {
+						t.Errorf("input %d: got %+v, want only an error", i, result)
+					}
+					continue
+				}
+				if result.Err != nil || result.Output["out"] != inputs[i]["in"] {
+					t.Errorf("input %d: got %+v, want out %v", i, result, inputs[i]["in"])
+				}
+			}
+			if errs := ApplyResultErrors(results); len(errs) != 3 || errs[3] == nil || errs[10] == nil || errs[17] == nil {
+				t.Errorf("got errors %v, want inputs 3, 10 and 17", errs)
+			}
+
+			limit := int32(maxWorkers)
+			if limit <= 0 {
+				limit = DefaultMaxWorkers
+			}
+			if peak > limit {
+				t.Errorf("%d calls ran at once, want at most %d", peak, limit)
+			}
+			if handler.starts != len(inputs) || handler.ends != len(inputs)-3 || handler.errors != 3 {
+				t.Errorf("got %d starts, %d ends and %d errors", handler.starts, handler.ends, handler.errors)
+			}
+		})
+	}
+}
+
+func TestApplyConcurrentCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var running, peak int32
+	results := newTestChain(&countingHandler{}, &running, &peak).ApplyConcurrent(ctx, []map[string]interface{}{{"in": "a"}, {"in": "b"}}, 1)
+	for i, result := range results {
+		if !errors.Is(result.Err, context.Canceled) {
+			t.Errorf("input %d: got %+v, want context.Canceled", i, result)
+		}
+	}
+}
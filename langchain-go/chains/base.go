@@ -1,6 +1,7 @@
 package chains
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"github.com/William-Bohm/langchain-go/langchain-go/callbacks"
@@ -25,12 +26,22 @@ type Chain interface {
 	Run(...interface{}) (string, error)
 	ToDict() map[string]interface{}
 	Save(string) error
+
+	// context-first variants, the plain methods above run with context.Background()
+	CallWithContext(context.Context, map[string]interface{}, ...bool) (map[string]interface{}, error)
+	ExecuteWithContext(context.Context, map[string]interface{}, bool) (interface{}, error)
+	ApplyWithContext(context.Context, []map[string]interface{}) ([]map[string]string, error)
+	RunWithContext(context.Context, ...interface{}) (string, error)
+	ApplyConcurrent(context.Context, []map[string]interface{}, int) []ApplyResult
 }
 
 type BaseChain struct {
 	Memory          memorySchema.BaseMemory
 	CallbackManager callbackSchema.BaseCallbackManager
 	Verbose         bool
+	// CallFunc is the chain's own logic, for chains outside this package that embed BaseChain and
+	// use its Call, Execute, Run, Apply and ApplyConcurrent.
+	CallFunc CallFunc
 }
 
 func NewDefaultBaseChain() BaseChain {
@@ -73,49 +84,82 @@ func (bc *BaseChain) ValidateOutputs(outputs map[string]string) error {
 	return nil
 }
 
-func (c *BaseChain) call(args map[string]interface{}) (map[string]interface{}, error) {
-	return map[string]interface{}{}, nil
+// CallFunc is a chain's own logic, run by callWith between the chain callbacks. Go has no virtual
+// methods, so chains embedding BaseChain either set BaseChain.CallFunc or, inside this package, pass
+// theirs in from their own Call, Execute and Run.
+type CallFunc func(ctx context.Context, inputs map[string]interface{}) (map[string]interface{}, error)
+
+func (c *BaseChain) call(ctx context.Context, args map[string]interface{}) (map[string]interface{}, error) {
+	if c.CallFunc == nil {
+		return nil, errors.New("call must be implemented by the chain")
+	}
+	return c.CallFunc(ctx, args)
 }
 
 func (c *BaseChain) Call(inputs map[string]interface{}, returnOnlyOutputs ...bool) (map[string]interface{}, error) {
+	return c.CallWithContext(context.Background(), inputs, returnOnlyOutputs...)
+}
+
+// CallWithContext runs the chain, aborting before the call starts if ctx is already done.
+// ctx is passed through to the underlying call so LLM requests can be cancelled mid-flight.
+func (c *BaseChain) CallWithContext(ctx context.Context, inputs map[string]interface{}, returnOnlyOutputs ...bool) (map[string]interface{}, error) {
+	return c.callWith(ctx, c.call, inputs, returnOnlyOutputs...)
+}
+
+func (c *BaseChain) callWith(ctx context.Context, call CallFunc, inputs map[string]interface{}, returnOnlyOutputs ...bool) (map[string]interface{}, error) {
 	var roo bool
 	if len(returnOnlyOutputs) > 0 {
 		roo = returnOnlyOutputs[0]
 	}
 
-	inputsPrep, err := c.PrepareInputs(inputs)
-	if err != nil {
+	if err := ctx.Err(); err != nil {
 		return nil, err
 	}
 
-	_, err = c.CallbackManager.OnChainStart(map[string]interface{}{"name": reflect.TypeOf(c).Name()}, inputsPrep, c.Verbose)
+	inputsPrep, err := c.PrepareInputs(inputs)
 	if err != nil {
 		return nil, err
 	}
 
-	outputs, err := c.call(inputsPrep)
-	if err != nil {
-		_, _ = c.CallbackManager.OnChainError(err, c.Verbose)
-		return nil, err
-	}
+	c.CallbackManager.OnChainStart(map[string]interface{}{"name": reflect.TypeOf(c).Name()}, inputsPrep, c.Verbose)
 
-	_, err = c.CallbackManager.OnChainEnd(outputs, c.Verbose)
+	outputs, err := call(ctx, inputsPrep)
 	if err != nil {
+		c.CallbackManager.OnChainError(err, c.Verbose)
 		return nil, err
 	}
 
+	c.CallbackManager.OnChainEnd(outputs, c.Verbose)
+
 	return c.PrepareOutputs(inputsPrep, outputs, roo), nil
 }
 
 func (bc *BaseChain) Execute(
 	inputs map[string]interface{},
 	returnOnlyOutputs bool,
+) (interface{}, error) {
+	return bc.ExecuteWithContext(context.Background(), inputs, returnOnlyOutputs)
+}
+
+func (bc *BaseChain) ExecuteWithContext(
+	ctx context.Context,
+	inputs map[string]interface{},
+	returnOnlyOutputs bool,
+) (interface{}, error) {
+	return bc.executeWith(ctx, bc.call, inputs, returnOnlyOutputs)
+}
+
+func (bc *BaseChain) executeWith(
+	ctx context.Context,
+	call CallFunc,
+	inputs map[string]interface{},
+	returnOnlyOutputs bool,
 ) (interface{}, error) {
 	preparedInputs, err := bc.PrepareInputs(inputs)
 	if err != nil {
 		return nil, err
 	}
-	outputs, err := bc.Call(preparedInputs)
+	outputs, err := bc.callWith(ctx, call, preparedInputs)
 	if err != nil {
 		return nil, err
 	}
@@ -149,15 +193,22 @@ func (bc *BaseChain) PrepareInputs(inputs interface{}) (map[string]interface{},
 }
 
 func (bc *BaseChain) Apply(inputList []map[string]interface{}) ([]map[string]string, error) {
+	return bc.ApplyWithContext(context.Background(), inputList)
+}
+
+func (bc *BaseChain) ApplyWithContext(ctx context.Context, inputList []map[string]interface{}) ([]map[string]string, error) {
 	outputList := []map[string]string{}
 	for _, inputs := range inputList {
-		output, err := bc.Execute(inputs, false)
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		output, err := bc.executeWith(ctx, bc.call, inputs, false)
 		if err != nil {
 			return nil, err
 		}
-		outputStr, ok := output.(map[string]string)
-		if !ok {
-			return nil, errors.New("output must be a map[string]string")
+		outputStr, err := toStringMap(output)
+		if err != nil {
+			return nil, err
 		}
 		outputList = append(outputList, outputStr)
 	}
@@ -211,23 +262,31 @@ func mapKeys(m map[string]string) []string {
 }
 
 func (bc *BaseChain) Run(args ...interface{}) (string, error) {
-	if len(bc.OutputKeys()) != 1 {
-		return "", errors.New("`Run` not supported when there is not exactly one output key. Got " + fmt.Sprint(bc.OutputKeys))
+	return bc.RunWithContext(context.Background(), args...)
+}
+
+func (bc *BaseChain) RunWithContext(ctx context.Context, args ...interface{}) (string, error) {
+	return bc.runWith(ctx, bc.call, bc.OutputKeys(), args...)
+}
+
+func (bc *BaseChain) runWith(ctx context.Context, call CallFunc, outputKeys []string, args ...interface{}) (string, error) {
+	if len(outputKeys) != 1 {
+		return "", errors.New("`Run` not supported when there is not exactly one output key. Got " + fmt.Sprint(outputKeys))
 	}
 
 	if len(args) == 1 {
-		output, err := bc.Call(args[0].(map[string]interface{}))
+		output, err := bc.callWith(ctx, call, args[0].(map[string]interface{}))
 		if err != nil {
 			return "", err
 		}
-		return output[bc.OutputKeys()[0]].(string), nil
+		return output[outputKeys[0]].(string), nil
 	} else if len(args) == 0 {
-		output, err := bc.Call(nil)
+		output, err := bc.callWith(ctx, call, nil)
 		if err != nil {
 			return "", err
 		}
-		return output[bc.OutputKeys()[0]].(string), nil
+		return output[outputKeys[0]].(string), nil
 	} else {
-		return "", errors.New("`Run` supported with either one positional argument or no arguments but not more than one. Got args: " + fmt.Sprint(args))
+		return "", errors.New("`Run` supported with either one positional argument or no arguments but not more than one. Got args: " + fmt.Sprintf("%v", args))
 	}
 }
@@ -1,6 +1,7 @@
 package chains
 
 import (
+	"context"
 	"errors"
 	"github.com/William-Bohm/langchain-go/langchain-go/llm/llmSchema"
 	"github.com/William-Bohm/langchain-go/langchain-go/prompt/promptSchema"
@@ -31,12 +32,54 @@ func (c *LLMChain) OutputKeys() []string {
 	return []string{c.OutputKey}
 }
 
+// call predicts with the prompt filled from inputs. Call, Execute, Run and ApplyConcurrent are
+// redeclared below so they reach it, and ctx with it, instead of BaseChain's unimplemented call.
+func (c *LLMChain) call(ctx context.Context, inputs map[string]interface{}) (map[string]interface{}, error) {
+	text, err := c.PredictWithContext(ctx, inputs)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{c.OutputKey: text}, nil
+}
+
+func (c *LLMChain) Call(inputs map[string]interface{}, returnOnlyOutputs ...bool) (map[string]interface{}, error) {
+	return c.CallWithContext(context.Background(), inputs, returnOnlyOutputs...)
+}
+
+func (c *LLMChain) CallWithContext(ctx context.Context, inputs map[string]interface{}, returnOnlyOutputs ...bool) (map[string]interface{}, error) {
+	return c.callWith(ctx, c.call, inputs, returnOnlyOutputs...)
+}
+
+func (c *LLMChain) Execute(inputs map[string]interface{}, returnOnlyOutputs bool) (interface{}, error) {
+	return c.ExecuteWithContext(context.Background(), inputs, returnOnlyOutputs)
+}
+
+func (c *LLMChain) ExecuteWithContext(ctx context.Context, inputs map[string]interface{}, returnOnlyOutputs bool) (interface{}, error) {
+	return c.executeWith(ctx, c.call, inputs, returnOnlyOutputs)
+}
+
+func (c *LLMChain) Run(args ...interface{}) (string, error) {
+	return c.RunWithContext(context.Background(), args...)
+}
+
+func (c *LLMChain) RunWithContext(ctx context.Context, args ...interface{}) (string, error) {
+	return c.runWith(ctx, c.call, c.OutputKeys(), args...)
+}
+
+func (c *LLMChain) ApplyConcurrent(ctx context.Context, inputList []map[string]interface{}, maxWorkers int) []ApplyResult {
+	return c.applyConcurrentWith(ctx, c.call, inputList, maxWorkers)
+}
+
 func (c *LLMChain) Generate(inputList []map[string]interface{}) (*llmSchema.LLMResult, error) {
+	return c.GenerateWithContext(context.Background(), inputList)
+}
+
+func (c *LLMChain) GenerateWithContext(ctx context.Context, inputList []map[string]interface{}) (*llmSchema.LLMResult, error) {
 	prompts, stop, err := c.prepPrompts(inputList)
 	if err != nil {
 		return nil, err
 	}
-	result, err := c.LLM.Generate(prompts, []string{stop})
+	result, err := c.LLM.GenerateWithContext(ctx, prompts, []string{stop})
 	if err != nil {
 		return nil, err
 	}
@@ -76,7 +119,11 @@ func (c *LLMChain) prepPrompts(inputList []map[string]interface{}) ([]string, st
 }
 
 func (c *LLMChain) Apply(inputList []map[string]interface{}) ([]map[string]string, error) {
-	response, err := c.Generate(inputList)
+	return c.ApplyWithContext(context.Background(), inputList)
+}
+
+func (c *LLMChain) ApplyWithContext(ctx context.Context, inputList []map[string]interface{}) ([]map[string]string, error) {
+	response, err := c.GenerateWithContext(ctx, inputList)
 	if err != nil {
 		return nil, err
 	}
@@ -92,7 +139,11 @@ func (c *LLMChain) CreateOutputs(response *llmSchema.LLMResult) []map[string]str
 }
 
 func (c *LLMChain) Predict(inputs map[string]interface{}) (string, error) {
-	output, err := c.Apply([]map[string]interface{}{inputs})
+	return c.PredictWithContext(context.Background(), inputs)
+}
+
+func (c *LLMChain) PredictWithContext(ctx context.Context, inputs map[string]interface{}) (string, error) {
+	output, err := c.ApplyWithContext(ctx, []map[string]interface{}{inputs})
 	if err != nil {
 		return "", err
 	}
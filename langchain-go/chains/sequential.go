@@ -1,6 +1,7 @@
 package chains
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"github.com/William-Bohm/langchain-go/langchain-go/callbacks/callbackSchema"
@@ -112,6 +113,11 @@ func NewSimpleSequentialChain(chains []Chain, stripOutputs bool, inputKey, outpu
 }
 
 func (s *SimpleSequentialChain) Call(inputs map[string]string) (map[string]string, error) {
+	return s.CallWithContext(context.Background(), inputs)
+}
+
+// CallWithContext runs each chain in turn, stopping before the next chain once ctx is done.
+func (s *SimpleSequentialChain) CallWithContext(ctx context.Context, inputs map[string]string) (map[string]string, error) {
 	input, ok := inputs[s.inputKey]
 	if !ok {
 		return nil, errors.New("Input key not found.")
@@ -124,7 +130,10 @@ func (s *SimpleSequentialChain) Call(inputs map[string]string) (map[string]strin
 	colorMapping := tools.GetColorMapping(items)
 
 	for i, chain := range s.chains {
-		output, err := chain.Run(input)
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		output, err := chain.RunWithContext(ctx, input)
 		if err != nil {
 			return nil, err
 		}
@@ -1,5 +1,7 @@
 package chains
 
+import "context"
+
 type TransformFunc func(map[string]string) (map[string]string, error)
 
 type TransformChain struct {
@@ -19,3 +21,11 @@ func (t *TransformChain) OutputKeys() []string {
 func (t *TransformChain) Call(inputs map[string]string) (map[string]string, error) {
 	return t.Transform(inputs)
 }
+
+// CallWithContext runs the transform unless ctx is already done; the transform itself is not interruptible.
+func (t *TransformChain) CallWithContext(ctx context.Context, inputs map[string]string) (map[string]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return t.Transform(inputs)
+}
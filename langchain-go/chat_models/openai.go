@@ -1 +1,424 @@
 package chat_models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/William-Bohm/langchain-go/langchain-go/callbacks/callbackSchema"
+	"github.com/William-Bohm/langchain-go/langchain-go/chat_models/schema"
+	"github.com/William-Bohm/langchain-go/langchain-go/config/logger"
+	"github.com/William-Bohm/langchain-go/langchain-go/llm/openai/openaiClient"
+	"github.com/William-Bohm/langchain-go/langchain-go/rootSchema"
+	"github.com/William-Bohm/langchain-go/langchain-go/tools/mapTools"
+)
+
+const (
+	openaiApiKeyEnvVarName       = "OPENAI_API_KEY"
+	openaiOrganizationEnvVarName = "OPENAI_ORGANIZATION_ID"
+	openaiApiBaseEnvVarName      = "OPENAI_API_BASE"
+	defaultOpenaiApiBase         = "https://api.openai.com/v1"
+	chatCompletionsPath          = "/chat/completions"
+)
+
+// ChatOpenAI is a chat model backed by the OpenAI chat completions endpoint (gpt-3.5-turbo, gpt-4, ...).
+type ChatOpenAI struct {
+	*schema.BaseChatModel
+	Client             *openaiClient.OpenAiClient
+	Model              openaiClient.Model
+	ModelKwargs        map[string]interface{}
+	Temperature        float64     `comment:"What sampling temperature to use."`
+	MaxTokens          int         `comment:"Maximum number of tokens to generate, 0 lets the API decide."`
+	N                  int         `comment:"How many chat completions to generate for each conversation."`
+	Stop               []string    `comment:"Default stop sequences, used when Generate is not given any."`
+	OpenaiApiKey       *string     `comment:"Optional OpenAI API keys and organization."`
+	OpenaiOrganization *string     `comment:"Optional OpenAI API keys and organization."`
+	OpenaiApiBase      string      `comment:"Base URL of the OpenAI API, the chat completions path is appended."`
+	RequestTimeout     interface{} `comment:"Timeout for requests to OpenAI chat completion API. Default is 600 seconds."`
+	MaxRetries         int         `comment:"Maximum number of retries to make when generating."`
+	CompletionTokens   float64
+	PromptTokens       float64
+	TotalTokens        float64
+}
+
+// NewChatOpenAI creates a ChatOpenAI with the same defaults as the completion model, using gpt-3.5-turbo.
+// The API key falls back to OPENAI_API_KEY when no OpenaiApiKey option is given.
+func NewChatOpenAI(callbackManager callbackSchema.BaseCallbackManager, verbose bool, options ...Option) (*ChatOpenAI, error) {
+	c := &ChatOpenAI{
+		BaseChatModel:      schema.NewBaseChatModel(verbose, callbackManager),
+		Model:              openaiClient.GPT35_Turbo,
+		ModelKwargs:        nil,
+		Temperature:        0.7,
+		MaxTokens:          0,
+		N:                  1,
+		Stop:               nil,
+		OpenaiApiKey:       nil,
+		OpenaiOrganization: nil,
+		OpenaiApiBase:      os.Getenv(openaiApiBaseEnvVarName),
+		RequestTimeout:     600,
+		MaxRetries:         2,
+	}
+
+	for _, opt := range options {
+		if err := opt(c); err != nil {
+			return nil, err
+		}
+	}
+
+	if c.OpenaiApiKey == nil {
+		if err := OpenaiApiKey("")(c); err != nil {
+			return nil, err
+		}
+	}
+	if c.OpenaiApiBase == "" {
+		c.OpenaiApiBase = defaultOpenaiApiBase
+	}
+
+	var organization string
+	if c.OpenaiOrganization != nil {
+		organization = *c.OpenaiOrganization
+	}
+	client, err := openaiClient.NewOpenAiChatClient(
+		*c.OpenaiApiKey,
+		organization,
+		strings.TrimSuffix(c.OpenaiApiBase, "/")+chatCompletionsPath,
+		c.MaxRetries,
+	)
+	if err != nil {
+		return nil, err
+	}
+	c.Client = client
+
+	return c, nil
+}
+
+// Generate runs one chat completion per conversation in messages.
+func (c *ChatOpenAI) Generate(messages [][]rootSchema.BaseMessageInterface, stop []string) (*schema.ChatLLMResult, error) {
+	return c.GenerateWithContext(context.Background(), messages, stop)
+}
+
+func (c *ChatOpenAI) GenerateWithContext(ctx context.Context, messages [][]rootSchema.BaseMessageInterface, stop []string) (*schema.ChatLLMResult, error) {
+	params, err := c.invocationParams(stop)
+	if err != nil {
+		return nil, err
+	}
+	return c.generateWithParams(ctx, messages, params)
+}
+
+// CallWithFunctions sends a single conversation along with the functions the model may call.
+// When the model decides to call one, the returned message has an empty Content and carries the
+// call in AdditionalKwargs["function_call"] (*openaiClient.FunctionCall), or in
+// AdditionalKwargs["tool_calls"] ([]openaiClient.ToolCall) for parallel calls.
+func (c *ChatOpenAI) CallWithFunctions(ctx context.Context, messages []rootSchema.BaseMessageInterface, functions []openaiClient.FunctionDefinition) (*rootSchema.AIMessage, error) {
+	params, err := c.invocationParams(nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(functions) > 0 {
+		params["functions"] = functions
+	}
+
+	result, err := c.generateWithParams(ctx, [][]rootSchema.BaseMessageInterface{messages}, params)
+	if err != nil {
+		return nil, err
+	}
+	return firstMessage(result)
+}
+
+func (c *ChatOpenAI) generateWithParams(ctx context.Context, messages [][]rootSchema.BaseMessageInterface, params map[string]interface{}) (*schema.ChatLLMResult, error) {
+	ctx, cancel := c.withRequestTimeout(ctx)
+	defer cancel()
+
+	var err error
+	promptStrings := make([]string, len(messages))
+	for i, conversation := range messages {
+		promptStrings[i], err = rootSchema.GetBufferString(conversation)
+		if err != nil {
+			return nil, err
+		}
+	}
+	c.CallbackManager().OnLLMStart(map[string]interface{}{"name": "ChatOpenAI"}, promptStrings, c.Verbose())
+
+	generations := make([][]schema.ChatGeneration, len(messages))
+	tokenUsage := make(map[string]float64)
+	for i, conversation := range messages {
+		result, err := c.generate(ctx, conversation, params)
+		if err != nil {
+			c.CallbackManager().OnLLMError(err, c.Verbose())
+			return nil, err
+		}
+		generations[i] = result.Generations
+		for k, v := range result.LLMOutput["token_usage"].(map[string]float64) {
+			tokenUsage[k] += v
+		}
+	}
+
+	output := &schema.ChatLLMResult{
+		Generations: generations,
+		LLMOutput: map[string]interface{}{
+			"token_usage": tokenUsage,
+			"model_name":  c.Model,
+		},
+	}
+	c.CallbackManager().OnLLMEnd(*output.ToLLMResult(), c.Verbose())
+	return output, nil
+}
+
+// Call sends a single conversation and returns the first reply.
+func (c *ChatOpenAI) Call(messages []rootSchema.BaseMessageInterface, stop []string) (*rootSchema.AIMessage, error) {
+	result, err := c.Generate([][]rootSchema.BaseMessageInterface{messages}, stop)
+	if err != nil {
+		return nil, err
+	}
+	return firstMessage(result)
+}
+
+// firstMessage returns the first reply to the first conversation, which the API may leave out.
+func firstMessage(result *schema.ChatLLMResult) (*rootSchema.AIMessage, error) {
+	if len(result.Generations) == 0 || len(result.Generations[0]) == 0 {
+		return nil, errors.New("the chat completion returned no choices")
+	}
+	return result.Generations[0][0].Message, nil
+}
+
+func (c *ChatOpenAI) GetNumTokensFromMessage(messages []rootSchema.BaseMessage) (int, error) {
+	var fullText string
+	for _, message := range messages {
+		fullText += message.Content
+	}
+	return c.GetNumTokensFromText(fullText)
+}
+
+func (c *ChatOpenAI) GetNumTokensFromText(text string) (int, error) {
+	return openaiClient.GetNumTokensForText(text, c.Model)
+}
+
+func (c *ChatOpenAI) generate(ctx context.Context, messages []rootSchema.BaseMessageInterface, params map[string]interface{}) (*schema.ChatResult, error) {
+	openaiMessages, err := convertMessagesToOpenai(messages)
+	if err != nil {
+		return nil, err
+	}
+
+	// the client retries failed connections, 429 and 5xx responses MaxRetries times itself
+	response, err := c.Client.CreateChat(ctx, openaiMessages, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.createChatResult(response), nil
+}
+
+func (c *ChatOpenAI) createChatResult(response *openaiClient.ChatCompletionResponsePayload) *schema.ChatResult {
+	generations := make([]schema.ChatGeneration, len(response.Choices))
+	for i, choice := range response.Choices {
+		message := rootSchema.NewAIMessage(choice.Message.Content)
+		if choice.Message.FunctionCall != nil {
+			message.AdditionalKwargs["function_call"] = choice.Message.FunctionCall
+		}
+		if len(choice.Message.ToolCalls) > 0 {
+			message.AdditionalKwargs["tool_calls"] = choice.Message.ToolCalls
+		}
+		generations[i] = schema.NewChatGeneration(message, map[string]interface{}{
+			"finish_reason": choice.FinishReason,
+		})
+	}
+
+	c.updateTokenUsage(response.Usage.CompletionTokens, response.Usage.PromptTokens, response.Usage.TotalTokens)
+	tokenUsage := map[string]float64{
+		"completion_tokens": response.Usage.CompletionTokens,
+		"prompt_tokens":     response.Usage.PromptTokens,
+		"total_tokens":      response.Usage.TotalTokens,
+	}
+
+	return &schema.ChatResult{
+		Generations: generations,
+		LLMOutput: map[string]interface{}{
+			"token_usage": tokenUsage,
+			"model_name":  c.Model,
+		},
+	}
+}
+
+func (c *ChatOpenAI) updateTokenUsage(completionTokens float64, promptTokens float64, totalTokens float64) {
+	c.PromptTokens = c.PromptTokens + promptTokens
+	c.CompletionTokens = c.CompletionTokens + completionTokens
+	c.TotalTokens = c.TotalTokens + totalTokens
+}
+
+func (c *ChatOpenAI) defaultParams() map[string]interface{} {
+	normalParams := map[string]interface{}{
+		"model_name":  string(c.Model),
+		"temperature": c.Temperature,
+		"max_tokens":  c.MaxTokens,
+		"n":           c.N,
+	}
+	return mapTools.MergeMaps(normalParams, c.ModelKwargs)
+}
+
+func (c *ChatOpenAI) invocationParams(stop []string) (map[string]interface{}, error) {
+	params := c.defaultParams()
+	if stop == nil {
+		stop = c.Stop
+	} else if c.Stop != nil {
+		return nil, errors.New("`stop` found in both the input and default params")
+	}
+	if stop != nil {
+		params["stop"] = stop
+	}
+	return params, nil
+}
+
+// withRequestTimeout derives a context bounded by RequestTimeout (seconds, or a time.Duration).
+func (c *ChatOpenAI) withRequestTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	var timeout time.Duration
+	switch rt := c.RequestTimeout.(type) {
+	case time.Duration:
+		timeout = rt
+	case int:
+		timeout = time.Duration(rt) * time.Second
+	case float64:
+		timeout = time.Duration(rt * float64(time.Second))
+	}
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// convertMessagesToOpenai maps langchain messages onto the chat completions roles.
+func convertMessagesToOpenai(messages []rootSchema.BaseMessageInterface) ([]openaiClient.Message, error) {
+	openaiMessages := make([]openaiClient.Message, len(messages))
+	for i, message := range messages {
+		openaiMessage := openaiClient.Message{Content: message.GetContent()}
+		switch m := message.(type) {
+		case *rootSchema.ChatMessage:
+			openaiMessage.Role = m.Role
+		case *rootSchema.HumanMessage:
+			openaiMessage.Role = "user"
+		case *rootSchema.AIMessage:
+			openaiMessage.Role = "assistant"
+			if functionCall, ok := m.AdditionalKwargs["function_call"].(*openaiClient.FunctionCall); ok {
+				openaiMessage.FunctionCall = functionCall
+			}
+			if toolCalls, ok := m.AdditionalKwargs["tool_calls"].([]openaiClient.ToolCall); ok {
+				openaiMessage.ToolCalls = toolCalls
+			}
+		case *rootSchema.SystemMessage:
+			openaiMessage.Role = "system"
+		case *rootSchema.FunctionMessage:
+			openaiMessage.Role = "function"
+			openaiMessage.Name = m.Name
+		default:
+			return nil, fmt.Errorf("got unknown message type: %T", message)
+		}
+		openaiMessages[i] = openaiMessage
+	}
+	return openaiMessages, nil
+}
+
+/*
+ * Options Pattern for ChatOpenAI, see the openai package for a description of the pattern.
+ */
+
+type Option func(*ChatOpenAI) error
+
+func Model(m string) Option {
+	return func(c *ChatOpenAI) error {
+		model := openaiClient.GPT35_Turbo
+		if m != "" {
+			tempModel := openaiClient.Model(m)
+			if !strings.HasPrefix(m, "gpt-") {
+				logger.Error("Invalid chat model: " + m)
+				return fmt.Errorf("invalid chat model: %s", m)
+			}
+			model = tempModel
+		}
+		c.Model = model
+		return nil
+	}
+}
+
+func ModelKwargs(mk map[string]interface{}) Option {
+	return func(c *ChatOpenAI) error {
+		c.ModelKwargs = mk
+		return nil
+	}
+}
+
+func Temperature(t float64) Option {
+	return func(c *ChatOpenAI) error {
+		c.Temperature = t
+		return nil
+	}
+}
+
+func MaxTokens(mt int) Option {
+	return func(c *ChatOpenAI) error {
+		c.MaxTokens = mt
+		return nil
+	}
+}
+
+func N(n int) Option {
+	return func(c *ChatOpenAI) error {
+		c.N = n
+		return nil
+	}
+}
+
+func Stop(stop []string) Option {
+	return func(c *ChatOpenAI) error {
+		c.Stop = stop
+		return nil
+	}
+}
+
+func OpenaiApiKey(key string) Option {
+	return func(c *ChatOpenAI) error {
+		if key == "" {
+			key = os.Getenv(openaiApiKeyEnvVarName)
+			if key == "" {
+				return errors.New("OPENAI_API_KEY not provided or set as environment variable")
+			}
+		}
+		c.OpenaiApiKey = &key
+		return nil
+	}
+}
+
+func OpenaiOrganization(org string) Option {
+	return func(c *ChatOpenAI) error {
+		if org == "" {
+			org = os.Getenv(openaiOrganizationEnvVarName)
+			if org == "" {
+				return errors.New("OPENAI_ORGANIZATION not provided or set as environment variable")
+			}
+		}
+		c.OpenaiOrganization = &org
+		return nil
+	}
+}
+
+func OpenaiApiBase(base string) Option {
+	return func(c *ChatOpenAI) error {
+		c.OpenaiApiBase = base
+		return nil
+	}
+}
+
+func RequestTimeout(rt interface{}) Option {
+	return func(c *ChatOpenAI) error {
+		c.RequestTimeout = rt
+		return nil
+	}
+}
+
+func MaxRetries(mr int) Option {
+	return func(c *ChatOpenAI) error {
+		c.MaxRetries = mr
+		return nil
+	}
+}
@@ -0,0 +1,132 @@
+package chat_models
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/William-Bohm/langchain-go/langchain-go/llm/openai/openaiClient"
+	"github.com/William-Bohm/langchain-go/langchain-go/rootSchema"
+)
+
+type chatRequest struct {
+	Model     string                            `json:"model"`
+	Messages  []openaiClient.Message            `json:"messages"`
+	Functions []openaiClient.FunctionDefinition `json:"functions"`
+}
+
+// newTestChatOpenAI points a ChatOpenAI at a server that rate limits the first requests with all but
+// the last response body, answers the rest with the last one, and records the requests it got.
+func newTestChatOpenAI(t *testing.T, responses ...string) (*ChatOpenAI, *[]chatRequest) {
+	var requests []chatRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" || r.Header.Get("Authorization") != "Bearer key" {
+			t.Errorf("got %s with headers %v", r.URL.Path, r.Header)
+		}
+		var request chatRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Errorf("decoding request: %v", err)
+		}
+		requests = append(requests, request)
+		if len(requests) < len(responses) {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			io.WriteString(w, responses[len(requests)-1])
+			return
+		}
+		io.WriteString(w, responses[len(responses)-1])
+	}))
+	t.Cleanup(server.Close)
+
+	c, err := NewChatOpenAI(nil, false, OpenaiApiKey("key"), OpenaiApiBase(server.URL+"/v1"), MaxRetries(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c, &requests
+}
+
+func TestChatOpenAICall(t *testing.T) {
+	c, requests := newTestChatOpenAI(t, `{
+		"choices": [{"message": {"role": "assistant", "content": "Hello!"}, "finish_reason": "stop"}],
+		"usage": {"completion_tokens": 2, "prompt_tokens": 5, "total_tokens": 7}
+	}`)
+	message, err := c.Call([]rootSchema.BaseMessageInterface{
+		rootSchema.NewSystemMessage("Be nice."),
+		rootSchema.NewHumanMessage("Hi"),
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if message.Content != "Hello!" {
+		t.Errorf("got %q, want Hello!", message.Content)
+	}
+	if c.TotalTokens != 7 || c.PromptTokens != 5 || c.CompletionTokens != 2 {
+		t.Errorf("got token usage %v/%v/%v, want 5/2/7", c.PromptTokens, c.CompletionTokens, c.TotalTokens)
+	}
+
+	request := (*requests)[0]
+	if request.Model != string(openaiClient.GPT35_Turbo) || len(request.Messages) != 2 ||
+		request.Messages[0].Role != "system" || request.Messages[1].Role != "user" || request.Messages[1].Content != "Hi" {
+		t.Errorf("got request %+v", request)
+	}
+}
+
+func TestChatOpenAIRetries(t *testing.T) {
+	c, requests := newTestChatOpenAI(t,
+		`{"error": {"message": "slow down"}}`,
+		`{"error": {"message": "slow down"}}`,
+		`{"choices": [{"message": {"role": "assistant", "content": "Hello!"}}]}`,
+	)
+	message, err := c.Call([]rootSchema.BaseMessageInterface{rootSchema.NewHumanMessage("Hi")}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if message.Content != "Hello!" {
+		t.Errorf("got %q, want Hello!", message.Content)
+	}
+	if len(*requests) != 3 {
+		t.Fatalf("sent %d requests, want 3", len(*requests))
+	}
+	// every attempt carries the whole conversation, not a body an earlier attempt already read
+	for i, request := range *requests {
+		if len(request.Messages) != 1 || request.Messages[0].Content != "Hi" {
+			t.Errorf("attempt %d sent %+v", i+1, request)
+		}
+	}
+}
+
+func TestChatOpenAINoChoices(t *testing.T) {
+	c, _ := newTestChatOpenAI(t, `{"choices": []}`)
+	messages := []rootSchema.BaseMessageInterface{rootSchema.NewHumanMessage("Hi")}
+	if _, err := c.Call(messages, nil); err == nil {
+		t.Error("Call got no error for a response without choices")
+	}
+	if _, err := c.CallWithFunctions(context.Background(), messages, nil); err == nil {
+		t.Error("CallWithFunctions got no error for a response without choices")
+	}
+}
+
+func TestChatOpenAICallWithFunctions(t *testing.T) {
+	c, requests := newTestChatOpenAI(t, `{"choices": [{
+		"message": {"role": "assistant", "content": null, "function_call": {"name": "search", "arguments": "{\"query\": \"go\"}"}},
+		"finish_reason": "function_call"
+	}]}`)
+	functions := []openaiClient.FunctionDefinition{{
+		Name:       "search",
+		Parameters: map[string]interface{}{"type": "object"},
+	}}
+	message, err := c.CallWithFunctions(context.Background(), []rootSchema.BaseMessageInterface{rootSchema.NewHumanMessage("Find go")}, functions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	call, ok := message.AdditionalKwargs["function_call"].(*openaiClient.FunctionCall)
+	if !ok || call.Name != "search" || call.Arguments != `{"query": "go"}` {
+		t.Fatalf("got %+v, want the search call", message.AdditionalKwargs)
+	}
+	if got := (*requests)[0].Functions; len(got) != 1 || got[0].Name != "search" {
+		t.Errorf("sent functions %+v", got)
+	}
+}
@@ -3,7 +3,7 @@ package schema
 import (
 	"errors"
 	"github.com/William-Bohm/langchain-go/langchain-go/callbacks/callbackSchema"
-	"github.com/William-Bohm/langchain-go/langchain-go/llm/llmSchema"
+	"github.com/William-Bohm/langchain-go/langchain-go/outputParser/outputParserSchema"
 	"github.com/William-Bohm/langchain-go/langchain-go/rootSchema"
 	"sync"
 )
@@ -23,7 +23,7 @@ type BaseChatModel struct {
 
 func NewBaseChatModel(verbose bool, cm callbackSchema.BaseCallbackManager) *BaseChatModel {
 	if cm == nil {
-		cm = callbackSchema.GetCallbackManager()
+		cm = callbackSchema.NewCallbackManager([]callbackSchema.BaseCallbackHandler{})
 	}
 	return &BaseChatModel{
 		verbose:               verbose,
@@ -33,37 +33,46 @@ func NewBaseChatModel(verbose bool, cm callbackSchema.BaseCallbackManager) *Base
 	}
 }
 
+func (m *BaseChatModel) Verbose() bool {
+	return m.verbose
+}
+
+func (m *BaseChatModel) CallbackManager() callbackSchema.BaseCallbackManager {
+	return m.callbackManager
+}
+
 func (m *BaseChatModel) combineLLMOutputs(llmOutputs []map[string]interface{}) map[string]interface{} {
 	return make(map[string]interface{})
 }
 
-func (m *BaseChatModel) Generate(messages [][]rootSchema.BaseMessage, stop []string) (llmSchema.LLMResult, error) {
+func (m *BaseChatModel) Generate(messages [][]rootSchema.BaseMessageInterface, stop []string) (*ChatLLMResult, error) {
 	var wg sync.WaitGroup
 	wg.Add(len(messages))
 
-	results := make([]schema.ChatResult, len(messages))
+	results := make([]ChatResult, len(messages))
 	for i, msg := range messages {
-		go func(i int, msg []rootSchema.BaseMessage) {
+		go func(i int, msg []rootSchema.BaseMessageInterface) {
 			defer wg.Done()
 			results[i] = m._generate(msg, stop)
 		}(i, msg)
 	}
 	wg.Wait()
 
-	llmOutput := m.combineLLMOutputs([]map[string]interface{}{})
-	var generations []schema.ChatGeneration
-	for _, res := range results {
-		generations = append(generations, res.generations...)
+	llmOutputs := make([]map[string]interface{}, len(results))
+	generations := make([][]ChatGeneration, len(results))
+	for i, res := range results {
+		llmOutputs[i] = res.LLMOutput
+		generations[i] = res.Generations
 	}
 
-	return llmSchema.LLMResult{
-		generations: generations,
-		llmOutput:   llmOutput,
+	return &ChatLLMResult{
+		Generations: generations,
+		LLMOutput:   m.combineLLMOutputs(llmOutputs),
 	}, nil
 }
 
-func (m *BaseChatModel) GeneratePrompt(prompts []schema.PromptValue, stop []string) (llmSchema.LLMResult, error) {
-	var promptMessages [][]rootSchema.BaseMessage
+func (m *BaseChatModel) GeneratePrompt(prompts []outputParserSchema.PromptValue, stop []string) (*ChatLLMResult, error) {
+	var promptMessages [][]rootSchema.BaseMessageInterface
 	var promptStrings []string
 	for _, p := range prompts {
 		promptMessages = append(promptMessages, p.ToMessages())
@@ -81,19 +90,19 @@ func (m *BaseChatModel) GeneratePrompt(prompts []schema.PromptValue, stop []stri
 	output, err := m.Generate(promptMessages, stop)
 	if err != nil {
 		m.callbackManager.OnLLMError(err, m.verbose)
-		return schema.LLMResult{}, err
+		return nil, err
 	}
 
-	m.callbackManager.OnLLMEnd(output, m.verbose)
+	m.callbackManager.OnLLMEnd(*output.ToLLMResult(), m.verbose)
 	return output, nil
 }
 
-func (m *BaseChatModel) _generate(messages []rootSchema.BaseMessage, stop []string) schema.ChatResult {
+func (m *BaseChatModel) _generate(messages []rootSchema.BaseMessageInterface, stop []string) ChatResult {
 	panic(errors.New("_generate not implemented"))
 }
 
-func (m *BaseChatModel) Call(messages []rootSchema.BaseMessage, stop []string) rootSchema.BaseMessage {
-	return m._generate(messages, stop).generations[0].message
+func (m *BaseChatModel) Call(messages []rootSchema.BaseMessageInterface, stop []string) *rootSchema.AIMessage {
+	return m._generate(messages, stop).Generations[0].Message
 }
 
 type SimpleChatModel struct {
@@ -105,14 +114,14 @@ func NewSimpleChatModel(verbose bool, cm callbackSchema.BaseCallbackManager) *Si
 	return &SimpleChatModel{*base}
 }
 
-func (m *SimpleChatModel) _generate(messages []rootSchema.BaseMessage, stop []string) schema.ChatResult {
+func (m *SimpleChatModel) _generate(messages []rootSchema.BaseMessageInterface, stop []string) ChatResult {
 	outputStr := m._call(messages, stop)
-	message := schema.AIMessage{content: outputStr}
-	generation := schema.ChatGeneration{message: message}
+	message := rootSchema.NewAIMessage(outputStr)
+	generation := NewChatGeneration(message, nil)
 
-	return schema.ChatResult{generations: []schema.ChatGeneration{generation}}
+	return ChatResult{Generations: []ChatGeneration{generation}}
 }
 
-func (m *SimpleChatModel) _call(messages []schema.BaseMessage, stop []string) string {
+func (m *SimpleChatModel) _call(messages []rootSchema.BaseMessageInterface, stop []string) string {
 	panic(errors.New("_call not implemented"))
 }
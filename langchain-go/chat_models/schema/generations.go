@@ -1 +1,50 @@
 package schema
+
+import (
+	"github.com/William-Bohm/langchain-go/langchain-go/llm/llmSchema"
+	"github.com/William-Bohm/langchain-go/langchain-go/rootSchema"
+)
+
+// ChatGeneration is a single chat completion. Text mirrors Message.Content so chat generations
+// can be used anywhere a plain llmSchema.Generation is expected.
+type ChatGeneration struct {
+	llmSchema.Generation
+	Message *rootSchema.AIMessage
+}
+
+func NewChatGeneration(message *rootSchema.AIMessage, generationInfo map[string]interface{}) ChatGeneration {
+	return ChatGeneration{
+		Generation: llmSchema.Generation{
+			Text:           message.Content,
+			GenerationInfo: generationInfo,
+		},
+		Message: message,
+	}
+}
+
+// ChatResult holds the generations for one list of input messages.
+type ChatResult struct {
+	Generations []ChatGeneration
+	LLMOutput   map[string]interface{}
+}
+
+// ChatLLMResult is the chat counterpart of llmSchema.LLMResult, one entry in Generations per input conversation.
+type ChatLLMResult struct {
+	Generations [][]ChatGeneration
+	LLMOutput   map[string]interface{}
+}
+
+// ToLLMResult drops the messages and keeps the text, for callers that only understand llmSchema.LLMResult.
+func (r *ChatLLMResult) ToLLMResult() *llmSchema.LLMResult {
+	generations := make([][]llmSchema.Generation, len(r.Generations))
+	for i, chatGenerations := range r.Generations {
+		generations[i] = make([]llmSchema.Generation, len(chatGenerations))
+		for j, chatGeneration := range chatGenerations {
+			generations[i][j] = chatGeneration.Generation
+		}
+	}
+	return &llmSchema.LLMResult{
+		Generations: generations,
+		LLMOutput:   r.LLMOutput,
+	}
+}
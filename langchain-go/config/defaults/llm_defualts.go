@@ -5,7 +5,6 @@ import "sync"
 const (
 	defaultVerbose         = false
 	defaultCountTokens     = true
-	defaultCache           = "false"
 	defaultCallbackManager = "false"
 )
 
@@ -13,7 +12,6 @@ var (
 	mu              sync.Mutex
 	verbose         = defaultVerbose
 	countTokens     = defaultCountTokens
-	cache           = defaultCache
 	callbackManager = defaultCallbackManager
 )
 
@@ -29,18 +27,6 @@ func GetDefaultVerbose() bool {
 	return verbose
 }
 
-func SetCache(c string) {
-	mu.Lock()
-	defer mu.Unlock()
-	cache = c
-}
-
-func GetDefaultCache() string {
-	mu.Lock()
-	defer mu.Unlock()
-	return cache
-}
-
 func SetCallbackManager(c string) {
 	mu.Lock()
 	defer mu.Unlock()
@@ -0,0 +1,134 @@
+package embedding
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/William-Bohm/langchain-go/langchain-go/embedding/embeddingSchema"
+	"github.com/William-Bohm/langchain-go/langchain-go/storage"
+)
+
+// CacheBackedEmbeddings caches the document embeddings of Underlying in Store, keyed by Namespace
+// and the SHA-256 of the text. Bytes of Namespace that are not letters, digits, . or - are written
+// as _ and two hex digits in keys, so any store accepts them and distinct namespaces never share
+// keys. Only texts missing from the cache are sent to Underlying. Query embeddings are not cached.
+type CacheBackedEmbeddings struct {
+	Underlying embeddingSchema.BaseEmbeddings
+	Store      storage.ByteStore
+	Namespace  string `comment:"Required. Usually the model name, so vectors of different models never mix."`
+}
+
+func NewCacheBackedEmbeddings(underlying embeddingSchema.BaseEmbeddings, store storage.ByteStore, namespace string) (*CacheBackedEmbeddings, error) {
+	if underlying == nil {
+		return nil, errors.New("cache backed embeddings need an underlying embeddings model")
+	}
+	if store == nil {
+		return nil, errors.New("cache backed embeddings need a byte store")
+	}
+	// without one, a store shared by two models would return one model's vectors for the other
+	if namespace == "" {
+		return nil, errors.New("cache backed embeddings need a namespace, e.g. the model name")
+	}
+	return &CacheBackedEmbeddings{Underlying: underlying, Store: store, Namespace: namespace}, nil
+}
+
+func (c *CacheBackedEmbeddings) key(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return escapeNamespace(c.Namespace) + hex.EncodeToString(sum[:])
+}
+
+// escapeNamespace is injective: _ only ever starts an escape, so "gpt/4" and "gpt_4" differ.
+func escapeNamespace(namespace string) string {
+	var b strings.Builder
+	for i := 0; i < len(namespace); i++ {
+		c := namespace[i]
+		if c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '.' || c == '-' {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "_%02x", c)
+		}
+	}
+	return b.String()
+}
+
+func (c *CacheBackedEmbeddings) EmbedDocuments(texts []string) ([][]float64, error) {
+	keys := make([]string, len(texts))
+	for i, text := range texts {
+		keys[i] = c.key(text)
+	}
+	cached, err := c.Store.MGet(keys)
+	if err != nil {
+		return nil, err
+	}
+
+	vectors := make([][]float64, len(texts))
+	// texts missing from the cache, each embedded once even if it occurs several times
+	var missingTexts, missingKeys []string
+	missingPositions := map[string][]int{}
+	for i, value := range cached {
+		if value != nil {
+			vector, err := decodeVector(value)
+			if err != nil {
+				return nil, fmt.Errorf("cached embedding %s: %w", keys[i], err)
+			}
+			vectors[i] = vector
+			continue
+		}
+		if _, ok := missingPositions[keys[i]]; !ok {
+			missingTexts = append(missingTexts, texts[i])
+			missingKeys = append(missingKeys, keys[i])
+		}
+		missingPositions[keys[i]] = append(missingPositions[keys[i]], i)
+	}
+	if len(missingTexts) == 0 {
+		return vectors, nil
+	}
+
+	embedded, err := c.Underlying.EmbedDocuments(missingTexts)
+	if err != nil {
+		return nil, err
+	}
+	if len(embedded) != len(missingTexts) {
+		return nil, fmt.Errorf("embeddings returned %d vectors for %d texts", len(embedded), len(missingTexts))
+	}
+	values := make([][]byte, len(embedded))
+	for i, vector := range embedded {
+		values[i] = encodeVector(vector)
+		for _, position := range missingPositions[missingKeys[i]] {
+			vectors[position] = vector
+		}
+	}
+	if err := c.Store.MSet(missingKeys, values); err != nil {
+		return nil, err
+	}
+	return vectors, nil
+}
+
+func (c *CacheBackedEmbeddings) EmbedQuery(text string) ([]float64, error) {
+	return c.Underlying.EmbedQuery(text)
+}
+
+// encodeVector stores a vector as little-endian float64s, so cached vectors come back bit for bit.
+func encodeVector(vector []float64) []byte {
+	data := make([]byte, 8*len(vector))
+	for i, v := range vector {
+		binary.LittleEndian.PutUint64(data[8*i:], math.Float64bits(v))
+	}
+	return data
+}
+
+func decodeVector(data []byte) ([]float64, error) {
+	if len(data)%8 != 0 {
+		return nil, fmt.Errorf("invalid vector encoding of %d bytes", len(data))
+	}
+	vector := make([]float64, len(data)/8)
+	for i := range vector {
+		vector[i] = math.Float64frombits(binary.LittleEndian.Uint64(data[8*i:]))
+	}
+	return vector, nil
+}
@@ -0,0 +1,82 @@
+package embedding
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/William-Bohm/langchain-go/langchain-go/storage"
+)
+
+// recordingEmbeddings embeds with HashEmbeddings and records the texts of every EmbedDocuments call.
+type recordingEmbeddings struct {
+	*HashEmbeddings
+	calls [][]string
+}
+
+func (e *recordingEmbeddings) EmbedDocuments(texts []string) ([][]float64, error) {
+	e.calls = append(e.calls, texts)
+	return e.HashEmbeddings.EmbedDocuments(texts)
+}
+
+func newTestCacheBackedEmbeddings(t *testing.T, store storage.ByteStore, namespace string) (*CacheBackedEmbeddings, *recordingEmbeddings) {
+	t.Helper()
+	hash, err := NewHashEmbeddings()
+	if err != nil {
+		t.Fatal(err)
+	}
+	underlying := &recordingEmbeddings{HashEmbeddings: hash}
+	c, err := NewCacheBackedEmbeddings(underlying, store, namespace)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c, underlying
+}
+
+func TestCacheBackedEmbeddingsOnlyEmbedsMisses(t *testing.T) {
+	c, underlying := newTestCacheBackedEmbeddings(t, storage.NewInMemoryByteStore(), "hash")
+	if _, err := c.EmbedDocuments([]string{"apple", "banana"}); err != nil {
+		t.Fatal(err)
+	}
+
+	texts := []string{"cherry", "apple", "cherry", "banana", "apple"}
+	got, err := c.EmbedDocuments(texts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := underlying.HashEmbeddings.EmbedDocuments(texts)
+	if !reflect.DeepEqual(got, want) {
+		t.Error("vectors differ from the underlying model's or are out of order")
+	}
+	// the duplicate cherry is embedded once, the cached texts not at all
+	if wantCalls := [][]string{{"apple", "banana"}, {"cherry"}}; !reflect.DeepEqual(underlying.calls, wantCalls) {
+		t.Errorf("underlying model embedded %v, want %v", underlying.calls, wantCalls)
+	}
+
+	if _, err := c.EmbedDocuments(texts); err != nil {
+		t.Fatal(err)
+	}
+	if len(underlying.calls) != 2 {
+		t.Errorf("fully cached texts reached the model: %v", underlying.calls[2:])
+	}
+}
+
+func TestCacheBackedEmbeddingsNamespaces(t *testing.T) {
+	store := storage.NewInMemoryByteStore()
+	namespaces := []string{"gpt/4", "gpt_4", "gpt_2f4", "gpt-4"}
+	for _, namespace := range namespaces {
+		c, underlying := newTestCacheBackedEmbeddings(t, store, namespace)
+		if _, err := c.EmbedDocuments([]string{"apple"}); err != nil {
+			t.Fatal(err)
+		}
+		if len(underlying.calls) != 1 {
+			t.Errorf("namespace %s used the vector cached by another namespace", namespace)
+		}
+	}
+	keys, _ := store.YieldKeys("")
+	if len(keys) != len(namespaces) {
+		t.Errorf("got keys %v, want one per namespace", keys)
+	}
+	if got := escapeNamespace("a/b_c.d-e"); got != "a_2fb_5fc.d-e" {
+		t.Errorf("escapeNamespace = %s", got)
+	}
+}
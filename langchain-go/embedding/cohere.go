@@ -1,18 +1,32 @@
 package embedding
 
 import (
+	"context"
 	"errors"
+	"net/http"
 	"os"
 	"strings"
+	"time"
 
+	"github.com/William-Bohm/langchain-go/langchain-go/embedding/embeddingSchema"
+	"github.com/avast/retry-go"
 	"github.com/cohere-ai/cohere-go"
 )
 
+// MaxCohereBatchSize is the most texts the embed endpoint accepts in one request.
+const MaxCohereBatchSize = 96
+
 type CohereEmbeddings struct {
-	Client       *cohere.Client
-	Model        string
-	Truncate     string
-	CohereAPIKey string
+	Client         *cohere.Client
+	Model          string
+	Truncate       string
+	CohereAPIKey   string
+	BatchSize      int                          `comment:"Texts per request, at most MaxCohereBatchSize."`
+	MaxConcurrency int                          `comment:"Requests in flight at once."`
+	MaxRetries     int                          `comment:"Attempts per request, for rate limits, server errors and failed connections."`
+	TokenLimiter   *embeddingSchema.RateLimiter `comment:"Tokens per minute quota, may be shared."`
+	RequestLimiter *embeddingSchema.RateLimiter `comment:"Requests per minute quota, may be shared."`
+	retryAfter     *embeddingSchema.RetryAfterTransport
 }
 
 func NewCohereEmbeddings(model string, cohereAPIKey string) (*CohereEmbeddings, error) {
@@ -27,22 +41,65 @@ func NewCohereEmbeddings(model string, cohereAPIKey string) (*CohereEmbeddings,
 	if err != nil {
 		return nil, err
 	}
+	// cohere-go errors carry the status code but not the Retry-After header, so the transport reads it
+	retryAfter := &embeddingSchema.RetryAfterTransport{Base: client.Client.Transport}
+	client.Client.Transport = retryAfter
 
 	return &CohereEmbeddings{
-		Client:       client,
-		Model:        model,
-		Truncate:     "",
-		CohereAPIKey: cohereAPIKey,
+		Client:         client,
+		Model:          model,
+		Truncate:       "",
+		CohereAPIKey:   cohereAPIKey,
+		BatchSize:      MaxCohereBatchSize,
+		MaxConcurrency: 4,
+		MaxRetries:     6,
+		retryAfter:     retryAfter,
 	}, nil
 }
 
 func (c *CohereEmbeddings) EmbedDocuments(texts []string) ([][]float64, error) {
-	embedOptions := cohere.EmbedOptions{
-		Model:    c.Model,
-		Texts:    texts,
-		Truncate: c.Truncate,
+	return c.EmbedDocumentsWithContext(context.Background(), texts)
+}
+
+// EmbedDocumentsWithContext sends texts in batches of BatchSize, MaxConcurrency at a time.
+func (c *CohereEmbeddings) EmbedDocumentsWithContext(ctx context.Context, texts []string) ([][]float64, error) {
+	batchSize := c.BatchSize
+	if batchSize <= 0 || batchSize > MaxCohereBatchSize {
+		batchSize = MaxCohereBatchSize
+	}
+	return embeddingSchema.EmbedBatches(ctx, texts, batchSize, c.MaxConcurrency, c.embed)
+}
+
+func (c *CohereEmbeddings) embed(ctx context.Context, texts []string) ([][]float64, error) {
+	attempts := c.MaxRetries
+	if attempts < 1 {
+		attempts = 1
 	}
-	embedResponse, err := c.Client.Embed(embedOptions)
+
+	var embedResponse *cohere.EmbedResponse
+	err := retry.Do(
+		func() error {
+			if err := c.RequestLimiter.Wait(ctx, 1); err != nil {
+				return retry.Unrecoverable(err)
+			}
+			if err := c.TokenLimiter.Wait(ctx, embeddingSchema.EstimateTokens(texts)); err != nil {
+				return retry.Unrecoverable(err)
+			}
+			var err error
+			embedResponse, err = c.Client.Embed(cohere.EmbedOptions{
+				Model:    c.Model,
+				Texts:    texts,
+				Truncate: c.Truncate,
+			})
+			return err
+		},
+		retry.RetryIf(retryableCohereError),
+		retry.DelayType(retryDelay(c.retryAfter)),
+		retry.Delay(4*time.Second),
+		retry.Attempts(uint(attempts)),
+		retry.Context(ctx),
+		retry.LastErrorOnly(true),
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -59,6 +116,16 @@ func (c *CohereEmbeddings) EmbedDocuments(texts []string) ([][]float64, error) {
 	return embeddings, nil
 }
 
+// retryableCohereError retries rate limits (429), server errors and failed connections, but not
+// requests the API rejected.
+func retryableCohereError(err error) bool {
+	var apiErr *cohere.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode == http.StatusTooManyRequests || apiErr.StatusCode >= 500
+	}
+	return true
+}
+
 func (c *CohereEmbeddings) EmbedQuery(text string) ([]float64, error) {
 	embeddings, err := c.EmbedDocuments([]string{text})
 	if err != nil {
@@ -0,0 +1,58 @@
+package embeddingSchema
+
+import (
+	"context"
+	"fmt"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// EmbedBatches splits texts into batches of at most batchSize and embeds up to maxConcurrency of
+// them at once with embed. Vectors are returned in the order of texts. A maxConcurrency of 0 or
+// less sends one batch at a time.
+func EmbedBatches(ctx context.Context, texts []string, batchSize int, maxConcurrency int, embed func(ctx context.Context, batch []string) ([][]float64, error)) ([][]float64, error) {
+	if batchSize <= 0 {
+		batchSize = len(texts)
+	}
+	if maxConcurrency <= 0 {
+		maxConcurrency = 1
+	}
+	results := make([][]float64, len(texts))
+	if len(texts) == 0 {
+		return results, nil
+	}
+
+	g, ctx := errgroup.WithContext(ctx)
+	g.SetLimit(maxConcurrency)
+	for start := 0; start < len(texts); start += batchSize {
+		start, end := start, start+batchSize
+		if end > len(texts) {
+			end = len(texts)
+		}
+		g.Go(func() error {
+			vectors, err := embed(ctx, texts[start:end])
+			if err != nil {
+				return err
+			}
+			if len(vectors) != end-start {
+				return fmt.Errorf("embeddings returned %d vectors for %d texts", len(vectors), end-start)
+			}
+			copy(results[start:end], vectors)
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// EstimateTokens approximates the token count of texts at four characters per token, which is
+// close enough for rate limiting.
+func EstimateTokens(texts []string) int {
+	tokens := 0
+	for _, text := range texts {
+		tokens += len(text)/4 + 1
+	}
+	return tokens
+}
@@ -3,11 +3,12 @@ package embeddingSchema
 import (
 	"bytes"
 	"context"
-	"errors"
-	"github.com/avast/retry-go"
 	"io/ioutil"
 	"net/http"
 	"sync"
+	"time"
+
+	"github.com/William-Bohm/langchain-go/langchain-go/util/requests"
 )
 
 type EmbeddingClient interface {
@@ -30,8 +31,13 @@ type BaseEmbeddingClient struct {
 	client          *http.Client
 	clientMutex     sync.Mutex
 	ResponsePayload ResponsePayload // the responses payload type
+	// RequestLimiter, if set, is waited on for one token before every request
+	RequestLimiter *RateLimiter
 }
 
+// Create posts requestPayload and decodes the response. Network errors, 429 and 5xx responses are
+// retried up to MaxRetries times, see requests.DoWithRetries. Every attempt first takes a token from
+// RequestLimiter.
 // TODO: make each custom openaiClient implement the request object to handle specific authorization logic
 func (c *BaseEmbeddingClient) Create(ctx context.Context, requestPayload RequestPayload) (ResponsePayload, error) {
 	jsonData, err := requestPayload.ToJSON()
@@ -39,34 +45,22 @@ func (c *BaseEmbeddingClient) Create(ctx context.Context, requestPayload Request
 		return nil, err
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.APIBaseURL, bytes.NewBuffer(jsonData))
-	if err != nil {
-		return nil, err
-	}
-
-	c.AddHeaders(req)
-
-	var resp *http.Response
-	err = retry.Do(
-		func() error {
-			resp, err = c.getClient().Do(req)
-			if err != nil {
-				return err
-			}
-			return nil
-		},
-		retry.Attempts(uint(c.MaxRetries)),
-		retry.Context(ctx),
-	)
+	resp, err := requests.DoWithRetries(ctx, c.getClient(), c.MaxRetries, func() (*http.Request, error) {
+		if err := c.RequestLimiter.Wait(ctx, 1); err != nil {
+			return nil, err
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.APIBaseURL, bytes.NewReader(jsonData))
+		if err != nil {
+			return nil, err
+		}
+		c.AddHeaders(req)
+		return req, nil
+	})
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, errors.New("Request failed with status: " + resp.Status)
-	}
-
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
 		return nil, err
@@ -81,6 +75,48 @@ func (c *BaseEmbeddingClient) Create(ctx context.Context, requestPayload Request
 	return response, nil
 }
 
+// RetryAfterTransport is an http.RoundTripper that remembers until when the server asked clients to
+// wait with its latest 429 or 5xx response, for SDKs whose errors do not expose response headers.
+// The wait applies to every request sharing the transport, as a rate limit does to the whole key.
+type RetryAfterTransport struct {
+	Base  http.RoundTripper // nil uses http.DefaultTransport
+	mu    sync.Mutex
+	until time.Time
+}
+
+func (t *RetryAfterTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	resp, err := base.RoundTrip(req)
+	if err != nil || (resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode < 500) {
+		return resp, err
+	}
+	if wait, ok := requests.RetryAfter(resp); ok {
+		until := time.Now().Add(wait)
+		t.mu.Lock()
+		if until.After(t.until) {
+			t.until = until
+		}
+		t.mu.Unlock()
+	}
+	return resp, nil
+}
+
+// Wait returns how much is left of the wait the server last asked for. A nil transport never waits.
+func (t *RetryAfterTransport) Wait() time.Duration {
+	if t == nil {
+		return 0
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if wait := time.Until(t.until); wait > 0 {
+		return wait
+	}
+	return 0
+}
+
 func (c *BaseEmbeddingClient) AddHeaders(req *http.Request) {
 	// This method can be overridden by child structs to add custom headers
 }
@@ -103,3 +139,10 @@ func (c *BaseEmbeddingClient) getClient() *http.Client {
 
 	return c.client
 }
+
+// SetHTTPClient replaces the http.Client requests are sent with, e.g. to set a timeout or transport.
+func (c *BaseEmbeddingClient) SetHTTPClient(client *http.Client) {
+	c.clientMutex.Lock()
+	defer c.clientMutex.Unlock()
+	c.client = client
+}
@@ -0,0 +1,155 @@
+package embeddingSchema
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type testRequest struct {
+	Input string `json:"input"`
+}
+
+func (r testRequest) ToJSON() ([]byte, error) {
+	return json.Marshal(r)
+}
+
+type testResponse struct {
+	Embedding []float64 `json:"embedding"`
+}
+
+func (r *testResponse) FromJSON(data []byte) (ResponsePayload, error) {
+	response := &testResponse{}
+	return response, json.Unmarshal(data, response)
+}
+
+func (r *testResponse) NewResponsePayload() ResponsePayload {
+	return &testResponse{}
+}
+
+// newTestEmbeddingClient answers with the given statuses in turn, each with its Retry-After header,
+// then with an embedding. It counts the requests that arrived with the whole body.
+func newTestEmbeddingClient(t *testing.T, maxRetries int, retryAfter string, statuses ...int) (*BaseEmbeddingClient, *int32) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		if string(body) != `{"input":"hi"}` {
+			t.Errorf("got body %q", body)
+		}
+		n := atomic.AddInt32(&requests, 1)
+		if int(n) <= len(statuses) {
+			w.Header().Set("Retry-After", retryAfter)
+			w.WriteHeader(statuses[n-1])
+			return
+		}
+		w.Write([]byte(`{"embedding": [0.5, 1]}`))
+	}))
+	t.Cleanup(server.Close)
+	return NewBaseAIClient(server.URL, maxRetries, &testResponse{}), &requests
+}
+
+func TestBaseEmbeddingClientCreate(t *testing.T) {
+	tests := []struct {
+		name       string
+		maxRetries int
+		statuses   []int
+		wantErr    bool
+		requests   int32
+	}{
+		{"ok", 2, nil, false, 1},
+		{"rate limited then ok", 2, []int{http.StatusTooManyRequests}, false, 2},
+		{"server errors up to max retries", 2, []int{http.StatusInternalServerError, http.StatusBadGateway}, false, 3},
+		{"server errors past max retries", 2, []int{500, 500, 500}, true, 3},
+		{"no retries", 0, []int{http.StatusTooManyRequests}, true, 1},
+		{"bad request is not retried", 2, []int{http.StatusBadRequest}, true, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, requests := newTestEmbeddingClient(t, tt.maxRetries, "0", tt.statuses...)
+			response, err := client.Create(context.Background(), testRequest{Input: "hi"})
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("got %+v, want an error", response)
+				}
+			} else if err != nil {
+				t.Fatal(err)
+			} else if embedding := response.(*testResponse).Embedding; len(embedding) != 2 || embedding[1] != 1 {
+				t.Errorf("got %+v", response)
+			}
+			if *requests != tt.requests {
+				t.Errorf("sent %d requests, want %d", *requests, tt.requests)
+			}
+		})
+	}
+}
+
+func TestBaseEmbeddingClientCreateCancelled(t *testing.T) {
+	client, requests := newTestEmbeddingClient(t, 2, "60", http.StatusTooManyRequests)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := client.Create(ctx, testRequest{Input: "hi"})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got %v, want the context's error", err)
+	}
+	if waited := time.Since(start); waited > 5*time.Second {
+		t.Errorf("returned after %v, want the Retry-After wait cut short", waited)
+	}
+	if *requests != 1 {
+		t.Errorf("sent %d requests, want 1", *requests)
+	}
+}
+
+func TestRetryAfterTransport(t *testing.T) {
+	var status int
+	var retryAfter string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if retryAfter != "" {
+			w.Header().Set("Retry-After", retryAfter)
+		}
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	transport := &RetryAfterTransport{}
+	client := &http.Client{Transport: transport}
+	get := func(s int, header string) {
+		t.Helper()
+		status, retryAfter = s, header
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+
+	get(http.StatusOK, "")
+	if wait := transport.Wait(); wait != 0 {
+		t.Fatalf("waiting %v after a success", wait)
+	}
+	get(http.StatusBadRequest, "30")
+	if wait := transport.Wait(); wait != 0 {
+		t.Fatalf("waiting %v after a 400, which is not retried", wait)
+	}
+	get(http.StatusTooManyRequests, "20")
+	if wait := transport.Wait(); wait <= 19*time.Second || wait > 20*time.Second {
+		t.Fatalf("waiting %v after Retry-After: 20", wait)
+	}
+	// a shorter wait asked for later does not cut the longer one short
+	get(http.StatusServiceUnavailable, "1")
+	if wait := transport.Wait(); wait <= 19*time.Second {
+		t.Fatalf("waiting %v, want the earlier 20s to hold", wait)
+	}
+
+	var none *RetryAfterTransport
+	if none.Wait() != 0 {
+		t.Fatal("a nil transport waits")
+	}
+}
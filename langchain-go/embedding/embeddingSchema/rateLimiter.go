@@ -0,0 +1,65 @@
+package embeddingSchema
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RateLimiter is a token bucket holding up to one minute's worth of tokens, refilled continuously.
+// One limiter can be shared by every goroutine, and every embeddings client, drawing on the same
+// quota, e.g. one for tokens per minute and one for requests per minute.
+type RateLimiter struct {
+	PerMinute float64
+	mu        sync.Mutex
+	available float64
+	last      time.Time
+	now       func() time.Time
+}
+
+func NewRateLimiter(perMinute int) *RateLimiter {
+	return &RateLimiter{
+		PerMinute: float64(perMinute),
+		available: float64(perMinute),
+		last:      time.Now(),
+		now:       time.Now,
+	}
+}
+
+// Wait blocks until n tokens are available and takes them, or returns ctx's error. A request for
+// more than the bucket holds waits for a full bucket and leaves it in debt, so it never blocks forever.
+func (l *RateLimiter) Wait(ctx context.Context, n int) error {
+	if l == nil || l.PerMinute <= 0 {
+		return nil
+	}
+	for {
+		l.mu.Lock()
+		now := l.now()
+		perSecond := l.PerMinute / 60
+		l.available += now.Sub(l.last).Seconds() * perSecond
+		if l.available > l.PerMinute {
+			l.available = l.PerMinute
+		}
+		l.last = now
+
+		need := float64(n)
+		if need > l.PerMinute {
+			need = l.PerMinute
+		}
+		if l.available >= need {
+			l.available -= float64(n)
+			l.mu.Unlock()
+			return nil
+		}
+		wait := time.Duration((need - l.available) / perSecond * float64(time.Second))
+		l.mu.Unlock()
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
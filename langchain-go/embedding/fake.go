@@ -2,6 +2,7 @@ package embedding
 
 import (
 	"math/rand"
+	"strings"
 )
 
 type FakeEmbeddings struct {
@@ -31,3 +32,25 @@ func (f *FakeEmbeddings) EmbedDocuments(texts []string) [][]float64 {
 func (f *FakeEmbeddings) EmbedQuery(text string) []float64 {
 	return f.getEmbedding()
 }
+
+// LetterEmbeddings embeds a text as its counts of the letters a to z, so texts sharing letters are
+// similar and every run gets the same vectors. Tests use it where similarities must be predictable.
+type LetterEmbeddings struct{}
+
+func (LetterEmbeddings) EmbedDocuments(texts []string) ([][]float64, error) {
+	vectors := make([][]float64, len(texts))
+	for i, text := range texts {
+		vectors[i], _ = LetterEmbeddings{}.EmbedQuery(text)
+	}
+	return vectors, nil
+}
+
+func (LetterEmbeddings) EmbedQuery(text string) ([]float64, error) {
+	vector := make([]float64, 26)
+	for _, r := range strings.ToLower(text) {
+		if r >= 'a' && r <= 'z' {
+			vector[r-'a']++
+		}
+	}
+	return vector, nil
+}
@@ -0,0 +1,210 @@
+package embedding
+
+import (
+	"errors"
+	"hash/fnv"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/William-Bohm/langchain-go/langchain-go/tools/token"
+)
+
+const DefaultHashEmbeddingSize = 256
+
+// HashEmbeddings embeds texts offline and deterministically by feature hashing: every word n-gram
+// and every character n-gram of each word is hashed into one of Size dimensions, with a hashed
+// sign so collisions tend to cancel out. Texts sharing words or word parts get similar vectors, so
+// tests can assert on rankings. Vectors are normalised to unit length.
+//
+// Term counts are weighted by inverse document frequency once Fit has seen a corpus.
+type HashEmbeddings struct {
+	Size          int                        `comment:"Number of dimensions."`
+	WordNGrams    int                        `comment:"Word n-grams from 1 up to this length are used. 0 uses no word features."`
+	MinCharNGram  int                        `comment:"Shortest character n-gram taken from each word."`
+	MaxCharNGram  int                        `comment:"Longest character n-gram taken from each word. 0 uses no character features."`
+	CharNGramRate float64                    `comment:"Weight of a character n-gram relative to a word n-gram."`
+	Tokenizer     func(text string) []string `comment:"Splits text into words."`
+	mu            sync.RWMutex
+	docFreqs      map[string]int
+	docCount      int
+	corpus        []string
+}
+
+func NewHashEmbeddings(options ...HashEmbeddingsOption) (*HashEmbeddings, error) {
+	h := &HashEmbeddings{
+		Size:          DefaultHashEmbeddingSize,
+		WordNGrams:    2,
+		MinCharNGram:  3,
+		MaxCharNGram:  5,
+		CharNGramRate: 0.5,
+		Tokenizer:     token.Words,
+	}
+	for _, option := range options {
+		if err := option(h); err != nil {
+			return nil, err
+		}
+	}
+	// fitted last, so the corpus is tokenized the way the options say
+	if h.corpus != nil {
+		h.Fit(h.corpus)
+		h.corpus = nil
+	}
+	return h, nil
+}
+
+// features counts the n-grams of text with their weights. Word n-grams and character n-grams are
+// prefixed differently so "ab" the word never shares a feature with "ab" inside a word.
+func (h *HashEmbeddings) features(text string) map[string]float64 {
+	words := h.Tokenizer(text)
+	features := map[string]float64{}
+	for n := 1; n <= h.WordNGrams; n++ {
+		for i := 0; i+n <= len(words); i++ {
+			features["w:"+strings.Join(words[i:i+n], " ")]++
+		}
+	}
+	if h.MaxCharNGram <= 0 {
+		return features
+	}
+	for _, word := range words {
+		// word boundaries count, so prefixes and suffixes are told apart
+		runes := []rune("<" + word + ">")
+		for n := h.MinCharNGram; n <= h.MaxCharNGram; n++ {
+			for i := 0; i+n <= len(runes); i++ {
+				features["c:"+string(runes[i:i+n])] += h.CharNGramRate
+			}
+		}
+	}
+	return features
+}
+
+// Fit counts in how many of texts every feature occurs, after which EmbedDocuments and EmbedQuery
+// weight features by inverse document frequency. Each call replaces what earlier calls counted.
+func (h *HashEmbeddings) Fit(texts []string) {
+	docFreqs := map[string]int{}
+	for _, text := range texts {
+		for feature := range h.features(text) {
+			docFreqs[feature]++
+		}
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.docFreqs = docFreqs
+	h.docCount = len(texts)
+}
+
+func (h *HashEmbeddings) embed(text string) []float64 {
+	vector := make([]float64, h.Size)
+	features := h.features(text)
+	// summed in a fixed order, so vectors are equal bit for bit across runs
+	names := make([]string, 0, len(features))
+	for feature := range features {
+		names = append(names, feature)
+	}
+	sort.Strings(names)
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for _, feature := range names {
+		weight := features[feature]
+		if h.docCount > 0 {
+			// smoothed like scikit-learn, so features missing from the corpus get the highest weight
+			weight *= math.Log(float64(1+h.docCount)/float64(1+h.docFreqs[feature])) + 1
+		}
+		hasher := fnv.New64a()
+		hasher.Write([]byte(feature))
+		sum := hasher.Sum64()
+		if sum>>63 == 1 {
+			weight = -weight
+		}
+		vector[(sum&math.MaxInt64)%uint64(h.Size)] += weight
+	}
+
+	norm := 0.0
+	for _, v := range vector {
+		norm += v * v
+	}
+	// texts without any features, e.g. empty ones, stay the zero vector
+	if norm > 0 {
+		norm = math.Sqrt(norm)
+		for i := range vector {
+			vector[i] /= norm
+		}
+	}
+	return vector
+}
+
+func (h *HashEmbeddings) EmbedDocuments(texts []string) ([][]float64, error) {
+	embeddings := make([][]float64, len(texts))
+	for i, text := range texts {
+		embeddings[i] = h.embed(text)
+	}
+	return embeddings, nil
+}
+
+func (h *HashEmbeddings) EmbedQuery(text string) ([]float64, error) {
+	return h.embed(text), nil
+}
+
+type HashEmbeddingsOption func(*HashEmbeddings) error
+
+func HashEmbeddingSize(size int) HashEmbeddingsOption {
+	return func(h *HashEmbeddings) error {
+		if size <= 0 {
+			return errors.New("size must be positive")
+		}
+		h.Size = size
+		return nil
+	}
+}
+
+func WordNGrams(n int) HashEmbeddingsOption {
+	return func(h *HashEmbeddings) error {
+		if n < 0 {
+			return errors.New("word n-gram length can not be negative")
+		}
+		h.WordNGrams = n
+		return nil
+	}
+}
+
+// CharNGrams sets the range of character n-gram lengths; 0, 0 turns character features off.
+func CharNGrams(min, max int) HashEmbeddingsOption {
+	return func(h *HashEmbeddings) error {
+		if max != 0 && (min <= 0 || min > max) {
+			return errors.New("character n-gram lengths must be positive and min at most max")
+		}
+		h.MinCharNGram = min
+		h.MaxCharNGram = max
+		return nil
+	}
+}
+
+func CharNGramRate(rate float64) HashEmbeddingsOption {
+	return func(h *HashEmbeddings) error {
+		if rate < 0 {
+			return errors.New("character n-gram rate can not be negative")
+		}
+		h.CharNGramRate = rate
+		return nil
+	}
+}
+
+func HashTokenizer(tokenizer func(text string) []string) HashEmbeddingsOption {
+	return func(h *HashEmbeddings) error {
+		if tokenizer == nil {
+			return errors.New("tokenizer can not be nil")
+		}
+		h.Tokenizer = tokenizer
+		return nil
+	}
+}
+
+// TFIDF fits inverse document frequencies on corpus, see Fit.
+func TFIDF(corpus []string) HashEmbeddingsOption {
+	return func(h *HashEmbeddings) error {
+		h.corpus = corpus
+		return nil
+	}
+}
@@ -0,0 +1,111 @@
+package embedding
+
+import (
+	"math"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func dot(a, b []float64) float64 {
+	sum := 0.0
+	for i := range a {
+		sum += a[i] * b[i]
+	}
+	return sum
+}
+
+func TestHashEmbeddingsDeterministic(t *testing.T) {
+	texts := []string{"The quick brown fox", "jumps over the lazy dog", ""}
+	first, err := NewHashEmbeddings()
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := NewHashEmbeddings()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want, _ := first.EmbedDocuments(texts)
+	again, _ := first.EmbedDocuments(texts)
+	other, _ := second.EmbedDocuments(texts)
+	if !reflect.DeepEqual(again, want) || !reflect.DeepEqual(other, want) {
+		t.Fatal("the same texts got different vectors")
+	}
+	query, _ := first.EmbedQuery(texts[0])
+	if !reflect.DeepEqual(query, want[0]) {
+		t.Fatal("a query got a different vector than the same document")
+	}
+
+	for i, vector := range want[:2] {
+		if len(vector) != DefaultHashEmbeddingSize {
+			t.Fatalf("got %d dimensions, want %d", len(vector), DefaultHashEmbeddingSize)
+		}
+		if norm := math.Sqrt(dot(vector, vector)); math.Abs(norm-1) > 1e-12 {
+			t.Errorf("vector of %q has length %f, want 1", texts[i], norm)
+		}
+	}
+	if dot(want[2], want[2]) != 0 {
+		t.Error("the empty text is not the zero vector")
+	}
+}
+
+func TestHashEmbeddingsRanking(t *testing.T) {
+	texts := []string{
+		"the cat sat on the mat",
+		"a dog barked at the mailman",
+		"stock prices fell sharply today",
+		"cats sitting on mats",
+	}
+	tests := []struct {
+		name    string
+		options []HashEmbeddingsOption
+		query   string
+		want    []int
+	}{
+		{"shared words", nil, "the cat on the mat", []int{0}},
+		{"shared word parts", []HashEmbeddingsOption{WordNGrams(0)}, "sitting cats", []int{3, 0}},
+		{"idf favours rare words", []HashEmbeddingsOption{TFIDF(texts)}, "the stock", []int{2}},
+		{"words only", []HashEmbeddingsOption{CharNGrams(0, 0)}, "barked", []int{1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, err := NewHashEmbeddings(tt.options...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			vectors, _ := h.EmbedDocuments(texts)
+			query, _ := h.EmbedQuery(tt.query)
+			order := []int{0, 1, 2, 3}
+			sort.SliceStable(order, func(i, j int) bool {
+				return dot(query, vectors[order[i]]) > dot(query, vectors[order[j]])
+			})
+			if !reflect.DeepEqual(order[:len(tt.want)], tt.want) {
+				t.Errorf("ranked %v, want %v first", order, tt.want)
+			}
+		})
+	}
+}
+
+func TestHashEmbeddingsOptions(t *testing.T) {
+	tests := map[string]HashEmbeddingsOption{
+		"zero size":           HashEmbeddingSize(0),
+		"negative word ngram": WordNGrams(-1),
+		"min above max":       CharNGrams(4, 2),
+		"negative rate":       CharNGramRate(-1),
+		"nil tokenizer":       HashTokenizer(nil),
+	}
+	for name, option := range tests {
+		if _, err := NewHashEmbeddings(option); err == nil {
+			t.Errorf("%s: got no error", name)
+		}
+	}
+
+	h, err := NewHashEmbeddings(HashEmbeddingSize(8))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if vector, _ := h.EmbedQuery("cat"); len(vector) != 8 {
+		t.Fatalf("got %d dimensions, want 8", len(vector))
+	}
+}
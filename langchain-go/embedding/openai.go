@@ -4,17 +4,22 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"github.com/William-Bohm/langchain-go/langchain-go/embedding/embeddingSchema"
 	"github.com/avast/retry-go"
+	"github.com/tiktoken-go/tokenizer"
 	"log"
-	"os"
+	"net/http"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	openai "github.com/sashabaranov/go-openai"
-	"golang.org/x/sync/errgroup"
 	"gonum.org/v1/gonum/mat"
 )
 
+// MaxOpenAIBatchSize is the most inputs the embeddings endpoint accepts in one request.
+const MaxOpenAIBatchSize = 2048
+
 var stringToModel = map[string]openai.EmbeddingModel{
 	"text-similarity-ada-001":       openai.AdaSimilarity,
 	"text-similarity-babbage-001":   openai.BabbageSimilarity,
@@ -38,13 +43,17 @@ var stringToModel = map[string]openai.EmbeddingModel{
 type OpenAIEmbeddingsConfig struct {
 	Model              string
 	Deployment         string
-	EmbeddingCtxLength int
+	EmbeddingCtxLength int  // most tokens the model embeds at once
+	SplitLongTexts     bool // embed texts over EmbeddingCtxLength tokens in pieces instead of sending them whole
 	OpenAIKey          string
 	OpenAIOrganization string
 	AllowedSpecial     map[string]struct{}
 	DisallowedSpecial  map[string]struct{}
-	ChunkSize          int
+	ChunkSize          int // texts per request, at most MaxOpenAIBatchSize
 	MaxRetries         int
+	MaxConcurrency     int                          // requests in flight at once
+	TokenLimiter       *embeddingSchema.RateLimiter // tokens per minute quota, may be shared
+	RequestLimiter     *embeddingSchema.RateLimiter // requests per minute quota, may be shared
 }
 
 func NewOpenAIEmbeddingsConfig() *OpenAIEmbeddingsConfig {
@@ -58,12 +67,14 @@ func NewOpenAIEmbeddingsConfig() *OpenAIEmbeddingsConfig {
 		DisallowedSpecial:  map[string]struct{}{"all": {}},
 		ChunkSize:          1000,
 		MaxRetries:         6,
+		MaxConcurrency:     4,
 	}
 }
 
 type OpenAIEmbeddings struct {
-	Client *openai.Client
-	Config *OpenAIEmbeddingsConfig
+	Client     *openai.Client
+	Config     *OpenAIEmbeddingsConfig
+	retryAfter *embeddingSchema.RetryAfterTransport
 }
 
 func NewOpenAIEmbeddings(config *OpenAIEmbeddingsConfig) (*OpenAIEmbeddings, error) {
@@ -71,72 +82,180 @@ func NewOpenAIEmbeddings(config *OpenAIEmbeddingsConfig) (*OpenAIEmbeddings, err
 		return nil, errors.New("OPENAI_API_KEY must be provided")
 	}
 
-	client := openai.NewClient(config.OpenAIKey)
+	// go-openai errors carry the status code but not the Retry-After header, so the transport reads it
+	clientConfig := openai.DefaultConfig(config.OpenAIKey)
+	retryAfter := &embeddingSchema.RetryAfterTransport{Base: clientConfig.HTTPClient.Transport}
+	clientConfig.HTTPClient = &http.Client{Transport: retryAfter}
 
 	return &OpenAIEmbeddings{
-		Client: client,
-		Config: config,
+		Client:     openai.NewClientWithConfig(clientConfig),
+		Config:     config,
+		retryAfter: retryAfter,
 	}, nil
 }
 
-func (oe *OpenAIEmbeddings) embedDocuments(texts []string) ([][]float64, error) {
-	// TODO: Implement _get_len_safe_embeddings for large input text
-	results := make([][]float64, 0, len(texts))
+func (oe *OpenAIEmbeddings) EmbedDocuments(texts []string) ([][]float64, error) {
+	return oe.EmbedDocumentsWithContext(context.Background(), texts)
+}
 
-	g, ctx := errgroup.WithContext(context.Background())
+// EmbedDocumentsWithContext sends texts in batches of ChunkSize, MaxConcurrency at a time.
+// With SplitLongTexts set, see embedLenSafe.
+func (oe *OpenAIEmbeddings) EmbedDocumentsWithContext(ctx context.Context, texts []string) ([][]float64, error) {
+	if oe.Config.SplitLongTexts {
+		return oe.embedLenSafe(ctx, texts)
+	}
+	return oe.embedBatches(ctx, texts)
+}
 
-	for i := 0; i < len(texts); i += oe.Config.ChunkSize {
-		end := i + oe.Config.ChunkSize
-		if end > len(texts) {
-			end = len(texts)
+func (oe *OpenAIEmbeddings) embedBatches(ctx context.Context, texts []string) ([][]float64, error) {
+	batchSize := oe.Config.ChunkSize
+	if batchSize <= 0 || batchSize > MaxOpenAIBatchSize {
+		batchSize = MaxOpenAIBatchSize
+	}
+	return embeddingSchema.EmbedBatches(ctx, texts, batchSize, oe.Config.MaxConcurrency, oe.embedWithRetry)
+}
+
+func (oe *OpenAIEmbeddings) EmbedQuery(text string) ([]float64, error) {
+	if oe.Config.SplitLongTexts {
+		embeddings, err := oe.embedLenSafe(context.Background(), []string{text})
+		if err != nil {
+			return nil, err
 		}
+		return embeddings[0], nil
+	}
+
+	embedding, err := oe.embedWithRetry(context.Background(), []string{text})
+	if err != nil {
+		return nil, err
+	}
 
-		textBatch := texts[i:end]
+	return embedding[0], nil
+}
+
+// embedLenSafe is the port of _get_len_safe_embeddings. Every text is cut into pieces of at most
+// EmbeddingCtxLength tokens, all pieces are embedded together, and each text gets the average of
+// its pieces' vectors, weighted by their token counts and normalised to unit length.
+func (oe *OpenAIEmbeddings) embedLenSafe(ctx context.Context, texts []string) ([][]float64, error) {
+	ctxLength := oe.Config.EmbeddingCtxLength
+	if ctxLength <= 0 {
+		return nil, errors.New("EmbeddingCtxLength must be positive to split long texts")
+	}
+	encoding, err := encodingForModel(oe.Config.Model)
+	if err != nil {
+		return nil, err
+	}
 
-		g.Go(func() error {
-			embeddings, err := oe.embedWithRetry(ctx, textBatch)
+	var pieces []string
+	var owners []int
+	var weights []float64
+	for i, text := range texts {
+		text = strings.ReplaceAll(text, "\n", " ")
+		tokens, _, err := encoding.Encode(text)
+		if err != nil {
+			return nil, err
+		}
+		if len(tokens) == 0 {
+			pieces, owners, weights = append(pieces, text), append(owners, i), append(weights, 1)
+			continue
+		}
+		for start := 0; start < len(tokens); {
+			end, piece, err := decodePiece(encoding, tokens, start, ctxLength)
 			if err != nil {
-				return err
+				return nil, err
 			}
-
-			results = append(results, embeddings...)
-			return nil
-		})
+			pieces, owners, weights = append(pieces, piece), append(owners, i), append(weights, float64(end-start))
+			start = end
+		}
 	}
 
-	if err := g.Wait(); err != nil {
+	vectors, err := oe.embedBatches(ctx, pieces)
+	if err != nil {
 		return nil, err
 	}
 
-	return results, nil
+	sums := make([][]float64, len(texts))
+	for j, vector := range vectors {
+		i := owners[j]
+		if sums[i] == nil {
+			sums[i] = make([]float64, len(vector))
+		}
+		if len(vector) != len(sums[i]) {
+			return nil, fmt.Errorf("pieces of text %d embedded to %d and %d dimensions", i, len(sums[i]), len(vector))
+		}
+		for k, v := range vector {
+			sums[i][k] += v * weights[j]
+		}
+	}
+	// dividing by the total weight is left out, normalising scales it away anyway
+	for i := range sums {
+		sums[i] = normalizeVector(sums[i])
+	}
+	return sums, nil
 }
 
-func (oe *OpenAIEmbeddings) embedQuery(text string) ([]float64, error) {
-	embedding, err := oe.embedWithRetry(context.Background(), []string{text})
-	if err != nil {
-		return nil, err
+// encodingForModel returns the tokenizer of an embedding model. The first generation models
+// tiktoken does not list, the *-query-001 and code-search-*-text-001 halves of the search models,
+// share r50k_base with the ones it does; newer unknown models are assumed to use cl100k_base.
+func encodingForModel(model string) (tokenizer.Codec, error) {
+	if encoding, err := tokenizer.ForModel(tokenizer.Model(model)); err == nil {
+		return encoding, nil
 	}
+	if strings.HasSuffix(model, "-001") {
+		return tokenizer.Get(tokenizer.R50kBase)
+	}
+	return tokenizer.Get(tokenizer.Cl100kBase)
+}
 
-	return embedding[0], nil
+// decodePiece decodes up to maxTokens tokens from start and returns where the piece ends. Since the
+// API takes strings rather than tokens, the end is moved back a few tokens if it would cut a
+// multi-byte character in two, which always succeeds for maxTokens of at least utf8.UTFMax.
+func decodePiece(encoding tokenizer.Codec, tokens []uint, start int, maxTokens int) (int, string, error) {
+	end := start + maxTokens
+	if end > len(tokens) {
+		end = len(tokens)
+	}
+	for cut := end; cut > start && cut > end-utf8.UTFMax; cut-- {
+		piece, err := encoding.Decode(tokens[start:cut])
+		if err != nil {
+			return 0, "", err
+		}
+		if utf8.ValidString(piece) {
+			return cut, piece, nil
+		}
+	}
+	piece, err := encoding.Decode(tokens[start:end])
+	return end, piece, err
 }
 
 func (oe *OpenAIEmbeddings) embedWithRetry(ctx context.Context, texts []string) ([][]float64, error) {
 	var embeddings [][]float64
 	var err error
 
-	backOff := []time.Duration{4 * time.Second, 8 * time.Second, 10 * time.Second}
+	attempts := oe.Config.MaxRetries
+	if attempts < 1 {
+		attempts = 1
+	}
 
 	err = retry.Do(
 		func() error {
+			if err := oe.Config.RequestLimiter.Wait(ctx, 1); err != nil {
+				return retry.Unrecoverable(err)
+			}
+			if err := oe.Config.TokenLimiter.Wait(ctx, embeddingSchema.EstimateTokens(texts)); err != nil {
+				return retry.Unrecoverable(err)
+			}
 			embeddings, err = oe.embed(ctx, texts)
 			if err != nil {
 				log.Println("embedWithRetry failed:", err)
 			}
 			return err
 		},
-		retry.DelayType(retry.BackOffDelay),
+		retry.RetryIf(retryableOpenAIError),
+		retry.DelayType(retryDelay(oe.retryAfter)),
 		retry.Delay(4*time.Second),
-		retry.Attempts(uint(len(backOff))),
+		retry.Attempts(uint(attempts)),
+		retry.Context(ctx),
+		retry.LastErrorOnly(true),
 	)
 
 	if err != nil {
@@ -146,6 +265,38 @@ func (oe *OpenAIEmbeddings) embedWithRetry(ctx context.Context, texts []string)
 	return embeddings, nil
 }
 
+// maxRetryBackOff caps the exponential back-off between retries. A longer Retry-After is still honoured.
+const maxRetryBackOff = time.Minute
+
+// retryDelay backs off exponentially, or waits as long as the server asked with Retry-After if that
+// is longer.
+func retryDelay(retryAfter *embeddingSchema.RetryAfterTransport) retry.DelayTypeFunc {
+	return func(n uint, err error, config *retry.Config) time.Duration {
+		delay := retry.BackOffDelay(n, err, config)
+		if delay > maxRetryBackOff {
+			delay = maxRetryBackOff
+		}
+		if wait := retryAfter.Wait(); wait > delay {
+			return wait
+		}
+		return delay
+	}
+}
+
+// retryableOpenAIError retries rate limits (429), server errors and failed connections, but not
+// requests the API rejected, e.g. for being too long.
+func retryableOpenAIError(err error) bool {
+	var apiErr *openai.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.HTTPStatusCode == http.StatusTooManyRequests || apiErr.HTTPStatusCode >= 500
+	}
+	var reqErr *openai.RequestError
+	if errors.As(err, &reqErr) {
+		return reqErr.HTTPStatusCode == http.StatusTooManyRequests || reqErr.HTTPStatusCode >= 500
+	}
+	return true
+}
+
 func (oe *OpenAIEmbeddings) embed(ctx context.Context, texts []string) ([][]float64, error) {
 	cleanedTexts := make([]string, len(texts))
 	for i, text := range texts {
@@ -162,14 +313,20 @@ func (oe *OpenAIEmbeddings) embed(ctx context.Context, texts []string) ([][]floa
 		return nil, err
 	}
 
+	if len(resp.Data) != len(texts) {
+		return nil, fmt.Errorf("openai returned %d embeddings for %d texts", len(resp.Data), len(texts))
+	}
 	embeddings := make([][]float64, len(resp.Data))
 	for _, datum := range resp.Data {
+		if datum.Index < 0 || datum.Index >= len(embeddings) {
+			return nil, fmt.Errorf("openai returned embedding index %d for %d texts", datum.Index, len(texts))
+		}
 		// Convert the []float32 value to []float64
 		embedding := make([]float64, len(datum.Embedding))
 		for i, v := range datum.Embedding {
 			embedding[i] = float64(v)
 		}
-		embeddings = append(embeddings, embedding)
+		embeddings[datum.Index] = embedding
 	}
 
 	return embeddings, nil
@@ -182,37 +339,3 @@ func normalizeVector(vec []float64) []float64 {
 	}
 	return vec
 }
-
-func main() {
-	config := NewOpenAIEmbeddingsConfig()
-	config.OpenAIKey = os.Getenv("OPENAI_API_KEY")
-
-	oe, err := NewOpenAIEmbeddings(config)
-	if err != nil {
-		log.Fatalf("Failed to initialize OpenAIEmbeddings: %v", err)
-	}
-
-	texts := []string{
-		"First test sentence.",
-		"Second test sentence.",
-	}
-
-	embeddings, err := oe.embedDocuments(texts)
-	if err != nil {
-		log.Fatalf("Failed to embed documents: %v", err)
-	}
-
-	fmt.Println("Embeddings:")
-	for i, embedding := range embeddings {
-		fmt.Printf("%d: %v\n", i, normalizeVector(embedding))
-	}
-
-	query := "Test query sentence."
-	queryEmbedding, err := oe.embedQuery(query)
-	if err != nil {
-		log.Fatalf("Failed to embed query: %v", err)
-	}
-
-	fmt.Println("Query Embedding:")
-	fmt.Println(normalizeVector(queryEmbedding))
-}
@@ -0,0 +1,173 @@
+package embedding
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"unicode/utf8"
+
+	openai "github.com/sashabaranov/go-openai"
+)
+
+// pieceVector is the embedding the test server returns for an input.
+func pieceVector(piece string) []float64 {
+	return []float64{float64(len(piece)), float64(strings.Count(piece, " ")), 1}
+}
+
+// newTestOpenAIEmbeddings embeds with an httptest server answering pieceVector for every input, and
+// returns the inputs the server got.
+func newTestOpenAIEmbeddings(t *testing.T, config *OpenAIEmbeddingsConfig) (*OpenAIEmbeddings, func() []string) {
+	t.Helper()
+	var mu sync.Mutex
+	var inputs []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			Input []string `json:"input"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Errorf("decoding request: %v", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		mu.Lock()
+		inputs = append(inputs, request.Input...)
+		mu.Unlock()
+
+		response := openai.EmbeddingResponse{Object: "list"}
+		for i, input := range request.Input {
+			embedding := make([]float32, 0, 3)
+			for _, v := range pieceVector(input) {
+				embedding = append(embedding, float32(v))
+			}
+			response.Data = append(response.Data, openai.Embedding{Object: "embedding", Embedding: embedding, Index: i})
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"object": response.Object, "data": response.Data})
+	}))
+	t.Cleanup(server.Close)
+
+	config.OpenAIKey = "test"
+	oe, err := NewOpenAIEmbeddings(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientConfig := openai.DefaultConfig(config.OpenAIKey)
+	clientConfig.BaseURL = server.URL
+	oe.Client = openai.NewClientWithConfig(clientConfig)
+	return oe, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), inputs...)
+	}
+}
+
+func TestOpenAIEmbeddingsSplitLongTexts(t *testing.T) {
+	const ctxLength = 4
+	config := NewOpenAIEmbeddingsConfig()
+	config.EmbeddingCtxLength = ctxLength
+	config.SplitLongTexts = true
+	config.ChunkSize = 3
+	oe, inputs := newTestOpenAIEmbeddings(t, config)
+
+	long := "The quick brown fox jumps over the lazy dog\nand keeps on running"
+	texts := []string{long, "hi", ""}
+	got, err := oe.EmbedDocuments(texts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the long text is cut every ctxLength tokens, each piece weighted by its token count
+	encoding, err := encodingForModel(config.Model)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tokens, _, _ := encoding.Encode(strings.ReplaceAll(long, "\n", " "))
+	if len(tokens)%ctxLength == 0 {
+		t.Fatalf("long text has %d tokens, want a last piece shorter than the others", len(tokens))
+	}
+	want := make([]float64, 3)
+	var pieces []string
+	for start := 0; start < len(tokens); start += ctxLength {
+		end := start + ctxLength
+		if end > len(tokens) {
+			end = len(tokens)
+		}
+		piece, _ := encoding.Decode(tokens[start:end])
+		pieces = append(pieces, piece)
+		for k, v := range pieceVector(piece) {
+			want[k] += v * float64(end-start)
+		}
+	}
+	norm := math.Sqrt(want[0]*want[0] + want[1]*want[1] + want[2]*want[2])
+	for k := range want {
+		want[k] /= norm
+	}
+
+	if len(got) != len(texts) {
+		t.Fatalf("got %d vectors for %d texts", len(got), len(texts))
+	}
+	for k := range want {
+		if math.Abs(got[0][k]-want[k]) > 1e-6 {
+			t.Fatalf("long text got %v, want the weighted average %v", got[0], want)
+		}
+	}
+	if math.Abs(got[1][0]-2/math.Sqrt(5)) > 1e-6 || math.Abs(got[1][2]-1/math.Sqrt(5)) > 1e-6 {
+		t.Errorf("short text got %v, want its one piece normalised", got[1])
+	}
+	if got[2][0] != 0 || got[2][2] != 1 {
+		t.Errorf("empty text got %v, want it sent as is", got[2])
+	}
+
+	sent := inputs()
+	if len(sent) != len(pieces)+2 {
+		t.Errorf("sent %d inputs, want %d pieces and the 2 short texts", len(sent), len(pieces))
+	}
+	for _, input := range sent {
+		if strings.Contains(input, "\n") {
+			t.Errorf("sent %q with a newline", input)
+		}
+	}
+	if joined := strings.Join(pieces, ""); joined != strings.ReplaceAll(long, "\n", " ") {
+		t.Errorf("pieces join to %q", joined)
+	}
+}
+
+func TestDecodePieceKeepsCharactersWhole(t *testing.T) {
+	encoding, err := encodingForModel("text-embedding-ada-002")
+	if err != nil {
+		t.Fatal(err)
+	}
+	const text = "日本語のテキスト🦜🔗 and émojis 🧑‍🚀"
+	tokens, _, _ := encoding.Encode(text)
+
+	// a character takes at most utf8.UTFMax byte tokens, so pieces of that many can always be cut whole
+	const maxTokens = utf8.UTFMax
+	var joined strings.Builder
+	shortened := 0
+	for start := 0; start < len(tokens); {
+		end, piece, err := decodePiece(encoding, tokens, start, maxTokens)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if end <= start || end-start > maxTokens {
+			t.Fatalf("piece from %d ends at %d", start, end)
+		}
+		if !utf8.ValidString(piece) {
+			t.Errorf("piece %q cuts a character", piece)
+		}
+		if end-start < maxTokens && end < len(tokens) {
+			shortened++
+		}
+		joined.WriteString(piece)
+		start = end
+	}
+	if joined.String() != text {
+		t.Errorf("pieces join to %q", joined.String())
+	}
+	if shortened == 0 {
+		t.Error("no piece was moved back, the text does not exercise cutting inside a character")
+	}
+}
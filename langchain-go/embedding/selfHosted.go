@@ -1,7 +1,6 @@
 package embedding
 
 import (
-	"fmt"
 	"strings"
 )
 
@@ -10,11 +9,6 @@ type SelfHostedEmbeddings struct {
 	InferenceKwargs map[string]interface{}
 }
 
-func embedDocuments(pipeline string, texts []string) ([][]float64, error) {
-	// Implement the inference function to send to the remote hardware.
-	return nil, nil // Replace with actual implementation
-}
-
 func NewSelfHostedEmbeddings(inferenceFn func(pipeline string, texts []string) ([][]float64, error)) *SelfHostedEmbeddings {
 	return &SelfHostedEmbeddings{
 		InferenceFn: inferenceFn,
@@ -45,33 +39,3 @@ func (se *SelfHostedEmbeddings) EmbedQuery(text string) ([]float64, error) {
 
 	return embeddings[0], nil
 }
-
-func main() {
-	selfHostedEmbeddings := NewSelfHostedEmbeddings(embedDocuments)
-
-	texts := []string{
-		"First test sentence.",
-		"Second test sentence.",
-	}
-
-	embeddings, err := selfHostedEmbeddings.EmbedDocuments(texts)
-	if err != nil {
-		fmt.Printf("Failed to embed documents: %v\n", err)
-		return
-	}
-
-	fmt.Println("Embeddings:")
-	for i, embedding := range embeddings {
-		fmt.Printf("%d: %v\n", i, embedding)
-	}
-
-	query := "Test query sentence."
-	queryEmbedding, err := selfHostedEmbeddings.EmbedQuery(query)
-	if err != nil {
-		fmt.Printf("Failed to embed query: %v\n", err)
-		return
-	}
-
-	fmt.Println("Query Embedding:")
-	fmt.Println(queryEmbedding)
-}
@@ -3,12 +3,11 @@ package llmSchema
 import (
 	"bytes"
 	"context"
-	"errors"
 	"io/ioutil"
 	"net/http"
 	"sync"
 
-	"github.com/avast/retry-go"
+	"github.com/William-Bohm/langchain-go/langchain-go/util/requests"
 )
 
 // TODO: implement logic for a worker pool/ rate limiter
@@ -25,6 +24,8 @@ type BaseAIClient struct {
 	client          *http.Client
 	clientMutex     sync.Mutex
 	ResponsePayload ResponsePayload
+	// HeaderFunc lets a provider client set its own headers (auth, organization, ...) on every request
+	HeaderFunc func(req *http.Request)
 }
 
 func (c *BaseAIClient) getClient() *http.Client {
@@ -38,6 +39,20 @@ func (c *BaseAIClient) getClient() *http.Client {
 	return c.client
 }
 
+// SetHTTPClient replaces the http.Client requests are sent with, e.g. to set a timeout or transport.
+func (c *BaseAIClient) SetHTTPClient(client *http.Client) {
+	c.clientMutex.Lock()
+	defer c.clientMutex.Unlock()
+	c.client = client
+}
+
+// Do sends the request newRequest builds with the client's http.Client, retrying failed
+// connections, 429 and 5xx responses up to MaxRetries times, see requests.DoWithRetries.
+func (c *BaseAIClient) Do(ctx context.Context, newRequest func() (*http.Request, error)) (*http.Response, error) {
+	return requests.DoWithRetries(ctx, c.getClient(), c.MaxRetries, newRequest)
+}
+
+// Create posts requestPayload and decodes the response, retrying like Do.
 // TODO: make each custom openaiClient implement the request object to handle specific authorization logic
 func (c *BaseAIClient) Create(ctx context.Context, requestPayload RequestPayload) (ResponsePayload, error) {
 	jsonData, err := requestPayload.ToJSON()
@@ -45,34 +60,19 @@ func (c *BaseAIClient) Create(ctx context.Context, requestPayload RequestPayload
 		return nil, err
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.APIBaseURL, bytes.NewBuffer(jsonData))
-	if err != nil {
-		return nil, err
-	}
-
-	c.AddHeaders(req)
-
-	var resp *http.Response
-	err = retry.Do(
-		func() error {
-			resp, err = c.getClient().Do(req)
-			if err != nil {
-				return err
-			}
-			return nil
-		},
-		retry.Attempts(uint(c.MaxRetries)),
-		retry.Context(ctx),
-	)
+	resp, err := c.Do(ctx, func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.APIBaseURL, bytes.NewReader(jsonData))
+		if err != nil {
+			return nil, err
+		}
+		c.AddHeaders(req)
+		return req, nil
+	})
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, errors.New("Request failed with status: " + resp.Status)
-	}
-
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
 		return nil, err
@@ -89,6 +89,9 @@ func (c *BaseAIClient) Create(ctx context.Context, requestPayload RequestPayload
 
 func (c *BaseAIClient) AddHeaders(req *http.Request) {
 	// This method can be overridden by child structs to add custom headers
+	if c.HeaderFunc != nil {
+		c.HeaderFunc(req)
+	}
 }
 
 func NewBaseAIClient(apiBaseURL string, maxRetries int, responsePayload ResponsePayload) *BaseAIClient {
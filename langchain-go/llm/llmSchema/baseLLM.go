@@ -1,6 +1,7 @@
 package llmSchema
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -16,10 +17,17 @@ import (
 // BaseLanguageModel Abstract methods all BaseLanguageModel openaiClient's should define
 type BaseLanguageModel interface {
 	Generate(prompts []string, stop []string) (*LLMResult, error)
+	GenerateWithContext(ctx context.Context, prompts []string, stop []string) (*LLMResult, error)
 	GetNumTokensFromMessage(messages []rootSchema.BaseMessage) (int, error)
 	GetNumTokensFromText(text string) (int, error)
 }
 
+// NewTokenHandler is the part of a callback manager an LLM needs while streaming.
+// callbackSchema.CallbackManager satisfies it; it is declared here to keep llmSchema free of callback imports.
+type NewTokenHandler interface {
+	OnLLMNewToken(token string, verbose bool, args ...interface{})
+}
+
 type LLMResult struct {
 	Generations [][]Generation
 	LLMOutput   map[string]interface{}
@@ -40,7 +48,7 @@ type BaseLLM struct {
 	Verbose         bool
 	CountTokens     bool
 	CallbackManager string
-	Cache           string
+	Cache           BaseCache `comment:"Cache for this LLM's generations. Nil falls back to the global cache set with SetLLMCache."`
 	LLMType         string
 }
 
@@ -50,7 +58,6 @@ func NewDefaultBaseLLM(LLMType string, apiKey string) *BaseLLM {
 		Verbose:         defaults.GetDefaultVerbose(),
 		CountTokens:     defaults.GetDefaultCountTokens(),
 		CallbackManager: defaults.GetDefaultCallbackManager(),
-		Cache:           defaults.GetDefaultCache(),
 		LLMType:         LLMType,
 	}
 }
@@ -71,9 +78,11 @@ func NewBaseLLM(attrs map[string]interface{}, LLMType string) (*BaseLLM, error)
 	}
 
 	if val, ok := attrs["Cache"]; ok {
-		baseLLM.Cache = val.(string)
-	} else {
-		baseLLM.Cache = defaults.GetDefaultCache()
+		cache, ok := val.(BaseCache)
+		if !ok {
+			return nil, fmt.Errorf("invalid value type for Cache: expected BaseCache, got %T", val)
+		}
+		baseLLM.Cache = cache
 	}
 
 	if val, ok := attrs["LLMType"]; ok {
@@ -101,7 +110,7 @@ func (llm *BaseLLM) SetCallBackHandler(handler string) {
 	llm.CallbackManager = handler
 }
 
-func (llm *BaseLLM) SetCache(cache string) {
+func (llm *BaseLLM) SetCache(cache BaseCache) {
 	llm.Cache = cache
 }
 
@@ -161,7 +170,7 @@ func (llm *BaseLLM) baseLLMToMap() map[string]interface{} {
 		"Verbose":         llm.Verbose,
 		"CountTokens":     llm.CountTokens,
 		"CallbackManager": llm.CallbackManager,
-		"Cache":           llm.Cache,
+		"Cache":           llm.Cache != nil,
 		"LLMType":         llm.LLMType,
 	}
 }
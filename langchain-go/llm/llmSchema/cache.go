@@ -0,0 +1,111 @@
+package llmSchema
+
+import (
+	"encoding/json"
+	"sync"
+)
+
+// BaseCache stores generations keyed on a prompt and an llmString, the serialized model name and
+// parameters that produced them. Implementations live in the cache package.
+type BaseCache interface {
+	// Lookup returns the cached generations and whether the key was found.
+	Lookup(prompt string, llmString string) ([]Generation, bool, error)
+	Update(prompt string, llmString string, returnVal []Generation) error
+	Clear() error
+}
+
+// CacheEventHandler is implemented by callback handlers that want cache hit/miss reports.
+// callbackSchema.CallbackManager satisfies it.
+type CacheEventHandler interface {
+	OnLLMCacheHit(prompt string, llmString string, verbose bool, args ...interface{})
+	OnLLMCacheMiss(prompt string, llmString string, verbose bool, args ...interface{})
+}
+
+var (
+	llmCacheMu sync.RWMutex
+	llmCache   BaseCache
+)
+
+// SetLLMCache sets the cache used by every LLM that does not have its own BaseLLM.Cache.
+// Passing nil turns global caching off.
+func SetLLMCache(cache BaseCache) {
+	llmCacheMu.Lock()
+	defer llmCacheMu.Unlock()
+	llmCache = cache
+}
+
+func GetLLMCache() BaseCache {
+	llmCacheMu.RLock()
+	defer llmCacheMu.RUnlock()
+	return llmCache
+}
+
+// ResolveCache picks the cache an LLM should use: its own cache if set, otherwise the global one.
+func (llm *BaseLLM) ResolveCache() BaseCache {
+	if llm.Cache != nil {
+		return llm.Cache
+	}
+	return GetLLMCache()
+}
+
+// LLMString serializes the model name and the parameters that affect the output into a cache key part.
+// encoding/json sorts map keys, so equal parameters always give the same string.
+func LLMString(modelName string, params map[string]interface{}, stop []string) (string, error) {
+	key := make(map[string]interface{}, len(params)+2)
+	for k, v := range params {
+		key[k] = v
+	}
+	key["model_name"] = modelName
+	if stop != nil {
+		key["stop"] = stop
+	}
+	data, err := json.Marshal(key)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// GetPrompts looks every prompt up in cache. It returns the generations that were found by prompt
+// index, and the indexes and prompts that still have to be sent to the model.
+// handler may be nil; otherwise it is told about every hit and miss.
+func GetPrompts(cache BaseCache, llmString string, prompts []string, handler CacheEventHandler, verbose bool) (map[int][]Generation, []int, []string, error) {
+	existing := make(map[int][]Generation)
+	var missingIdxs []int
+	var missingPrompts []string
+	for i, prompt := range prompts {
+		generations, ok, err := cache.Lookup(prompt, llmString)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		if ok {
+			existing[i] = generations
+			if handler != nil {
+				handler.OnLLMCacheHit(prompt, llmString, verbose)
+			}
+			continue
+		}
+		missingIdxs = append(missingIdxs, i)
+		missingPrompts = append(missingPrompts, prompt)
+		if handler != nil {
+			handler.OnLLMCacheMiss(prompt, llmString, verbose)
+		}
+	}
+	return existing, missingIdxs, missingPrompts, nil
+}
+
+// UpdateCache stores the fresh generations for the missing prompts and merges them into existing,
+// so existing then holds generations for every prompt index.
+func UpdateCache(cache BaseCache, existing map[int][]Generation, llmString string, missingIdxs []int, newResults *LLMResult, prompts []string) error {
+	for i, generations := range newResults.Generations {
+		if i >= len(missingIdxs) {
+			break
+		}
+		idx := missingIdxs[i]
+		existing[idx] = generations
+		if err := cache.Update(prompts[idx], llmString, generations); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -3,7 +3,6 @@ package llmSchema
 import (
 	"encoding/json"
 	"errors"
-	"github.com/William-Bohm/langchain-go/langchain-go/llm/openai"
 	"os"
 	"path/filepath"
 	"strings"
@@ -13,13 +12,12 @@ import (
 
 func load_llm_from_config(config map[string]interface{}) (BaseLanguageModel, error) {
 	// Load BaseLanguageModel from config
-	llmType := config["LLMType"].(string)
-	switch llmType {
-	case "openai":
-		return openai.NewFromMap(config)
-	default:
+	llmType, _ := config["LLMType"].(string)
+	factory, ok := LLMTypeToClassMap[llmType]
+	if !ok {
 		return nil, errors.New("invalid BaseLanguageModel type")
 	}
+	return factory(config)
 }
 
 func LoadLLM(file string) (BaseLanguageModel, error) {
@@ -2,9 +2,9 @@ package openaiClient
 
 import (
 	"context"
+	"errors"
 	"github.com/William-Bohm/langchain-go/langchain-go/llm/llmSchema"
 	"net/http"
-	"time"
 )
 
 type OpenAiClient struct {
@@ -13,10 +13,8 @@ type OpenAiClient struct {
 	OrganizationKey string
 }
 
+// addHeaders is installed as the BaseAIClient HeaderFunc, so it must not call back into BaseAIClient.AddHeaders
 func (c *OpenAiClient) addHeaders(req *http.Request) {
-	// Call the parent method to add default headers
-	c.BaseAIClient.AddHeaders(req)
-
 	// custom headers
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", "Bearer "+c.APIKey)
@@ -26,13 +24,12 @@ func (c *OpenAiClient) addHeaders(req *http.Request) {
 
 }
 
-func (c *OpenAiClient) Create(prompts []string, input map[string]interface{}) ([]CompletionResponsePayload, error) {
+// Create sends one completion request per prompt. Cancellation and deadlines are taken from ctx,
+// callers that want a timeout should set one on the context.
+func (c *OpenAiClient) Create(ctx context.Context, prompts []string, input map[string]interface{}) ([]CompletionResponsePayload, error) {
 	var err error
 	var response []CompletionResponsePayload
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-
 	requestPayload, err := c.createCompletionRequestPayload(input)
 	for _, prompt := range prompts {
 		requestPayload.Prompt = prompt
@@ -40,10 +37,10 @@ func (c *OpenAiClient) Create(prompts []string, input map[string]interface{}) ([
 			return nil, err
 		}
 		newResponse, err := c.BaseAIClient.Create(ctx, requestPayload)
-		response = append(response, newResponse.(CompletionResponsePayload))
 		if err != nil {
 			return nil, err
 		}
+		response = append(response, *newResponse.(*CompletionResponsePayload))
 	}
 	return response, err
 }
@@ -126,15 +123,7 @@ func (c *OpenAiClient) createCompletionRequestPayload(input map[string]interface
 				payload.Streaming = b
 			}
 		case "stop":
-			if a, ok := value.([]interface{}); ok && len(a) > 0 {
-				stopWords := make([]string, 0, len(a))
-				for _, v := range a {
-					if s, ok := v.(string); ok && s != "" {
-						stopWords = append(stopWords, s)
-					}
-				}
-				payload.StopWords = stopWords
-			}
+			payload.StopWords = stopWordsFromParam(value)
 		default:
 			// ignore unknown fields
 		}
@@ -155,6 +144,120 @@ func NewOpenAiClient(APIKey string, APIOrganization string, APIBaseURL string, m
 		APIKey,
 		APIOrganization,
 	}
+	baseAiClient.HeaderFunc = client.addHeaders
 
 	return client, err
 }
+
+// NewOpenAiChatClient is NewOpenAiClient for the chat completions endpoint, APIBaseURL should point at /chat/completions.
+func NewOpenAiChatClient(APIKey string, APIOrganization string, APIBaseURL string, maxRetries int) (*OpenAiClient, error) {
+	baseAiClient := llmSchema.NewBaseAIClient(APIBaseURL, maxRetries, NewChatCompletionResponsePayload())
+
+	client := &OpenAiClient{
+		baseAiClient,
+		APIKey,
+		APIOrganization,
+	}
+	baseAiClient.HeaderFunc = client.addHeaders
+
+	return client, nil
+}
+
+// CreateChat sends a single chat completion request for one conversation.
+func (c *OpenAiClient) CreateChat(ctx context.Context, messages []Message, input map[string]interface{}) (*ChatCompletionResponsePayload, error) {
+	requestPayload, err := c.createChatCompletionRequestPayload(input)
+	if err != nil {
+		return nil, err
+	}
+	requestPayload.Messages = messages
+
+	response, err := c.BaseAIClient.Create(ctx, requestPayload)
+	if err != nil {
+		return nil, err
+	}
+	return response.(*ChatCompletionResponsePayload), nil
+}
+
+func (c *OpenAiClient) createChatCompletionRequestPayload(input map[string]interface{}) (*ChatCompletionRequestPayload, error) {
+	payload := &ChatCompletionRequestPayload{}
+
+	for key, value := range input {
+		switch key {
+		case "model_name":
+			if s, ok := value.(string); ok && s != "" {
+				payload.Model = s
+			}
+		case "temperature":
+			if f, ok := value.(float64); ok {
+				payload.Temperature = &f
+			}
+		case "max_tokens":
+			if i, ok := value.(int); ok && i > 0 {
+				payload.MaxTokens = i
+			}
+		case "top_p":
+			if f, ok := value.(float64); ok && f != 0 {
+				payload.TopP = f
+			}
+		case "frequency_penalty":
+			if f, ok := value.(float64); ok && f != 0 {
+				payload.FrequencyPenalty = f
+			}
+		case "presence_penalty":
+			if f, ok := value.(float64); ok && f != 0 {
+				payload.PresencePenalty = f
+			}
+		case "n":
+			if i, ok := value.(int); ok && i != 0 {
+				payload.N = i
+			}
+		case "logit_bias":
+			if m, ok := value.(map[string]interface{}); ok && len(m) > 0 {
+				bias := make(map[string]float64)
+				for k, v := range m {
+					if vf, ok := v.(float64); ok && vf != 0 {
+						bias[k] = vf
+					}
+				}
+				payload.LogitBias = bias
+			}
+		case "stop":
+			payload.StopWords = stopWordsFromParam(value)
+		case "functions":
+			if f, ok := value.([]FunctionDefinition); ok && len(f) > 0 {
+				payload.Functions = f
+			}
+		case "function_call":
+			// "auto", "none" or {"name": "<function>"}
+			payload.FunctionCall = value
+		default:
+			// ignore unknown fields
+		}
+	}
+
+	if payload.Model == "" {
+		return nil, errors.New("model_name is required for chat completions")
+	}
+
+	return payload, nil
+}
+
+// stopWordsFromParam accepts stop sequences as either []string or []interface{} and drops empty entries.
+func stopWordsFromParam(value interface{}) []string {
+	var stopWords []string
+	switch a := value.(type) {
+	case []string:
+		for _, s := range a {
+			if s != "" {
+				stopWords = append(stopWords, s)
+			}
+		}
+	case []interface{}:
+		for _, v := range a {
+			if s, ok := v.(string); ok && s != "" {
+				stopWords = append(stopWords, s)
+			}
+		}
+	}
+	return stopWords
+}
@@ -0,0 +1,87 @@
+package openaiClient
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+)
+
+var (
+	streamDataPrefix = []byte("data:")
+	streamDoneMarker = []byte("[DONE]")
+)
+
+// CompletionStream reads the server-sent events of a streamed completion.
+// Each event has the same shape as a regular completion response, with Choices holding the text delta.
+type CompletionStream struct {
+	response *http.Response
+	reader   *bufio.Reader
+}
+
+// Recv returns the next chunk of the stream, or io.EOF once the server sends [DONE] or closes the body.
+func (s *CompletionStream) Recv() (*CompletionResponsePayload, error) {
+	for {
+		line, err := s.reader.ReadBytes('\n')
+		line = bytes.TrimSpace(line)
+
+		// anything that is not a data line (blank separators, comments, keep-alives) is skipped
+		if bytes.HasPrefix(line, streamDataPrefix) {
+			data := bytes.TrimSpace(bytes.TrimPrefix(line, streamDataPrefix))
+			if bytes.Equal(data, streamDoneMarker) {
+				return nil, io.EOF
+			}
+
+			var chunk CompletionResponsePayload
+			if err := json.Unmarshal(data, &chunk); err != nil {
+				return nil, err
+			}
+			return &chunk, nil
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// Close releases the underlying HTTP connection. It is safe to call more than once.
+func (s *CompletionStream) Close() error {
+	return s.response.Body.Close()
+}
+
+// CreateStream opens a streamed completion for a single prompt. Opening it is retried like any
+// other request, see BaseAIClient.Do, but once the stream is open errors are returned from Recv as-is.
+func (c *OpenAiClient) CreateStream(ctx context.Context, prompt string, input map[string]interface{}) (*CompletionStream, error) {
+	requestPayload, err := c.createCompletionRequestPayload(input)
+	if err != nil {
+		return nil, err
+	}
+	requestPayload.Prompt = prompt
+	requestPayload.Streaming = true
+
+	jsonData, err := requestPayload.ToJSON()
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.Do(ctx, func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.APIBaseURL, bytes.NewReader(jsonData))
+		if err != nil {
+			return nil, err
+		}
+		c.addHeaders(req)
+		req.Header.Set("Accept", "text/event-stream")
+		return req, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &CompletionStream{
+		response: resp,
+		reader:   bufio.NewReader(resp.Body),
+	}, nil
+}
@@ -0,0 +1,146 @@
+package openaiClient
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// countingTransport counts the requests sent through it.
+type countingTransport struct {
+	requests int32
+}
+
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	atomic.AddInt32(&t.requests, 1)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// newStreamServer answers with the given statuses in turn, then streams events.
+func newStreamServer(t *testing.T, events string, statuses ...int) *httptest.Server {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil || payload["stream"] != true || payload["prompt"] != "Say hello" {
+			t.Errorf("got request %v, %v, want a streamed prompt", payload, err)
+		}
+		if r.Header.Get("Accept") != "text/event-stream" || r.Header.Get("Authorization") != "Bearer key" {
+			t.Errorf("got headers %v", r.Header)
+		}
+		if n := atomic.AddInt32(&requests, 1); int(n) <= len(statuses) {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(statuses[n-1])
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		io.WriteString(w, events)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newTestStreamClient(t *testing.T, url string) (OpenAiClient, *countingTransport) {
+	client, err := NewOpenAiClient("key", "", url, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	transport := &countingTransport{}
+	client.SetHTTPClient(&http.Client{Transport: transport})
+	return client, transport
+}
+
+// readStream collects the text deltas until Recv fails.
+func readStream(stream *CompletionStream) ([]string, error) {
+	defer stream.Close()
+	var texts []string
+	for {
+		chunk, err := stream.Recv()
+		if err != nil {
+			return texts, err
+		}
+		texts = append(texts, chunk.Choices[0].Text)
+	}
+}
+
+func TestCompletionStream(t *testing.T) {
+	tests := []struct {
+		name    string
+		events  string
+		want    string
+		wantErr bool
+	}{
+		{
+			"done marker",
+			": keep-alive\n\n" +
+				"data: {\"choices\": [{\"text\": \"Hel\"}]}\n\n" +
+				"event: completion\n" +
+				"data:{\"choices\": [{\"text\": \"lo\"}]}\n\n" +
+				"data: [DONE]\n\n" +
+				"data: {\"choices\": [{\"text\": \"after done\"}]}\n\n",
+			"Hel,lo",
+			false,
+		},
+		{"closed without done marker", "data: {\"choices\": [{\"text\": \"Hi\"}]}\n", "Hi", false},
+		{"crlf line endings", "data: {\"choices\": [{\"text\": \"Hi\"}]}\r\n\r\ndata: [DONE]\r\n", "Hi", false},
+		{"bad json", "data: {\"choices\": [{\"text\": \"Hi\"}]}\n\ndata: {\"choices\": \n\n", "Hi", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, _ := newTestStreamClient(t, newStreamServer(t, tt.events).URL)
+			stream, err := client.CreateStream(context.Background(), "Say hello", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			texts, err := readStream(stream)
+			if strings.Join(texts, ",") != tt.want {
+				t.Errorf("got %v, want %s", texts, tt.want)
+			}
+			if tt.wantErr == (err == io.EOF) {
+				t.Errorf("stream ended with %v", err)
+			}
+		})
+	}
+}
+
+func TestCreateStreamRetries(t *testing.T) {
+	events := "data: {\"choices\": [{\"text\": \"Hi\"}]}\n\ndata: [DONE]\n\n"
+	tests := []struct {
+		name     string
+		statuses []int
+		wantErr  bool
+		requests int32
+	}{
+		{"rate limited then open", []int{http.StatusTooManyRequests}, false, 2},
+		{"server error then open", []int{http.StatusServiceUnavailable}, false, 2},
+		{"bad request is not retried", []int{http.StatusBadRequest}, true, 1},
+		{"unauthorized is not retried", []int{http.StatusUnauthorized}, true, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, transport := newTestStreamClient(t, newStreamServer(t, events, tt.statuses...).URL)
+			stream, err := client.CreateStream(context.Background(), "Say hello", nil)
+			if tt.wantErr {
+				if err == nil {
+					stream.Close()
+					t.Fatal("got no error")
+				}
+			} else {
+				if err != nil {
+					t.Fatal(err)
+				}
+				if texts, err := readStream(stream); strings.Join(texts, ",") != "Hi" || err != io.EOF {
+					t.Errorf("got %v, %v", texts, err)
+				}
+			}
+			// the requests went through the client's own http.Client
+			if transport.requests != tt.requests {
+				t.Errorf("sent %d requests through the client, want %d", transport.requests, tt.requests)
+			}
+		})
+	}
+}
@@ -24,7 +24,7 @@ type CompletionRequestPayload struct {
 	RequestTimeout     float64            `json:"-"`
 	LogitBias          map[string]float64 `json:"logit_bias,omitempty"`
 	MaxRetries         int                `json:"max_retries,omitempty"`
-	Streaming          bool               `json:"streaming,omitempty"`
+	Streaming          bool               `json:"stream,omitempty"`
 	StopWords          []string           `json:"stop,omitempty"`
 }
 
@@ -75,6 +75,86 @@ type Messages struct {
 }
 
 type Message struct {
-	Role    string `json:"role"`
-	Content string `json:"content"`
+	Role         string        `json:"role"`
+	Content      string        `json:"content"`
+	Name         string        `json:"name,omitempty"`
+	FunctionCall *FunctionCall `json:"function_call,omitempty"`
+	ToolCalls    []ToolCall    `json:"tool_calls,omitempty"`
+}
+
+// FunctionDefinition describes a function the chat model may call, Parameters is a JSON schema object.
+type FunctionDefinition struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description,omitempty"`
+	Parameters  map[string]interface{} `json:"parameters"`
+}
+
+// FunctionCall is the model's request to call a function, Arguments is a JSON encoded object.
+type FunctionCall struct {
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"`
+}
+
+// ToolCall is the newer, parallel form of FunctionCall returned in tool_calls.
+type ToolCall struct {
+	ID       string       `json:"id"`
+	Type     string       `json:"type"`
+	Function FunctionCall `json:"function"`
+}
+
+// chat completions endpoint settings
+type ChatCompletionRequestPayload struct {
+	Model            string               `json:"model"`
+	Messages         []Message            `json:"messages"`
+	Temperature      *float64             `json:"temperature,omitempty"`
+	MaxTokens        int                  `json:"max_tokens,omitempty"`
+	TopP             float64              `json:"top_p,omitempty"`
+	FrequencyPenalty float64              `json:"frequency_penalty,omitempty"`
+	PresencePenalty  float64              `json:"presence_penalty,omitempty"`
+	N                int                  `json:"n,omitempty"`
+	LogitBias        map[string]float64   `json:"logit_bias,omitempty"`
+	StopWords        []string             `json:"stop,omitempty"`
+	Functions        []FunctionDefinition `json:"functions,omitempty"`
+	FunctionCall     interface{}          `json:"function_call,omitempty"`
+}
+
+func (p *ChatCompletionRequestPayload) ToJSON() ([]byte, error) {
+	return json.Marshal(p)
+}
+
+// open ai chat completions endpoint response
+type ChatCompletionResponsePayload struct {
+	ID      string  `json:"id,omitempty"`
+	Object  string  `json:"object,omitempty"`
+	Created float64 `json:"created,omitempty"`
+	Model   string  `json:"model,omitempty"`
+
+	Usage struct {
+		CompletionTokens float64 `json:"completion_tokens,omitempty"`
+		PromptTokens     float64 `json:"prompt_tokens,omitempty"`
+		TotalTokens      float64 `json:"total_tokens,omitempty"`
+	} `json:"usage,omitempty"`
+
+	Choices []struct {
+		Index        float64 `json:"index,omitempty"`
+		Message      Message `json:"message"`
+		FinishReason string  `json:"finish_reason,omitempty"`
+	} `json:"choices,omitempty"`
+}
+
+func (p ChatCompletionResponsePayload) FromJSON(data []byte) (llmSchema.ResponsePayload, error) {
+	var response ChatCompletionResponsePayload
+	err := json.Unmarshal(data, &response)
+	if err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+func (p ChatCompletionResponsePayload) NewResponsePayload() llmSchema.ResponsePayload {
+	return ChatCompletionResponsePayload{}
+}
+
+func NewChatCompletionResponsePayload() llmSchema.ResponsePayload {
+	return ChatCompletionResponsePayload{}
 }
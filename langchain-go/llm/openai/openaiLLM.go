@@ -1,6 +1,7 @@
 package openai
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"github.com/William-Bohm/langchain-go/langchain-go/config/logger"
@@ -10,12 +11,23 @@ import (
 	"github.com/William-Bohm/langchain-go/langchain-go/tools/mapTools"
 	"github.com/avast/retry-go"
 	"os"
+	"time"
 )
 
 const openaiApiKeyEnvVarName = "OPENAI_API_KEY"
 const openaiOrganizationEnvVarName = "OPENAI_ORGANIZATION_ID"
 const openaiApiBase = "OPENAI_API_BASE"
 
+func init() {
+	llmSchema.LLMTypeToClassMap["openai"] = func(config map[string]interface{}) (llmSchema.BaseLanguageModel, error) {
+		llm, err := NewFromMap(config)
+		if err != nil {
+			return nil, err
+		}
+		return llm, nil
+	}
+}
+
 type generatedResponse struct {
 	Text         string
 	FinishReason string
@@ -48,6 +60,9 @@ type OpenaiLLM struct {
 	CompletionTokens   float64
 	PromptTokens       float64
 	TotalTokens        float64
+
+	// CallbackHandler receives OnLLMNewToken for every delta when Streaming is enabled.
+	CallbackHandler llmSchema.NewTokenHandler
 }
 
 func (o *OpenaiLLM) GetNumTokensFromMessage(messages []rootSchema.BaseMessage) (int, error) {
@@ -69,7 +84,7 @@ func (o *OpenaiLLM) GetNumTokensFromText(text string) (int, error) {
 	return tokens, nil
 }
 
-func (o *OpenaiLLM) sendRequest(prompts []string) ([]openaiClient.CompletionResponsePayload, error) {
+func (o *OpenaiLLM) sendRequest(ctx context.Context, prompts []string) ([]openaiClient.CompletionResponsePayload, error) {
 	// TODO: add openaiClient to openAI struct and at initialization (NewOpenaiLLM)
 	// create request payload
 
@@ -81,13 +96,14 @@ func (o *OpenaiLLM) sendRequest(prompts []string) ([]openaiClient.CompletionResp
 	retryOpts := []retry.Option{
 		retry.Attempts(uint(o.MaxRetries)),
 		retry.DelayType(retry.FixedDelay),
+		retry.Context(ctx),
 	}
 
 	// Wrap the createCompletion function with the retry package.
 	params := o.defaultParams()
 	err := retry.Do(
 		func() error {
-			response, err := o.Client.Create(prompts, params)
+			response, err := o.Client.Create(ctx, prompts, params)
 			if err != nil {
 				return err
 			}
@@ -105,23 +121,83 @@ func (o *OpenaiLLM) sendRequest(prompts []string) ([]openaiClient.CompletionResp
 
 // 'choices' is responses
 func (o *OpenaiLLM) Generate(prompts []string, stop []string) (*llmSchema.LLMResult, error) {
+	return o.GenerateWithContext(context.Background(), prompts, stop)
+}
+
+// GenerateWithContext is Generate with cancellation and deadlines taken from ctx.
+// RequestTimeout, when set, bounds the whole generation on top of any deadline already on ctx.
+// Prompts already in the LLM cache are answered from it and only the rest are sent to the API.
+func (o *OpenaiLLM) GenerateWithContext(ctx context.Context, prompts []string, stop []string) (*llmSchema.LLMResult, error) {
+	ctx, cancel := o.withRequestTimeout(ctx)
+	defer cancel()
+
+	cache := o.ResolveCache()
+	if cache == nil {
+		return o.generate(ctx, prompts, stop)
+	}
+
+	llmString, err := o.llmString(stop)
+	if err != nil {
+		return nil, err
+	}
+	cacheHandler, _ := o.CallbackHandler.(llmSchema.CacheEventHandler)
+	existing, missingIdxs, missingPrompts, err := llmSchema.GetPrompts(cache, llmString, prompts, cacheHandler, o.Verbose)
+	if err != nil {
+		return nil, err
+	}
+
+	llmOutput := map[string]interface{}{}
+	if len(missingPrompts) > 0 {
+		newResults, err := o.generate(ctx, missingPrompts, stop)
+		if err != nil {
+			return nil, err
+		}
+		if err := llmSchema.UpdateCache(cache, existing, llmString, missingIdxs, newResults, prompts); err != nil {
+			return nil, err
+		}
+		llmOutput = newResults.LLMOutput
+	}
+
+	generations := make([][]llmSchema.Generation, len(prompts))
+	for i := range prompts {
+		generations[i] = existing[i]
+	}
+	return &llmSchema.LLMResult{
+		Generations: generations,
+		LLMOutput:   llmOutput,
+	}, nil
+}
+
+// llmString is the cache key part for the current model settings. Settings that do not change the
+// completion, like timeouts and streaming, are left out.
+func (o *OpenaiLLM) llmString(stop []string) (string, error) {
+	params := o.defaultParams()
+	delete(params, "request_timeout")
+	delete(params, "streaming")
+	return llmSchema.LLMString(string(o.Model), params, stop)
+}
+
+func (o *OpenaiLLM) generate(ctx context.Context, prompts []string, stop []string) (*llmSchema.LLMResult, error) {
 	var err error
 	params := o.defaultParams()
 	subPrompts, err := o.GetSubPrompts(params, prompts, stop)
 	if err != nil {
 		return nil, err
 	}
+	if o.Streaming {
+		return o.generateStream(ctx, prompts, params)
+	}
 	generatedResponses := make([]generatedResponse, 0)
 	tokenUsage := make(map[string]float64)
 
 	for _, prompts := range subPrompts {
-		rawResponse, err := o.sendRequest(prompts)
+		rawResponse, err := o.sendRequest(ctx, prompts)
+		if err != nil {
+			return &llmSchema.LLMResult{}, err
+		}
 		for _, promptResponse := range rawResponse {
 			for _, choice := range promptResponse.Choices {
 				// get the text, finish reason, and log probs from the return value
-				if err != nil {
-					return &llmSchema.LLMResult{}, err
-				}
 				text := choice.Text
 				finishReason := choice.FinishReason
 				logProbs := choice.Logprobs
@@ -146,6 +222,24 @@ func (o *OpenaiLLM) Generate(prompts []string, stop []string) (*llmSchema.LLMRes
 	return result, nil
 }
 
+// withRequestTimeout derives a context bounded by RequestTimeout (seconds, or a time.Duration).
+// A missing or non-positive RequestTimeout leaves ctx untouched.
+func (o *OpenaiLLM) withRequestTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	var timeout time.Duration
+	switch rt := o.RequestTimeout.(type) {
+	case time.Duration:
+		timeout = rt
+	case int:
+		timeout = time.Duration(rt) * time.Second
+	case float64:
+		timeout = time.Duration(rt * float64(time.Second))
+	}
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
 func (o *OpenaiLLM) updateTokenUsage(completionTokens float64, promoptTokens float64, totalTokens float64) {
 	o.PromptTokens = o.PromptTokens + promoptTokens
 	o.CompletionTokens = o.CompletionTokens + completionTokens
@@ -274,7 +368,7 @@ func New(options ...Option) (*OpenaiLLM, error) {
 func NewFromMap(attrs map[string]interface{}) (*OpenaiLLM, error) {
 	baseLLM, err := llmSchema.NewBaseLLM(attrs, "openai")
 	if err != nil {
-		logger.Error("Failed to create base BaseLanguageModel: %s", err)
+		logger.Error("Failed to create base BaseLanguageModel:", err)
 		return nil, err
 	}
 
@@ -386,6 +480,12 @@ func NewFromMap(attrs map[string]interface{}) (*OpenaiLLM, error) {
 			} else {
 				return nil, fmt.Errorf("invalid value type for %s: expected bool, got %T", key, value)
 			}
+		case "Cache":
+			if val, ok := value.(llmSchema.BaseCache); ok {
+				opt = Cache(val)
+			} else {
+				return nil, fmt.Errorf("invalid value type for %s: expected llmSchema.BaseCache, got %T", key, value)
+			}
 		case "AllowedSpecial":
 			opt = AllowedSpecial(value)
 		case "DisallowedSpecial":
@@ -453,7 +553,7 @@ func Model(m string) Option {
 		if m != "" {
 			var tempModel = openaiClient.Model(m)
 			if openaiClient.IsValidModel(tempModel) {
-				logger.Error("Invalid model: %s", m)
+				logger.Error("Invalid model:", m)
 				return errors.New(fmt.Sprintf("invalid model: %s", m))
 			}
 			model = tempModel
@@ -579,6 +679,21 @@ func Streaming(s bool) Option {
 	}
 }
 
+func CallbackHandler(h llmSchema.NewTokenHandler) Option {
+	return func(o *OpenaiLLM) error {
+		o.CallbackHandler = h
+		return nil
+	}
+}
+
+// Cache sets a cache for this LLM only, overriding the global llmSchema.SetLLMCache.
+func Cache(c llmSchema.BaseCache) Option {
+	return func(o *OpenaiLLM) error {
+		o.BaseLLM.Cache = c
+		return nil
+	}
+}
+
 func AllowedSpecial(as interface{}) Option {
 	return func(o *OpenaiLLM) error {
 		o.AllowedSpecial = as
@@ -0,0 +1,158 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sort"
+
+	"github.com/William-Bohm/langchain-go/langchain-go/llm/llmSchema"
+	"github.com/William-Bohm/langchain-go/langchain-go/llm/openai/openaiClient"
+)
+
+// StreamEvent is a single delta from a streamed completion.
+// Index is the choice the token belongs to when N > 1. The final event of a choice carries its
+// FinishReason. A non-nil Err is always the last event sent before the channel is closed.
+type StreamEvent struct {
+	Token        string
+	Index        int
+	FinishReason string
+	LogProbs     interface{}
+	Err          error
+}
+
+// Stream starts a streamed completion for prompt and returns a channel of token deltas.
+// OnLLMNewToken is fired on CallbackHandler for every delta as it arrives. The channel is closed
+// when the completion finishes, fails or ctx is done; callers should drain it or cancel ctx.
+func (o *OpenaiLLM) Stream(ctx context.Context, prompt string, stop []string) (<-chan StreamEvent, error) {
+	params := o.defaultParams()
+	if stop != nil {
+		if _, ok := params["stop"]; ok {
+			return nil, errors.New("`stop` found in both the input and default params")
+		}
+		params["stop"] = stop
+	}
+	return o.stream(ctx, prompt, params)
+}
+
+func (o *OpenaiLLM) stream(ctx context.Context, prompt string, params map[string]interface{}) (<-chan StreamEvent, error) {
+	if o.Client == nil {
+		return nil, errors.New("openai client is not initialized")
+	}
+
+	stream, err := o.Client.CreateStream(ctx, prompt, params)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make(chan StreamEvent)
+	go func() {
+		defer close(events)
+		defer stream.Close()
+
+		send := func(event StreamEvent) bool {
+			select {
+			case events <- event:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		for {
+			chunk, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				return
+			}
+			if err != nil {
+				send(StreamEvent{Err: err})
+				return
+			}
+
+			for _, choice := range chunk.Choices {
+				if choice.Text != "" && o.CallbackHandler != nil {
+					o.CallbackHandler.OnLLMNewToken(choice.Text, o.Verbose, map[string]interface{}{"index": int(choice.Index)})
+				}
+				event := StreamEvent{
+					Token:        choice.Text,
+					Index:        int(choice.Index),
+					FinishReason: choice.FinishReason,
+					LogProbs:     choice.Logprobs,
+				}
+				if !send(event) {
+					return
+				}
+			}
+		}
+	}()
+
+	return events, nil
+}
+
+// generateStream is the Streaming counterpart of sending batched requests: every prompt is
+// streamed in turn and its deltas are folded back into one generation per choice.
+// The streaming endpoint does not report usage, so token counts are computed locally with tiktoken.
+func (o *OpenaiLLM) generateStream(ctx context.Context, prompts []string, params map[string]interface{}) (*llmSchema.LLMResult, error) {
+	generatedResponses := make([]generatedResponse, 0, len(prompts)*o.N)
+	tokenUsage := make(map[string]float64)
+
+	for _, prompt := range prompts {
+		events, err := o.stream(ctx, prompt, params)
+		if err != nil {
+			return &llmSchema.LLMResult{}, err
+		}
+
+		choices := make(map[int]*generatedResponse)
+		for event := range events {
+			if event.Err != nil {
+				return &llmSchema.LLMResult{}, event.Err
+			}
+			choice, ok := choices[event.Index]
+			if !ok {
+				choice = &generatedResponse{}
+				choices[event.Index] = choice
+			}
+			choice.Text += event.Token
+			if event.FinishReason != "" {
+				choice.FinishReason = event.FinishReason
+			}
+			if event.LogProbs != nil {
+				choice.LogProbs = event.LogProbs
+			}
+		}
+		if err := ctx.Err(); err != nil {
+			return &llmSchema.LLMResult{}, err
+		}
+
+		indexes := make([]int, 0, len(choices))
+		for index := range choices {
+			indexes = append(indexes, index)
+		}
+		sort.Ints(indexes)
+		if len(indexes) != o.N {
+			return &llmSchema.LLMResult{}, errors.New("stream ended before all choices were received")
+		}
+
+		promptTokens, err := openaiClient.GetNumTokensForText(prompt, o.Model)
+		if err != nil {
+			return &llmSchema.LLMResult{}, err
+		}
+		var completionTokens int
+		for _, index := range indexes {
+			choice := choices[index]
+			generatedResponses = append(generatedResponses, *choice)
+			numTokens, err := openaiClient.GetNumTokensForText(choice.Text, o.Model)
+			if err != nil {
+				return &llmSchema.LLMResult{}, err
+			}
+			completionTokens += numTokens
+		}
+
+		tokenUsage["prompt_tokens"] += float64(promptTokens)
+		tokenUsage["completion_tokens"] += float64(completionTokens)
+		tokenUsage["total_tokens"] += float64(promptTokens + completionTokens)
+		o.updateTokenUsage(float64(completionTokens), float64(promptTokens), float64(promptTokens+completionTokens))
+	}
+
+	return o.createllmSchema(generatedResponses, prompts, tokenUsage)
+}
@@ -0,0 +1,25 @@
+package retriever
+
+import (
+	"context"
+
+	"github.com/William-Bohm/langchain-go/langchain-go/documentStore/documentSchema"
+	"github.com/William-Bohm/langchain-go/langchain-go/vectorstore"
+)
+
+// Retriever returns the documents relevant to a query.
+type Retriever interface {
+	GetRelevantDocuments(ctx context.Context, query string) ([]documentSchema.Document, error)
+}
+
+var (
+	_ Retriever = (*vectorstore.VectorStoreRetriever)(nil)
+	_ Retriever = (*ChatGPTPluginRetriever)(nil)
+	_ Retriever = (*EnsembleRetriever)(nil)
+	_ Retriever = (*BM25Retriever)(nil)
+	_ Retriever = (*ContextualCompressionRetriever)(nil)
+	_ Retriever = (*MultiQueryRetriever)(nil)
+	_ Retriever = (*ParentDocumentRetriever)(nil)
+	_ Retriever = (*SelfQueryRetriever)(nil)
+	_ Retriever = (*TimeWeightedVectorStoreRetriever)(nil)
+)
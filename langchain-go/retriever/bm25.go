@@ -0,0 +1,305 @@
+package retriever
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/William-Bohm/langchain-go/langchain-go/documentStore/documentSchema"
+	"github.com/William-Bohm/langchain-go/langchain-go/tools/token"
+)
+
+const (
+	DefaultBM25K1 = 1.5
+	DefaultBM25B  = 0.75
+	DefaultBM25K  = 4
+)
+
+// BM25Retriever ranks documents by Okapi BM25 keyword relevance, without embeddings. Documents are
+// tokenized with Tokenizer and every token is passed through Stemmer, if set; queries go through
+// the same steps.
+type BM25Retriever struct {
+	K         int     `comment:"Number of documents to return."`
+	K1        float64 `comment:"Term frequency saturation. Higher values let repeated terms count for more."`
+	B         float64 `comment:"Document length normalization, from 0 (none) to 1 (full)."`
+	Tokenizer func(text string) []string
+	Stemmer   func(token string) string
+	mu        sync.RWMutex
+	documents []documentSchema.Document
+	termFreqs []map[string]int
+	docLens   []int
+	docFreqs  map[string]int
+	totalLen  int
+}
+
+func NewBM25Retriever(docs []documentSchema.Document, options ...BM25Option) (*BM25Retriever, error) {
+	r := &BM25Retriever{
+		K:         DefaultBM25K,
+		K1:        DefaultBM25K1,
+		B:         DefaultBM25B,
+		Tokenizer: DefaultTokenizer,
+		docFreqs:  map[string]int{},
+	}
+	for _, option := range options {
+		if err := option(r); err != nil {
+			return nil, err
+		}
+	}
+	r.AddDocuments(docs)
+	return r, nil
+}
+
+// BM25FromTexts builds a retriever over texts, with metadatas[i] attached to texts[i] if given.
+func BM25FromTexts(texts []string, metadatas []map[string]interface{}, options ...BM25Option) (*BM25Retriever, error) {
+	if metadatas != nil && len(metadatas) != len(texts) {
+		return nil, fmt.Errorf("got %d metadatas for %d texts", len(metadatas), len(texts))
+	}
+	docs := make([]documentSchema.Document, len(texts))
+	for i, text := range texts {
+		docs[i] = documentSchema.Document{PageContent: text, Metadata: map[string]interface{}{}}
+		if metadatas != nil && metadatas[i] != nil {
+			docs[i].Metadata = metadatas[i]
+		}
+	}
+	return NewBM25Retriever(docs, options...)
+}
+
+// DefaultTokenizer is token.Words.
+func DefaultTokenizer(text string) []string {
+	return token.Words(text)
+}
+
+func (r *BM25Retriever) tokenize(text string) []string {
+	tokens := r.Tokenizer(text)
+	if r.Stemmer == nil {
+		return tokens
+	}
+	stemmed := tokens[:0]
+	for _, token := range tokens {
+		if token = r.Stemmer(token); token != "" {
+			stemmed = append(stemmed, token)
+		}
+	}
+	return stemmed
+}
+
+// AddDocuments indexes more documents. Existing scores change, since BM25 depends on corpus statistics.
+func (r *BM25Retriever) AddDocuments(docs []documentSchema.Document) {
+	termFreqs := make([]map[string]int, len(docs))
+	for i, doc := range docs {
+		termFreqs[i] = map[string]int{}
+		for _, token := range r.tokenize(doc.PageContent) {
+			termFreqs[i][token]++
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, doc := range docs {
+		r.addIndexed(doc, termFreqs[i])
+	}
+}
+
+func (r *BM25Retriever) addIndexed(doc documentSchema.Document, termFreq map[string]int) {
+	length := 0
+	for term, count := range termFreq {
+		r.docFreqs[term]++
+		length += count
+	}
+	r.documents = append(r.documents, doc)
+	r.termFreqs = append(r.termFreqs, termFreq)
+	r.docLens = append(r.docLens, length)
+	r.totalLen += length
+}
+
+func (r *BM25Retriever) Len() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.documents)
+}
+
+func (r *BM25Retriever) GetRelevantDocuments(ctx context.Context, query string) ([]documentSchema.Document, error) {
+	docs, _ := r.GetRelevantDocumentsWithScores(query)
+	return docs, nil
+}
+
+// GetRelevantDocumentsWithScores returns the K best matching documents and their BM25 scores.
+// Documents sharing no term with the query are never returned.
+func (r *BM25Retriever) GetRelevantDocumentsWithScores(query string) ([]documentSchema.Document, []float64) {
+	terms := r.tokenize(query)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if len(r.documents) == 0 {
+		return nil, nil
+	}
+
+	n := float64(len(r.documents))
+	avgLen := float64(r.totalLen) / n
+	if avgLen == 0 {
+		avgLen = 1
+	}
+	scores := make([]float64, len(r.documents))
+	for _, term := range terms {
+		df := r.docFreqs[term]
+		if df == 0 {
+			continue
+		}
+		idf := math.Log((n-float64(df)+0.5)/(float64(df)+0.5) + 1)
+		for i, termFreq := range r.termFreqs {
+			tf := float64(termFreq[term])
+			if tf == 0 {
+				continue
+			}
+			norm := 1 - r.B + r.B*float64(r.docLens[i])/avgLen
+			scores[i] += idf * tf * (r.K1 + 1) / (tf + r.K1*norm)
+		}
+	}
+
+	var order []int
+	for i, score := range scores {
+		if score > 0 {
+			order = append(order, i)
+		}
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return scores[order[a]] > scores[order[b]]
+	})
+	if len(order) > r.K {
+		order = order[:r.K]
+	}
+
+	docs := make([]documentSchema.Document, len(order))
+	topScores := make([]float64, len(order))
+	for i, position := range order {
+		docs[i] = r.documents[position]
+		topScores[i] = scores[position]
+	}
+	return docs, topScores
+}
+
+type bm25File struct {
+	K         int                       `json:"k"`
+	K1        float64                   `json:"k1"`
+	B         float64                   `json:"b"`
+	Documents []documentSchema.Document `json:"documents"`
+	TermFreqs []map[string]int          `json:"term_freqs"`
+}
+
+// Save writes the documents and their tokenized form to path. A temporary file is written first so
+// a crash never leaves a half-written index behind.
+func (r *BM25Retriever) Save(path string) error {
+	r.mu.RLock()
+	data, err := json.Marshal(bm25File{
+		K:         r.K,
+		K1:        r.K1,
+		B:         r.B,
+		Documents: r.documents,
+		TermFreqs: r.termFreqs,
+	})
+	r.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	dirPath := filepath.Dir(path)
+	if err := os.MkdirAll(dirPath, 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dirPath, filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// LoadBM25Retriever reads an index written by Save. Documents are not re-tokenized, so options must
+// configure the same Tokenizer and Stemmer the index was built with, or queries will not match.
+func LoadBM25Retriever(path string, options ...BM25Option) (*BM25Retriever, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var saved bm25File
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return nil, fmt.Errorf("reading bm25 index %s: %w", path, err)
+	}
+	if len(saved.TermFreqs) != len(saved.Documents) {
+		return nil, fmt.Errorf("bm25 index %s has %d term frequency tables for %d documents", path, len(saved.TermFreqs), len(saved.Documents))
+	}
+
+	r, err := NewBM25Retriever(nil, append([]BM25Option{BM25K(saved.K), BM25K1(saved.K1), BM25B(saved.B)}, options...)...)
+	if err != nil {
+		return nil, err
+	}
+	for i, doc := range saved.Documents {
+		r.addIndexed(doc, saved.TermFreqs[i])
+	}
+	return r, nil
+}
+
+type BM25Option func(*BM25Retriever) error
+
+func BM25K(k int) BM25Option {
+	return func(r *BM25Retriever) error {
+		if k <= 0 {
+			return errors.New("k must be positive")
+		}
+		r.K = k
+		return nil
+	}
+}
+
+func BM25K1(k1 float64) BM25Option {
+	return func(r *BM25Retriever) error {
+		if k1 < 0 {
+			return errors.New("k1 can not be negative")
+		}
+		r.K1 = k1
+		return nil
+	}
+}
+
+func BM25B(b float64) BM25Option {
+	return func(r *BM25Retriever) error {
+		if b < 0 || b > 1 {
+			return errors.New("b must be between 0 and 1")
+		}
+		r.B = b
+		return nil
+	}
+}
+
+func BM25Tokenizer(tokenizer func(text string) []string) BM25Option {
+	return func(r *BM25Retriever) error {
+		if tokenizer == nil {
+			return errors.New("tokenizer can not be nil")
+		}
+		r.Tokenizer = tokenizer
+		return nil
+	}
+}
+
+// BM25Stemmer reduces every token, e.g. "running" to "run". Tokens stemmed to "" are dropped, so a
+// stemmer can also remove stop words.
+func BM25Stemmer(stemmer func(token string) string) BM25Option {
+	return func(r *BM25Retriever) error {
+		r.Stemmer = stemmer
+		return nil
+	}
+}
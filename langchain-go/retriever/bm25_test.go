@@ -0,0 +1,160 @@
+package retriever
+
+import (
+	"context"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBM25Score(t *testing.T) {
+	bm25, err := BM25FromTexts([]string{"the cat sat", "the dog sat", "the cat and the hat"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, scores := bm25.GetRelevantDocumentsWithScores("cat")
+	if contents(got) != "the cat sat,the cat and the hat" {
+		t.Fatalf("got %s, want the two cat documents, shortest first", contents(got))
+	}
+
+	// "cat" is in 2 of 3 documents, which have 11 tokens in total
+	idf := math.Log((3-2+0.5)/(2+0.5) + 1)
+	avgLen := 11.0 / 3
+	want := func(docLen float64) float64 {
+		return idf * (DefaultBM25K1 + 1) / (1 + DefaultBM25K1*(1-DefaultBM25B+DefaultBM25B*docLen/avgLen))
+	}
+	for i, docLen := range []float64{3, 5} {
+		if math.Abs(scores[i]-want(docLen)) > 1e-12 {
+			t.Errorf("score of %q = %f, want %f", got[i].PageContent, scores[i], want(docLen))
+		}
+	}
+}
+
+func TestBM25Ranking(t *testing.T) {
+	texts := []string{
+		"apple banana",
+		"apple cherry",
+		"apple banana banana banana",
+		"durian durian durian durian durian durian durian banana",
+	}
+	stopApple := func(token string) string {
+		if token == "apple" {
+			return ""
+		}
+		return token
+	}
+	tests := []struct {
+		name    string
+		options []BM25Option
+		query   string
+		want    string
+	}{
+		{"rare terms outweigh common ones", nil, "apple cherry", "apple cherry,apple banana,apple banana banana banana"},
+		{"repeated terms count for more", nil, "banana", "apple banana banana banana,apple banana,durian durian durian durian durian durian durian banana"},
+		{"k1 0 ignores repeated terms", []BM25Option{BM25K1(0)}, "banana", "apple banana,apple banana banana banana,durian durian durian durian durian durian durian banana"},
+		{"k limits the results", []BM25Option{BM25K(1)}, "cherry", "apple cherry"},
+		{"query is tokenized like documents", nil, "CHERRY!", "apple cherry"},
+		{"no shared terms", nil, "mango", ""},
+		{"stemmer drops tokens", []BM25Option{BM25Stemmer(stopApple)}, "apple", ""},
+		{
+			"custom tokenizer",
+			[]BM25Option{BM25Tokenizer(func(text string) []string { return strings.Split(text, " ") })},
+			"Cherry",
+			"",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bm25, err := BM25FromTexts(texts, nil, tt.options...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := bm25.GetRelevantDocuments(context.Background(), tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if contents(got) != tt.want {
+				t.Errorf("got %s, want %s", contents(got), tt.want)
+			}
+		})
+	}
+}
+
+func TestBM25LengthNormalisation(t *testing.T) {
+	texts := []string{"banana banana a b c d e f g h", "banana"}
+	tests := []struct {
+		b    float64
+		want string
+	}{
+		{0, "banana banana a b c d e f g h,banana"},
+		{1, "banana,banana banana a b c d e f g h"},
+	}
+	for _, tt := range tests {
+		bm25, err := BM25FromTexts(texts, nil, BM25B(tt.b))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, _ := bm25.GetRelevantDocumentsWithScores("banana")
+		if contents(got) != tt.want {
+			t.Errorf("b=%v: got %s, want %s", tt.b, contents(got), tt.want)
+		}
+	}
+}
+
+func TestBM25SaveLoad(t *testing.T) {
+	bm25, err := BM25FromTexts(
+		[]string{"the cat sat", "the dog sat", "the cat and the hat"},
+		[]map[string]interface{}{{"source": "a"}, {"source": "b"}, {"source": "c"}},
+		BM25K(2), BM25K1(1.2), BM25B(0.5),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "index", "bm25.json")
+	if err := bm25.Save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadBM25Retriever(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.K != 2 || loaded.K1 != 1.2 || loaded.B != 0.5 || loaded.Len() != 3 {
+		t.Fatalf("loaded K=%d K1=%f B=%f with %d documents, want 2, 1.2, 0.5 and 3", loaded.K, loaded.K1, loaded.B, loaded.Len())
+	}
+	for _, query := range []string{"cat", "sat", "the hat", "dog cat"} {
+		wantDocs, wantScores := bm25.GetRelevantDocumentsWithScores(query)
+		gotDocs, gotScores := loaded.GetRelevantDocumentsWithScores(query)
+		if !reflect.DeepEqual(gotDocs, wantDocs) || !reflect.DeepEqual(gotScores, wantScores) {
+			t.Errorf("%q: loaded index returned %v %v, want %v %v", query, gotDocs, gotScores, wantDocs, wantScores)
+		}
+	}
+
+	// a loaded index keeps growing like a new one
+	loaded.AddDocuments(docs("a hat on a cat"))
+	if got, _ := loaded.GetRelevantDocumentsWithScores("hat"); len(got) != 2 {
+		t.Fatalf("got %d hat documents after adding one, want 2", len(got))
+	}
+}
+
+func TestLoadBM25RetrieverCorrupt(t *testing.T) {
+	dir := t.TempDir()
+	tests := map[string]string{
+		"not json":           `{"documents": [`,
+		"missing term freqs": `{"k": 4, "k1": 1.5, "b": 0.75, "documents": [{"page_content": "cat"}], "term_freqs": []}`,
+	}
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(dir, name+".json")
+			if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := LoadBM25Retriever(path); err == nil {
+				t.Fatal("loaded a corrupt index")
+			}
+		})
+	}
+}
@@ -2,19 +2,16 @@ package retriever
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 
+	"github.com/William-Bohm/langchain-go/langchain-go/documentStore/documentSchema"
 	"github.com/valyala/fasthttp"
 )
 
-type Document struct {
-	PageContent string                 `json:"page_content"`
-	Metadata    map[string]interface{} `json:"metadata"`
-}
-
 type ChatGPTPluginRetriever struct {
 	URL          string
 	BearerToken  string
@@ -41,7 +38,7 @@ func (r *ChatGPTPluginRetriever) createRequest(query string) (string, map[string
 	return url, jsonBody, headers
 }
 
-func (r *ChatGPTPluginRetriever) GetRelevantDocuments(query string) ([]Document, error) {
+func (r *ChatGPTPluginRetriever) GetRelevantDocuments(ctx context.Context, query string) ([]documentSchema.Document, error) {
 	url, jsonBody, headers := r.createRequest(query)
 
 	jsonData, err := json.Marshal(jsonBody)
@@ -49,7 +46,7 @@ func (r *ChatGPTPluginRetriever) GetRelevantDocuments(query string) ([]Document,
 		return nil, err
 	}
 
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
 	if err != nil {
 		return nil, err
 	}
@@ -76,13 +73,13 @@ func (r *ChatGPTPluginRetriever) GetRelevantDocuments(query string) ([]Document,
 	}
 
 	results := data["results"].([]interface{})[0].(map[string]interface{})["results"].([]interface{})
-	var docs []Document
+	var docs []documentSchema.Document
 
 	for _, d := range results {
 		docData := d.(map[string]interface{})
 		content := docData["text"].(string)
 		delete(docData, "text")
-		docs = append(docs, Document{PageContent: content, Metadata: docData})
+		docs = append(docs, documentSchema.Document{PageContent: content, Metadata: docData})
 	}
 
 	return docs, nil
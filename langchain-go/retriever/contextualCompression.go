@@ -0,0 +1,57 @@
+package retriever
+
+import (
+	"context"
+	"errors"
+
+	"github.com/William-Bohm/langchain-go/langchain-go/documentStore/documentSchema"
+	"github.com/William-Bohm/langchain-go/langchain-go/rootSchema"
+)
+
+// DocumentCompressor is satisfied by EmbeddingsFilter, EmbeddingsRedundantFilter and the
+// document_compressor.BaseDocumentCompressor implementations, including DocumentCompressorPipeline.
+type DocumentCompressor interface {
+	CompressDocuments(documents []rootSchema.Document, query string) ([]rootSchema.Document, error)
+}
+
+// ContextualCompressionRetriever fetches documents from BaseRetriever and passes them through
+// BaseCompressor, which may shorten, filter or reorder them with respect to the query.
+type ContextualCompressionRetriever struct {
+	BaseCompressor DocumentCompressor
+	BaseRetriever  Retriever
+}
+
+func NewContextualCompressionRetriever(baseCompressor DocumentCompressor, baseRetriever Retriever) (*ContextualCompressionRetriever, error) {
+	if baseCompressor == nil {
+		return nil, errors.New("contextual compression retriever needs a compressor")
+	}
+	if baseRetriever == nil {
+		return nil, errors.New("contextual compression retriever needs a base retriever")
+	}
+	return &ContextualCompressionRetriever{BaseCompressor: baseCompressor, BaseRetriever: baseRetriever}, nil
+}
+
+func (r *ContextualCompressionRetriever) GetRelevantDocuments(ctx context.Context, query string) ([]documentSchema.Document, error) {
+	docs, err := r.BaseRetriever.GetRelevantDocuments(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	if len(docs) == 0 {
+		return docs, nil
+	}
+
+	toCompress := make([]rootSchema.Document, len(docs))
+	for i, doc := range docs {
+		toCompress[i] = rootSchema.Document{PageContent: doc.PageContent, Metadata: doc.Metadata}
+	}
+	compressed, err := r.BaseCompressor.CompressDocuments(toCompress, query)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]documentSchema.Document, len(compressed))
+	for i, doc := range compressed {
+		result[i] = documentSchema.Document{PageContent: doc.PageContent, Metadata: doc.Metadata}
+	}
+	return result, nil
+}
@@ -0,0 +1,116 @@
+package retriever
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/William-Bohm/langchain-go/langchain-go/embedding/embeddingSchema"
+	"github.com/William-Bohm/langchain-go/langchain-go/rootSchema"
+	"github.com/William-Bohm/langchain-go/langchain-go/vectorstore"
+)
+
+const DefaultEmbeddingsFilterK = 20
+
+// EmbeddingsFilter keeps the documents whose embeddings are most similar to the query's, without
+// calling an LLM. It keeps at most K documents, and only those with a cosine similarity above
+// SimilarityThreshold when that is set. Kept documents are ordered most similar first.
+type EmbeddingsFilter struct {
+	Embeddings          embeddingSchema.BaseEmbeddings
+	K                   int      `comment:"Maximum number of documents to keep. 0 means no limit."`
+	SimilarityThreshold *float64 `comment:"Minimum cosine similarity between a document and the query."`
+}
+
+func NewEmbeddingsFilter(embeddings embeddingSchema.BaseEmbeddings, options ...EmbeddingsFilterOption) (*EmbeddingsFilter, error) {
+	if embeddings == nil {
+		return nil, errors.New("embeddings filter needs an embeddings model")
+	}
+	f := &EmbeddingsFilter{
+		Embeddings: embeddings,
+		K:          DefaultEmbeddingsFilterK,
+	}
+	for _, option := range options {
+		if err := option(f); err != nil {
+			return nil, err
+		}
+	}
+	if f.K == 0 && f.SimilarityThreshold == nil {
+		return nil, errors.New("embeddings filter needs k or a similarity threshold")
+	}
+	return f, nil
+}
+
+func (f *EmbeddingsFilter) CompressDocuments(documents []rootSchema.Document, query string) ([]rootSchema.Document, error) {
+	if len(documents) == 0 {
+		return []rootSchema.Document{}, nil
+	}
+	vectors, err := embedDocuments(f.Embeddings, documents)
+	if err != nil {
+		return nil, err
+	}
+	queryVector, err := f.Embeddings.EmbedQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
+	similarities := make([]float64, len(documents))
+	order := make([]int, len(documents))
+	for i, vector := range vectors {
+		similarities[i] = vectorstore.CosineSimilarity(queryVector, vector)
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return similarities[order[a]] > similarities[order[b]]
+	})
+	if f.K > 0 && len(order) > f.K {
+		order = order[:f.K]
+	}
+
+	filtered := []rootSchema.Document{}
+	for _, i := range order {
+		if f.SimilarityThreshold != nil && similarities[i] <= *f.SimilarityThreshold {
+			continue
+		}
+		filtered = append(filtered, documents[i])
+	}
+	return filtered, nil
+}
+
+func embedDocuments(embeddings embeddingSchema.BaseEmbeddings, documents []rootSchema.Document) ([][]float64, error) {
+	texts := make([]string, len(documents))
+	for i, doc := range documents {
+		texts[i] = doc.PageContent
+	}
+	vectors, err := embeddings.EmbedDocuments(texts)
+	if err != nil {
+		return nil, err
+	}
+	if len(vectors) != len(texts) {
+		return nil, fmt.Errorf("embeddings returned %d vectors for %d documents", len(vectors), len(texts))
+	}
+	return vectors, nil
+}
+
+type EmbeddingsFilterOption func(*EmbeddingsFilter) error
+
+// EmbeddingsFilterK limits the number of documents kept; 0 disables the limit.
+func EmbeddingsFilterK(k int) EmbeddingsFilterOption {
+	return func(f *EmbeddingsFilter) error {
+		if k < 0 {
+			return errors.New("k can not be negative")
+		}
+		f.K = k
+		return nil
+	}
+}
+
+// EmbeddingsFilterThreshold drops documents with a cosine similarity to the query at or below threshold.
+func EmbeddingsFilterThreshold(threshold float64) EmbeddingsFilterOption {
+	return func(f *EmbeddingsFilter) error {
+		if threshold < -1 || threshold > 1 {
+			return errors.New("similarity threshold must be a cosine similarity between -1 and 1")
+		}
+		f.SimilarityThreshold = &threshold
+		return nil
+	}
+}
@@ -0,0 +1,143 @@
+package retriever
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/William-Bohm/langchain-go/langchain-go/embedding"
+	"github.com/William-Bohm/langchain-go/langchain-go/rootSchema"
+)
+
+func rootDocs(contents ...string) []rootSchema.Document {
+	result := make([]rootSchema.Document, len(contents))
+	for i, content := range contents {
+		result[i] = rootSchema.Document{PageContent: content, Metadata: map[string]interface{}{"n": i}}
+	}
+	return result
+}
+
+func rootContents(docs []rootSchema.Document) string {
+	parts := make([]string, len(docs))
+	for i, doc := range docs {
+		parts[i] = doc.PageContent
+	}
+	return strings.Join(parts, ",")
+}
+
+func TestEmbeddingsFilter(t *testing.T) {
+	// similarities to the query "a": aaaa 1, aaab 0.949, aabb 0.707, abbb 0.316, bbbb 0
+	documents := rootDocs("aabb", "bbbb", "aaaa", "abbb", "aaab")
+	tests := []struct {
+		name    string
+		options []EmbeddingsFilterOption
+		want    string
+	}{
+		{"default k keeps all, most similar first", nil, "aaaa,aaab,aabb,abbb,bbbb"},
+		{"k", []EmbeddingsFilterOption{EmbeddingsFilterK(2)}, "aaaa,aaab"},
+		{"threshold only", []EmbeddingsFilterOption{EmbeddingsFilterK(0), EmbeddingsFilterThreshold(0.5)}, "aaaa,aaab,aabb"},
+		{"threshold below k", []EmbeddingsFilterOption{EmbeddingsFilterK(3), EmbeddingsFilterThreshold(0.95)}, "aaaa"},
+		{"k below threshold", []EmbeddingsFilterOption{EmbeddingsFilterK(2), EmbeddingsFilterThreshold(0.3)}, "aaaa,aaab"},
+		{"similarity equal to the threshold is dropped", []EmbeddingsFilterOption{EmbeddingsFilterK(0), EmbeddingsFilterThreshold(1 / math.Sqrt2)}, "aaaa,aaab"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := NewEmbeddingsFilter(embedding.LetterEmbeddings{}, tt.options...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := f.CompressDocuments(documents, "a")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if rootContents(got) != tt.want {
+				t.Errorf("got %s, want %s", rootContents(got), tt.want)
+			}
+			for _, doc := range got {
+				if documents[doc.Metadata["n"].(int)].PageContent != doc.PageContent {
+					t.Errorf("%s got the metadata of another document", doc.PageContent)
+				}
+			}
+		})
+	}
+
+	if _, err := NewEmbeddingsFilter(embedding.LetterEmbeddings{}, EmbeddingsFilterK(0)); err == nil {
+		t.Error("filter without k or threshold got no error")
+	}
+	if _, err := NewEmbeddingsFilter(embedding.LetterEmbeddings{}, EmbeddingsFilterThreshold(1.5)); err == nil {
+		t.Error("threshold above 1 got no error")
+	}
+}
+
+func TestEmbeddingsRedundantFilter(t *testing.T) {
+	tests := []struct {
+		name      string
+		documents []rootSchema.Document
+		threshold float64
+		want      string
+	}{
+		// aaaab is 0.970 similar to aaaa, aabb 0.707
+		{"near duplicate dropped, order kept", rootDocs("bbbb", "aaaa", "aaaab", "aabb"), 0.95, "bbbb,aaaa,aabb"},
+		{"lower threshold drops more", rootDocs("bbbb", "aaaa", "aaaab", "aabb"), 0.7, "bbbb,aaaa"},
+		{"similarity equal to the threshold is kept", rootDocs("ab", "ba"), 1, "ab,ba"},
+		{"identical documents", rootDocs("ab", "ba", "ab"), DefaultRedundancyThreshold, "ab"},
+		{"empty", nil, DefaultRedundancyThreshold, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := NewEmbeddingsRedundantFilter(embedding.LetterEmbeddings{}, tt.threshold)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := f.CompressDocuments(tt.documents, "ignored")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if rootContents(got) != tt.want {
+				t.Errorf("got %s, want %s", rootContents(got), tt.want)
+			}
+		})
+	}
+}
+
+// failingCompressor fails every compression.
+type failingCompressor struct{}
+
+func (failingCompressor) CompressDocuments(documents []rootSchema.Document, query string) ([]rootSchema.Document, error) {
+	return nil, errors.New("compression failed")
+}
+
+func TestContextualCompressionRetriever(t *testing.T) {
+	filter, err := NewEmbeddingsFilter(embedding.LetterEmbeddings{}, EmbeddingsFilterK(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	base := staticRetriever{docs: docs("bbbb", "aaab", "aaaa")}
+	base.docs[2].Metadata["source"] = "a.txt"
+	r, err := NewContextualCompressionRetriever(filter, base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := r.GetRelevantDocuments(context.Background(), "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if contents(got) != "aaaa,aaab" || got[0].Metadata["source"] != "a.txt" {
+		t.Errorf("got %+v", got)
+	}
+
+	r, _ = NewContextualCompressionRetriever(failingCompressor{}, staticRetriever{})
+	if got, err := r.GetRelevantDocuments(context.Background(), "a"); err != nil || len(got) != 0 {
+		t.Errorf("no documents got %v, %v, want the compressor skipped", got, err)
+	}
+	r, _ = NewContextualCompressionRetriever(failingCompressor{}, base)
+	if _, err := r.GetRelevantDocuments(context.Background(), "a"); err == nil {
+		t.Error("compressor error was not returned")
+	}
+	r, _ = NewContextualCompressionRetriever(filter, staticRetriever{err: errors.New("down")})
+	if _, err := r.GetRelevantDocuments(context.Background(), "a"); err == nil {
+		t.Error("retriever error was not returned")
+	}
+}
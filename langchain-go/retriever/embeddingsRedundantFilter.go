@@ -0,0 +1,60 @@
+package retriever
+
+import (
+	"errors"
+
+	"github.com/William-Bohm/langchain-go/langchain-go/embedding/embeddingSchema"
+	"github.com/William-Bohm/langchain-go/langchain-go/rootSchema"
+	"github.com/William-Bohm/langchain-go/langchain-go/vectorstore"
+)
+
+const DefaultRedundancyThreshold = 0.95
+
+// EmbeddingsRedundantFilter drops documents whose embedding has a cosine similarity above
+// SimilarityThreshold with an earlier document that was kept. The order of documents is preserved.
+type EmbeddingsRedundantFilter struct {
+	Embeddings          embeddingSchema.BaseEmbeddings
+	SimilarityThreshold float64 `comment:"Cosine similarity above which two documents count as duplicates."`
+}
+
+func NewEmbeddingsRedundantFilter(embeddings embeddingSchema.BaseEmbeddings, threshold float64) (*EmbeddingsRedundantFilter, error) {
+	if embeddings == nil {
+		return nil, errors.New("redundant filter needs an embeddings model")
+	}
+	if threshold < -1 || threshold > 1 {
+		return nil, errors.New("similarity threshold must be a cosine similarity between -1 and 1")
+	}
+	return &EmbeddingsRedundantFilter{Embeddings: embeddings, SimilarityThreshold: threshold}, nil
+}
+
+func (f *EmbeddingsRedundantFilter) TransformDocuments(documents []rootSchema.Document) ([]rootSchema.Document, error) {
+	if len(documents) == 0 {
+		return []rootSchema.Document{}, nil
+	}
+	vectors, err := embedDocuments(f.Embeddings, documents)
+	if err != nil {
+		return nil, err
+	}
+
+	var kept []int
+	filtered := []rootSchema.Document{}
+	for i, vector := range vectors {
+		redundant := false
+		for _, j := range kept {
+			if vectorstore.CosineSimilarity(vector, vectors[j]) > f.SimilarityThreshold {
+				redundant = true
+				break
+			}
+		}
+		if !redundant {
+			kept = append(kept, i)
+			filtered = append(filtered, documents[i])
+		}
+	}
+	return filtered, nil
+}
+
+// CompressDocuments ignores the query, so the filter can also be used on its own as a compressor.
+func (f *EmbeddingsRedundantFilter) CompressDocuments(documents []rootSchema.Document, query string) ([]rootSchema.Document, error) {
+	return f.TransformDocuments(documents)
+}
@@ -0,0 +1,151 @@
+package retriever
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/William-Bohm/langchain-go/langchain-go/documentStore/documentSchema"
+	"golang.org/x/sync/errgroup"
+)
+
+const DefaultRRFConstant = 60
+
+// EnsembleRetriever queries several retrievers in parallel and merges their results with weighted
+// reciprocal rank fusion: a document at rank r (from 1) in retriever i scores Weights[i] / (C + r),
+// and its scores are summed over all retrievers that returned it.
+type EnsembleRetriever struct {
+	Retrievers []Retriever
+	Weights    []float64 `comment:"Weight of each retriever. Defaults to equal weights."`
+	C          int       `comment:"Rank constant of reciprocal rank fusion. Higher values flatten the rank differences."`
+	IDKey      string    `comment:"Metadata key identifying a document for de-duplication. Empty compares page contents."`
+}
+
+func NewEnsembleRetriever(retrievers []Retriever, options ...EnsembleOption) (*EnsembleRetriever, error) {
+	if len(retrievers) == 0 {
+		return nil, errors.New("ensemble retriever needs at least one retriever")
+	}
+	e := &EnsembleRetriever{
+		Retrievers: retrievers,
+		C:          DefaultRRFConstant,
+	}
+	for _, option := range options {
+		if err := option(e); err != nil {
+			return nil, err
+		}
+	}
+	if e.Weights == nil {
+		e.Weights = make([]float64, len(retrievers))
+		for i := range e.Weights {
+			e.Weights[i] = 1 / float64(len(retrievers))
+		}
+	}
+	if len(e.Weights) != len(retrievers) {
+		return nil, fmt.Errorf("got %d weights for %d retrievers", len(e.Weights), len(retrievers))
+	}
+	return e, nil
+}
+
+func (e *EnsembleRetriever) GetRelevantDocuments(ctx context.Context, query string) ([]documentSchema.Document, error) {
+	results := make([][]documentSchema.Document, len(e.Retrievers))
+	g, ctx := errgroup.WithContext(ctx)
+	for i, retriever := range e.Retrievers {
+		i, retriever := i, retriever
+		g.Go(func() error {
+			docs, err := retriever.GetRelevantDocuments(ctx, query)
+			if err != nil {
+				return err
+			}
+			results[i] = docs
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	return e.RankFusion(results), nil
+}
+
+// RankFusion merges ranked document lists, one per retriever, into a single de-duplicated list
+// ordered by weighted reciprocal rank fusion score. Ties keep the order documents were first seen in.
+func (e *EnsembleRetriever) RankFusion(results [][]documentSchema.Document) []documentSchema.Document {
+	var docs []documentSchema.Document
+	var scores []float64
+	positions := map[string]int{}
+
+	for i, ranked := range results {
+		seen := map[string]bool{}
+		for rank, doc := range ranked {
+			key := e.documentKey(doc)
+			// a retriever that returns a document twice only counts its best rank
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			position, ok := positions[key]
+			if !ok {
+				position = len(docs)
+				positions[key] = position
+				docs = append(docs, doc)
+				scores = append(scores, 0)
+			}
+			scores[position] += e.Weights[i] / float64(e.C+rank+1)
+		}
+	}
+
+	order := make([]int, len(docs))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return scores[order[a]] > scores[order[b]]
+	})
+	fused := make([]documentSchema.Document, len(order))
+	for i, position := range order {
+		fused[i] = docs[position]
+	}
+	return fused
+}
+
+func (e *EnsembleRetriever) documentKey(doc documentSchema.Document) string {
+	if e.IDKey != "" {
+		if id, ok := doc.Metadata[e.IDKey]; ok {
+			return fmt.Sprintf("id:%v", id)
+		}
+	}
+	return "content:" + doc.PageContent
+}
+
+type EnsembleOption func(*EnsembleRetriever) error
+
+// EnsembleWeights sets one weight per retriever, in the same order as the retrievers.
+func EnsembleWeights(weights ...float64) EnsembleOption {
+	return func(e *EnsembleRetriever) error {
+		for _, w := range weights {
+			if w < 0 {
+				return errors.New("weights can not be negative")
+			}
+		}
+		e.Weights = weights
+		return nil
+	}
+}
+
+func RRFConstant(c int) EnsembleOption {
+	return func(e *EnsembleRetriever) error {
+		if c < 0 {
+			return errors.New("rrf constant can not be negative")
+		}
+		e.C = c
+		return nil
+	}
+}
+
+func EnsembleIDKey(key string) EnsembleOption {
+	return func(e *EnsembleRetriever) error {
+		e.IDKey = key
+		return nil
+	}
+}
@@ -0,0 +1,158 @@
+package retriever
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/William-Bohm/langchain-go/langchain-go/documentStore/documentSchema"
+)
+
+// staticRetriever returns the same documents for every query.
+type staticRetriever struct {
+	docs []documentSchema.Document
+	err  error
+}
+
+func (s staticRetriever) GetRelevantDocuments(ctx context.Context, query string) ([]documentSchema.Document, error) {
+	return s.docs, s.err
+}
+
+func docs(contents ...string) []documentSchema.Document {
+	result := make([]documentSchema.Document, len(contents))
+	for i, content := range contents {
+		result[i] = documentSchema.Document{PageContent: content, Metadata: map[string]interface{}{}}
+	}
+	return result
+}
+
+func contents(docs []documentSchema.Document) string {
+	parts := make([]string, len(docs))
+	for i, doc := range docs {
+		parts[i] = doc.PageContent
+	}
+	return strings.Join(parts, ",")
+}
+
+func TestRankFusion(t *testing.T) {
+	withID := func(content string, id string) documentSchema.Document {
+		return documentSchema.Document{PageContent: content, Metadata: map[string]interface{}{"id": id}}
+	}
+
+	tests := []struct {
+		name    string
+		options []EnsembleOption
+		results [][]documentSchema.Document
+		want    string
+	}{
+		{
+			// a: 1/61+1/62, c: 1/61+1/63, b: 1/62
+			name:    "shared documents rank first",
+			results: [][]documentSchema.Document{docs("a", "b", "c"), docs("c", "a")},
+			want:    "a,c,b",
+		},
+		{
+			name:    "weights favour the first retriever",
+			options: []EnsembleOption{EnsembleWeights(0.9, 0.1)},
+			results: [][]documentSchema.Document{docs("a", "b"), docs("b", "a")},
+			want:    "a,b",
+		},
+		{
+			name:    "weights favour the second retriever",
+			options: []EnsembleOption{EnsembleWeights(0.1, 0.9)},
+			results: [][]documentSchema.Document{docs("a", "b"), docs("b", "a")},
+			want:    "b,a",
+		},
+		{
+			// a: 1/1, b: 1/2+1/1, c: 1/3
+			name:    "zero rank constant",
+			options: []EnsembleOption{RRFConstant(0)},
+			results: [][]documentSchema.Document{docs("a", "b", "c"), docs("b")},
+			want:    "b,a,c",
+		},
+		{
+			name:    "ties keep first seen order",
+			results: [][]documentSchema.Document{docs("b"), docs("a")},
+			want:    "b,a",
+		},
+		{
+			// the second a is skipped but still takes rank 2, so b is at rank 3
+			name:    "duplicates count their best rank only",
+			results: [][]documentSchema.Document{docs("a", "a", "b"), docs("b")},
+			want:    "b,a",
+		},
+		{
+			name:    "id key merges documents with different contents",
+			options: []EnsembleOption{EnsembleIDKey("id")},
+			results: [][]documentSchema.Document{
+				{withID("first", "1")},
+				{withID("second", "1"), withID("other", "2")},
+			},
+			want: "first,other",
+		},
+		{
+			name:    "no results",
+			results: [][]documentSchema.Document{nil, nil},
+			want:    "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ensemble, err := NewEnsembleRetriever([]Retriever{staticRetriever{}, staticRetriever{}}, tt.options...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := contents(ensemble.RankFusion(tt.results)); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEnsembleRetriever(t *testing.T) {
+	ensemble, err := NewEnsembleRetriever([]Retriever{
+		staticRetriever{docs: docs("a", "b")},
+		staticRetriever{docs: docs("b", "c")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := ensemble.GetRelevantDocuments(context.Background(), "query")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if contents(got) != "b,a,c" {
+		t.Fatalf("got %s, want b,a,c", contents(got))
+	}
+
+	failing := errors.New("retriever failed")
+	ensemble, err = NewEnsembleRetriever([]Retriever{staticRetriever{docs: docs("a")}, staticRetriever{err: failing}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ensemble.GetRelevantDocuments(context.Background(), "query"); !errors.Is(err, failing) {
+		t.Fatalf("got error %v, want %v", err, failing)
+	}
+}
+
+func TestNewEnsembleRetrieverOptions(t *testing.T) {
+	two := []Retriever{staticRetriever{}, staticRetriever{}}
+	tests := []struct {
+		name       string
+		retrievers []Retriever
+		options    []EnsembleOption
+	}{
+		{"no retrievers", nil, nil},
+		{"too few weights", two, []EnsembleOption{EnsembleWeights(1)}},
+		{"negative weight", two, []EnsembleOption{EnsembleWeights(1, -1)}},
+		{"negative rank constant", two, []EnsembleOption{RRFConstant(-1)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewEnsembleRetriever(tt.retrievers, tt.options...); err == nil {
+				t.Fatal("expected an error")
+			}
+		})
+	}
+}
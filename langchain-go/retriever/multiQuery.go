@@ -0,0 +1,164 @@
+package retriever
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/William-Bohm/langchain-go/langchain-go/documentStore/documentSchema"
+	"golang.org/x/sync/errgroup"
+)
+
+const DefaultNumQueries = 3
+
+// listMarker matches the numbering or bullet an LLM tends to put in front of each generated query.
+// The space after it is required, so queries like "-5 degrees" or "2.5 GHz" keep their start.
+var listMarker = regexp.MustCompile(`^\s*(\d+[.)]|[-*•])\s+`)
+
+// PredictChain is satisfied by *chains.LLMChain.
+type PredictChain interface {
+	PredictWithContext(ctx context.Context, inputs map[string]interface{}) (string, error)
+}
+
+// TextCallback is satisfied by callbackSchema.BaseCallbackManager.
+type TextCallback interface {
+	OnText(text string, verbose bool, args ...interface{})
+}
+
+// MultiQueryRetriever asks LLMChain for NumQueries rephrasings of the question, runs each of them
+// against Retriever concurrently and returns the union of the results without duplicates. The
+// chain's prompt gets the question as "question" and NumQueries as "n", and must answer with one
+// query per line; MultiQueryPromptTemplate is a prompt that does.
+type MultiQueryRetriever struct {
+	Retriever       Retriever
+	LLMChain        PredictChain
+	NumQueries      int  `comment:"Number of query variants to ask the LLM for."`
+	IncludeOriginal bool `comment:"Also run the user's own question against the retriever."`
+	CallbackManager TextCallback
+	Verbose         bool
+}
+
+func NewMultiQueryRetriever(retriever Retriever, llmChain PredictChain, options ...MultiQueryOption) (*MultiQueryRetriever, error) {
+	if retriever == nil {
+		return nil, errors.New("multi query retriever needs a retriever")
+	}
+	if llmChain == nil {
+		return nil, errors.New("multi query retriever needs an llm chain")
+	}
+	r := &MultiQueryRetriever{
+		Retriever:  retriever,
+		LLMChain:   llmChain,
+		NumQueries: DefaultNumQueries,
+	}
+	for _, option := range options {
+		if err := option(r); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+func (r *MultiQueryRetriever) GetRelevantDocuments(ctx context.Context, query string) ([]documentSchema.Document, error) {
+	queries, err := r.GenerateQueries(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	if r.IncludeOriginal {
+		queries = append(queries, query)
+	}
+
+	results := make([][]documentSchema.Document, len(queries))
+	g, gctx := errgroup.WithContext(ctx)
+	for i, q := range queries {
+		i, q := i, q
+		g.Go(func() error {
+			docs, err := r.Retriever.GetRelevantDocuments(gctx, q)
+			if err != nil {
+				return err
+			}
+			results[i] = docs
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	return uniqueDocuments(results)
+}
+
+// GenerateQueries asks the LLM for variants of query, one per line of its answer.
+func (r *MultiQueryRetriever) GenerateQueries(ctx context.Context, query string) ([]string, error) {
+	output, err := r.LLMChain.PredictWithContext(ctx, map[string]interface{}{
+		"question": query,
+		"n":        r.NumQueries,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var queries []string
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(listMarker.ReplaceAllString(line, ""))
+		if line != "" {
+			queries = append(queries, line)
+		}
+	}
+	if len(queries) > r.NumQueries {
+		queries = queries[:r.NumQueries]
+	}
+	if r.CallbackManager != nil {
+		r.CallbackManager.OnText("Generated queries:\n"+strings.Join(queries, "\n"), r.Verbose)
+	}
+	return queries, nil
+}
+
+// uniqueDocuments flattens results in order, keeping the first of documents with the same content and metadata.
+func uniqueDocuments(results [][]documentSchema.Document) ([]documentSchema.Document, error) {
+	seen := map[string]bool{}
+	unique := []documentSchema.Document{}
+	for _, docs := range results {
+		for _, doc := range docs {
+			metadata, err := json.Marshal(doc.Metadata)
+			if err != nil {
+				return nil, err
+			}
+			key := doc.PageContent + "\x00" + string(metadata)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			unique = append(unique, doc)
+		}
+	}
+	return unique, nil
+}
+
+type MultiQueryOption func(*MultiQueryRetriever) error
+
+func NumQueries(n int) MultiQueryOption {
+	return func(r *MultiQueryRetriever) error {
+		if n <= 0 {
+			return errors.New("number of queries must be positive")
+		}
+		r.NumQueries = n
+		return nil
+	}
+}
+
+func IncludeOriginal(include bool) MultiQueryOption {
+	return func(r *MultiQueryRetriever) error {
+		r.IncludeOriginal = include
+		return nil
+	}
+}
+
+// MultiQueryCallbackManager reports the generated queries through OnText.
+func MultiQueryCallbackManager(callbackManager TextCallback, verbose bool) MultiQueryOption {
+	return func(r *MultiQueryRetriever) error {
+		r.CallbackManager = callbackManager
+		r.Verbose = verbose
+		return nil
+	}
+}
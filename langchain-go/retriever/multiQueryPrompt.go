@@ -0,0 +1,5 @@
+package retriever
+
+// MultiQueryPromptTemplate is a text/template asking for n variants of question, one per line.
+const MultiQueryPromptTemplate = `You are an AI language model assistant. Your task is to generate {{.n}} different versions of the given user question to retrieve relevant documents from a vector database. By generating multiple perspectives on the user question, your goal is to help the user overcome some of the limitations of distance-based similarity search. Provide these alternative questions separated by newlines, without numbering.
+Original question: {{.question}}`
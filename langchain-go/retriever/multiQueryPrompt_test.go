@@ -0,0 +1,21 @@
+package retriever
+
+import (
+	"strings"
+	"testing"
+	"text/template"
+)
+
+func TestMultiQueryPromptTemplate(t *testing.T) {
+	tmpl, err := template.New("prompt").Option("missingkey=error").Parse(MultiQueryPromptTemplate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var prompt strings.Builder
+	if err := tmpl.Execute(&prompt, map[string]interface{}{"n": 3, "question": "What is BM25?"}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(prompt.String(), "generate 3 different versions") || !strings.HasSuffix(prompt.String(), "Original question: What is BM25?") {
+		t.Fatalf("unexpected prompt:\n%s", prompt.String())
+	}
+}
@@ -0,0 +1,114 @@
+package retriever
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/William-Bohm/langchain-go/langchain-go/documentStore/documentSchema"
+)
+
+// recordingCallback records the texts passed to OnText.
+type recordingCallback struct {
+	texts   []string
+	verbose []bool
+}
+
+func (c *recordingCallback) OnText(text string, verbose bool, args ...interface{}) {
+	c.texts = append(c.texts, text)
+	c.verbose = append(c.verbose, verbose)
+}
+
+func TestGenerateQueries(t *testing.T) {
+	tests := []struct {
+		name       string
+		output     string
+		numQueries int
+		want       []string
+	}{
+		{"plain lines", "first query\nsecond query\n", 3, []string{"first query", "second query"}},
+		{"numbered", "1. first query\n2) second query\n  10. third query", 3, []string{"first query", "second query", "third query"}},
+		{"bullets", "- first\n* second\n• third", 3, []string{"first", "second", "third"}},
+		{"blank lines", "\n1. first\n\n   \n2. second\n", 3, []string{"first", "second"}},
+		{"marker without a space is part of the query", "-5 degrees in Oslo\n2.5 GHz processors\n*nix tools", 3, []string{"-5 degrees in Oslo", "2.5 GHz processors", "*nix tools"}},
+		{"number without a marker", "2023 budget report", 3, []string{"2023 budget report"}},
+		{"cut to the number of queries", "1. a\n2. b\n3. c\n4. d", 2, []string{"a", "b"}},
+		{"empty", "", 3, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chain := &fakePredictChain{output: tt.output}
+			r, err := NewMultiQueryRetriever(staticRetriever{}, chain, NumQueries(tt.numQueries))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := r.GenerateQueries(context.Background(), "question")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+			if inputs := chain.inputs[0]; inputs["question"] != "question" || inputs["n"] != tt.numQueries {
+				t.Errorf("chain got %v", inputs)
+			}
+		})
+	}
+}
+
+func TestUniqueDocuments(t *testing.T) {
+	withColor := func(content, color string) documentSchema.Document {
+		return documentSchema.Document{PageContent: content, Metadata: map[string]interface{}{"color": color}}
+	}
+	got, err := uniqueDocuments([][]documentSchema.Document{
+		{withColor("apple", "red"), withColor("pear", "green")},
+		nil,
+		{withColor("pear", "green"), withColor("apple", "green"), withColor("cherry", "red")},
+		{withColor("apple", "red")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []documentSchema.Document{withColor("apple", "red"), withColor("pear", "green"), withColor("apple", "green"), withColor("cherry", "red")}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	if _, err := uniqueDocuments([][]documentSchema.Document{{{PageContent: "x", Metadata: map[string]interface{}{"f": func() {}}}}}); err == nil {
+		t.Error("unmarshalable metadata got no error")
+	}
+}
+
+func TestMultiQueryRetriever(t *testing.T) {
+	callback := &recordingCallback{}
+	chain := &fakePredictChain{output: "1. apples\n2. pears"}
+	r, err := NewMultiQueryRetriever(
+		staticRetriever{docs: docs("apple", "pear", "apple")},
+		chain,
+		IncludeOriginal(true),
+		MultiQueryCallbackManager(callback, true),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := r.GetRelevantDocuments(context.Background(), "fruit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if contents(got) != "apple,pear" {
+		t.Errorf("got %s, want the documents of all three queries without duplicates", contents(got))
+	}
+	if !reflect.DeepEqual(callback.texts, []string{"Generated queries:\napples\npears"}) || !callback.verbose[0] {
+		t.Errorf("callback got %q, %v", callback.texts, callback.verbose)
+	}
+
+	r, _ = NewMultiQueryRetriever(staticRetriever{err: errors.New("down")}, chain)
+	if _, err := r.GetRelevantDocuments(context.Background(), "fruit"); err == nil {
+		t.Error("retriever error was not returned")
+	}
+	r, _ = NewMultiQueryRetriever(staticRetriever{}, &fakePredictChain{err: errors.New("down")})
+	if _, err := r.GetRelevantDocuments(context.Background(), "fruit"); err == nil {
+		t.Error("chain error was not returned")
+	}
+}
@@ -0,0 +1,180 @@
+package retriever
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/William-Bohm/langchain-go/langchain-go/documentStore/documentSchema"
+	"github.com/William-Bohm/langchain-go/langchain-go/vectorstore"
+	"github.com/google/uuid"
+)
+
+const DefaultParentIDKey = "doc_id"
+
+// TextSplitter is the part of textSplitters.TextSplitter the parent document retriever uses.
+type TextSplitter interface {
+	SplitText(text string) []string
+}
+
+// ParentDocstore stores parent documents by id, e.g. documentStore.InMemoryDocstore.
+type ParentDocstore interface {
+	documentSchema.Docstore
+	documentSchema.AddableMixin
+}
+
+// ParentDocumentRetriever embeds small child chunks for precise matching but returns the larger
+// documents they were cut from. Children are indexed in VectorStore with the id of their parent
+// under IDKey, and parents are kept in Docstore.
+type ParentDocumentRetriever struct {
+	VectorStore    vectorstore.VectorStore
+	Docstore       ParentDocstore
+	ChildSplitter  TextSplitter
+	ParentSplitter TextSplitter       `comment:"Splits added documents into parents first. Nil uses whole documents as parents."`
+	IDKey          string             `comment:"Child metadata key holding the parent's id."`
+	K              int                `comment:"Number of child chunks to search for. Fewer parents come back when children share one."`
+	Filter         vectorstore.Filter `comment:"Metadata filter applied to the child search."`
+}
+
+func NewParentDocumentRetriever(vectorStore vectorstore.VectorStore, docstore ParentDocstore, childSplitter TextSplitter, options ...ParentDocumentOption) (*ParentDocumentRetriever, error) {
+	if vectorStore == nil || docstore == nil || childSplitter == nil {
+		return nil, errors.New("parent document retriever needs a vector store, a docstore and a child splitter")
+	}
+	r := &ParentDocumentRetriever{
+		VectorStore:   vectorStore,
+		Docstore:      docstore,
+		ChildSplitter: childSplitter,
+		IDKey:         DefaultParentIDKey,
+		K:             vectorstore.DefaultK,
+	}
+	for _, option := range options {
+		if err := option(r); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// AddDocuments stores docs as parents and indexes their child chunks. ids name the parents and may
+// only be given without a ParentSplitter; nil generates them. Ids already in the docstore are an
+// error. It returns the parent ids.
+func (r *ParentDocumentRetriever) AddDocuments(docs []documentSchema.Document, ids []string) ([]string, error) {
+	parents := docs
+	if r.ParentSplitter != nil {
+		if ids != nil {
+			return nil, errors.New("ids can not be given when documents are split into parents")
+		}
+		parents = splitDocuments(r.ParentSplitter, docs)
+	}
+	if ids == nil {
+		ids = make([]string, len(parents))
+		for i := range ids {
+			ids[i] = uuid.New().String()
+		}
+	}
+	if len(ids) != len(parents) {
+		return nil, fmt.Errorf("got %d ids for %d documents", len(ids), len(parents))
+	}
+	// the docstore refuses ids it already has, which would leave the new children pointing at the
+	// old parent, so nothing is indexed unless every id is new
+	seen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		if seen[id] {
+			return nil, fmt.Errorf("id %s given twice", id)
+		}
+		seen[id] = true
+		if _, existing := r.Docstore.Search(id); existing != nil {
+			return nil, fmt.Errorf("a document with id %s already exists", id)
+		}
+	}
+
+	var children []documentSchema.Document
+	stored := make(map[string]*documentSchema.Document, len(parents))
+	for i, parent := range parents {
+		parent := parent
+		for _, child := range splitDocuments(r.ChildSplitter, []documentSchema.Document{parent}) {
+			child.Metadata[r.IDKey] = ids[i]
+			children = append(children, child)
+		}
+		stored[ids[i]] = &parent
+	}
+	if _, err := r.VectorStore.AddDocuments(children); err != nil {
+		return nil, err
+	}
+	r.Docstore.Add(stored)
+	return ids, nil
+}
+
+func (r *ParentDocumentRetriever) GetRelevantDocuments(ctx context.Context, query string) ([]documentSchema.Document, error) {
+	children, err := r.VectorStore.SimilaritySearchWithFilter(query, r.K, r.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := map[string]bool{}
+	parents := []documentSchema.Document{}
+	for _, child := range children {
+		id, ok := child.Metadata[r.IDKey].(string)
+		if !ok || seen[id] {
+			continue
+		}
+		seen[id] = true
+		// parents missing from the docstore, e.g. removed since indexing, are skipped
+		if _, parent := r.Docstore.Search(id); parent != nil {
+			parents = append(parents, *parent)
+		}
+	}
+	return parents, nil
+}
+
+// splitDocuments splits every document, giving each chunk its own copy of the document's metadata.
+func splitDocuments(splitter TextSplitter, docs []documentSchema.Document) []documentSchema.Document {
+	var chunks []documentSchema.Document
+	for _, doc := range docs {
+		for _, text := range splitter.SplitText(doc.PageContent) {
+			metadata := make(map[string]interface{}, len(doc.Metadata)+1)
+			for key, value := range doc.Metadata {
+				metadata[key] = value
+			}
+			chunks = append(chunks, documentSchema.Document{PageContent: text, Metadata: metadata})
+		}
+	}
+	return chunks
+}
+
+type ParentDocumentOption func(*ParentDocumentRetriever) error
+
+func ParentSplitter(splitter TextSplitter) ParentDocumentOption {
+	return func(r *ParentDocumentRetriever) error {
+		r.ParentSplitter = splitter
+		return nil
+	}
+}
+
+func ParentIDKey(key string) ParentDocumentOption {
+	return func(r *ParentDocumentRetriever) error {
+		if key == "" {
+			return errors.New("id key can not be empty")
+		}
+		r.IDKey = key
+		return nil
+	}
+}
+
+// ChildK sets how many child chunks are searched for.
+func ChildK(k int) ParentDocumentOption {
+	return func(r *ParentDocumentRetriever) error {
+		if k <= 0 {
+			return errors.New("k must be positive")
+		}
+		r.K = k
+		return nil
+	}
+}
+
+func ChildFilter(filter vectorstore.Filter) ParentDocumentOption {
+	return func(r *ParentDocumentRetriever) error {
+		r.Filter = filter
+		return nil
+	}
+}
@@ -0,0 +1,94 @@
+package retriever
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/William-Bohm/langchain-go/langchain-go/documentStore"
+	"github.com/William-Bohm/langchain-go/langchain-go/documentStore/documentSchema"
+	"github.com/William-Bohm/langchain-go/langchain-go/embedding"
+	"github.com/William-Bohm/langchain-go/langchain-go/vectorstore"
+)
+
+// sentenceSplitter cuts text after every ". ".
+type sentenceSplitter struct{}
+
+func (sentenceSplitter) SplitText(text string) []string {
+	var sentences []string
+	for _, sentence := range strings.SplitAfter(text, ". ") {
+		if sentence != "" {
+			sentences = append(sentences, sentence)
+		}
+	}
+	return sentences
+}
+
+func newTestParentDocumentRetriever(t *testing.T) (*ParentDocumentRetriever, *vectorstore.InMemoryVectorStore) {
+	t.Helper()
+	store, err := vectorstore.NewInMemoryVectorStore(embedding.LetterEmbeddings{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	docstore := documentStore.NewInMemoryDocstore(map[string]*documentSchema.Document{})
+	r, err := NewParentDocumentRetriever(store, docstore, sentenceSplitter{}, ChildK(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r, store
+}
+
+func TestParentDocumentRetriever(t *testing.T) {
+	r, _ := newTestParentDocumentRetriever(t)
+	docs := []documentSchema.Document{
+		{PageContent: "Cats purr. Cats nap all day. ", Metadata: map[string]interface{}{"source": "cats"}},
+		{PageContent: "Zebras have stripes. Zebras graze. ", Metadata: map[string]interface{}{"source": "zebras"}},
+	}
+	ids, err := r.AddDocuments(docs, []string{"cats", "zebras"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(ids, ",") != "cats,zebras" {
+		t.Fatalf("got ids %v, want the given ones", ids)
+	}
+
+	got, err := r.GetRelevantDocuments(context.Background(), "zebras graze")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].PageContent != docs[1].PageContent || got[0].Metadata["source"] != "zebras" {
+		t.Fatalf("got %+v, want the whole zebra document", got)
+	}
+}
+
+func TestParentDocumentRetrieverExistingIDs(t *testing.T) {
+	r, store := newTestParentDocumentRetriever(t)
+	if _, err := r.AddDocuments([]documentSchema.Document{{PageContent: "Cats purr. "}}, []string{"cats"}); err != nil {
+		t.Fatal(err)
+	}
+	children := store.Len()
+
+	tests := map[string][]string{
+		"existing id": {"cats", "dogs"},
+		"repeated id": {"dogs", "dogs"},
+	}
+	for name, ids := range tests {
+		t.Run(name, func(t *testing.T) {
+			docs := []documentSchema.Document{{PageContent: "Dogs bark. "}, {PageContent: "Dogs fetch. "}}
+			if _, err := r.AddDocuments(docs, ids); err == nil {
+				t.Fatal("added documents under ids that are not new")
+			}
+			if store.Len() != children {
+				t.Fatalf("%d children indexed before the ids were rejected", store.Len()-children)
+			}
+		})
+	}
+
+	got, err := r.GetRelevantDocuments(context.Background(), "cats purr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].PageContent != "Cats purr. " {
+		t.Fatalf("got %+v, want the original cats document", got)
+	}
+}
@@ -0,0 +1,229 @@
+package retriever
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/William-Bohm/langchain-go/langchain-go/documentStore/documentSchema"
+	"github.com/William-Bohm/langchain-go/langchain-go/vectorstore"
+)
+
+// AttributeInfo describes a metadata attribute the LLM may filter on.
+type AttributeInfo struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Type        string `json:"type"`
+}
+
+// StructuredQuery is a question translated into a semantic query and a metadata filter.
+type StructuredQuery struct {
+	Query  string
+	Filter vectorstore.Filter
+	Limit  int
+}
+
+// SelfQueryRetriever has LLMChain translate a question into a StructuredQuery and runs that against
+// VectorStore. The chain's prompt gets "question", "document_contents" and "attributes", the latter
+// as JSON, and must answer with the JSON object described in SelfQueryPromptTemplate.
+type SelfQueryRetriever struct {
+	VectorStore       vectorstore.VectorStore
+	LLMChain          PredictChain
+	DocumentContents  string          `comment:"Short description of what the documents contain."`
+	MetadataFieldInfo []AttributeInfo `comment:"Metadata attributes the LLM may filter on. Filters on any other attribute are rejected."`
+	K                 int             `comment:"Number of documents to return when the question asks for no specific number."`
+	EnableLimit       bool            `comment:"Let the question set the number of documents, e.g. \"the 3 latest PRs\"."`
+	CallbackManager   TextCallback
+	Verbose           bool
+}
+
+func NewSelfQueryRetriever(vectorStore vectorstore.VectorStore, llmChain PredictChain, documentContents string, metadataFieldInfo []AttributeInfo, options ...SelfQueryOption) (*SelfQueryRetriever, error) {
+	if vectorStore == nil {
+		return nil, errors.New("self query retriever needs a vector store")
+	}
+	if llmChain == nil {
+		return nil, errors.New("self query retriever needs an llm chain")
+	}
+	r := &SelfQueryRetriever{
+		VectorStore:       vectorStore,
+		LLMChain:          llmChain,
+		DocumentContents:  documentContents,
+		MetadataFieldInfo: metadataFieldInfo,
+		K:                 vectorstore.DefaultK,
+	}
+	for _, option := range options {
+		if err := option(r); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+func (r *SelfQueryRetriever) GetRelevantDocuments(ctx context.Context, query string) ([]documentSchema.Document, error) {
+	structured, err := r.StructureQuery(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	k := r.K
+	if r.EnableLimit && structured.Limit > 0 {
+		k = structured.Limit
+	}
+	return r.VectorStore.SimilaritySearchWithFilter(structured.Query, k, structured.Filter)
+}
+
+// StructureQuery asks the LLM to translate query and validates the filter it returns. An empty
+// semantic query falls back to the original question.
+func (r *SelfQueryRetriever) StructureQuery(ctx context.Context, query string) (*StructuredQuery, error) {
+	attributes, err := json.Marshal(r.MetadataFieldInfo)
+	if err != nil {
+		return nil, err
+	}
+	output, err := r.LLMChain.PredictWithContext(ctx, map[string]interface{}{
+		"question":          query,
+		"document_contents": r.DocumentContents,
+		"attributes":        string(attributes),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if r.CallbackManager != nil {
+		r.CallbackManager.OnText("Generated structured query:\n"+output, r.Verbose)
+	}
+
+	structured, err := r.ParseStructuredQuery(output)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(structured.Query) == "" {
+		structured.Query = query
+	}
+	return structured, nil
+}
+
+type structuredQueryJSON struct {
+	Query  string          `json:"query"`
+	Filter json.RawMessage `json:"filter"`
+	Limit  *int            `json:"limit"`
+}
+
+// ParseStructuredQuery reads the LLM's answer, a JSON object optionally wrapped in a markdown code block.
+func (r *SelfQueryRetriever) ParseStructuredQuery(text string) (*StructuredQuery, error) {
+	start := strings.Index(text, "{")
+	end := strings.LastIndex(text, "}")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("no JSON object in structured query output: %q", text)
+	}
+	var parsed structuredQueryJSON
+	if err := json.Unmarshal([]byte(text[start:end+1]), &parsed); err != nil {
+		return nil, fmt.Errorf("invalid structured query output: %w", err)
+	}
+
+	structured := &StructuredQuery{Query: parsed.Query}
+	if parsed.Limit != nil {
+		structured.Limit = *parsed.Limit
+	}
+	if len(parsed.Filter) > 0 && string(parsed.Filter) != "null" {
+		filter, err := r.parseFilter(parsed.Filter)
+		if err != nil {
+			return nil, err
+		}
+		structured.Filter = filter
+	}
+	return structured, nil
+}
+
+type filterJSON struct {
+	Comparator string            `json:"comparator"`
+	Attribute  string            `json:"attribute"`
+	Value      interface{}       `json:"value"`
+	Operator   string            `json:"operator"`
+	Arguments  []json.RawMessage `json:"arguments"`
+}
+
+func (r *SelfQueryRetriever) parseFilter(raw json.RawMessage) (vectorstore.Filter, error) {
+	var f filterJSON
+	if err := json.Unmarshal(raw, &f); err != nil {
+		return nil, fmt.Errorf("invalid filter %s: %w", raw, err)
+	}
+
+	switch {
+	case f.Operator != "":
+		operator := vectorstore.Operator(strings.ToLower(f.Operator))
+		if operator != vectorstore.AND && operator != vectorstore.OR && operator != vectorstore.NOT {
+			return nil, fmt.Errorf("unknown operator %q", f.Operator)
+		}
+		if operator == vectorstore.NOT && len(f.Arguments) != 1 {
+			return nil, errors.New("not takes exactly one argument")
+		}
+		filters := make([]vectorstore.Filter, len(f.Arguments))
+		for i, argument := range f.Arguments {
+			filter, err := r.parseFilter(argument)
+			if err != nil {
+				return nil, err
+			}
+			filters[i] = filter
+		}
+		return vectorstore.Operation{Operator: operator, Filters: filters}, nil
+	case f.Comparator != "":
+		comparator := vectorstore.Comparator(strings.ToLower(f.Comparator))
+		switch comparator {
+		case vectorstore.EQ, vectorstore.NE, vectorstore.GT, vectorstore.GTE, vectorstore.LT, vectorstore.LTE,
+			vectorstore.IN, vectorstore.NIN, vectorstore.EXISTS:
+		default:
+			return nil, fmt.Errorf("unknown comparator %q", f.Comparator)
+		}
+		if !r.knownAttribute(f.Attribute) {
+			return nil, fmt.Errorf("filter on unknown attribute %q, allowed attributes are %s", f.Attribute, r.attributeNames())
+		}
+		return vectorstore.Comparison{Comparator: comparator, Attribute: f.Attribute, Value: f.Value}, nil
+	default:
+		return nil, fmt.Errorf("filter %s is neither a comparison nor an operation", raw)
+	}
+}
+
+func (r *SelfQueryRetriever) knownAttribute(name string) bool {
+	for _, attribute := range r.MetadataFieldInfo {
+		if attribute.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *SelfQueryRetriever) attributeNames() string {
+	names := make([]string, len(r.MetadataFieldInfo))
+	for i, attribute := range r.MetadataFieldInfo {
+		names[i] = attribute.Name
+	}
+	return strings.Join(names, ", ")
+}
+
+type SelfQueryOption func(*SelfQueryRetriever) error
+
+func SelfQueryK(k int) SelfQueryOption {
+	return func(r *SelfQueryRetriever) error {
+		if k <= 0 {
+			return errors.New("k must be positive")
+		}
+		r.K = k
+		return nil
+	}
+}
+
+func EnableLimit(enable bool) SelfQueryOption {
+	return func(r *SelfQueryRetriever) error {
+		r.EnableLimit = enable
+		return nil
+	}
+}
+
+// SelfQueryCallbackManager reports the LLM's structured query through OnText.
+func SelfQueryCallbackManager(callbackManager TextCallback, verbose bool) SelfQueryOption {
+	return func(r *SelfQueryRetriever) error {
+		r.CallbackManager = callbackManager
+		r.Verbose = verbose
+		return nil
+	}
+}
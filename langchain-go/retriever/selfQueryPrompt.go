@@ -0,0 +1,58 @@
+package retriever
+
+// SelfQueryPromptTemplate is a text/template asking for a JSON structured query over
+// document_contents with the metadata attributes in attributes. The JSON braces are literal, since
+// text/template only starts an action at a double brace.
+const SelfQueryPromptTemplate = "Your goal is to structure the user's query to match the request schema provided below.\n\n" +
+	"<< Structured Request Schema >>\n" +
+	"When responding use a markdown code snippet with a JSON object formatted in the following schema:\n\n" +
+	"```json\n" +
+	"{\n" +
+	"    \"query\": string \\ text string to compare to document contents\n" +
+	"    \"filter\": object or null \\ logical condition statement for filtering documents\n" +
+	"    \"limit\": integer or null \\ the number of documents to retrieve\n" +
+	"}\n" +
+	"```\n\n" +
+	"The query string should contain only text that is expected to match the contents of documents. Any conditions in the filter should not be mentioned in the query as well.\n\n" +
+	"A logical condition statement is either a comparison or an operation.\n" +
+	"A comparison is {\"comparator\": comparator, \"attribute\": attribute, \"value\": value}, where comparator is one of eq, ne, gt, gte, lt, lte, in, nin or exists, attribute is the name of a metadata attribute and value is the value to compare it with, a list of values for in and nin.\n" +
+	"An operation is {\"operator\": operator, \"arguments\": [statement, ...]}, where operator is one of and, or or not, and not takes exactly one argument.\n\n" +
+	"Make sure that you only use the comparators and operators listed above and no others.\n" +
+	"Make sure that filters only refer to attributes that exist in the data source.\n" +
+	"Make sure that filters take into account the descriptions and types of the attributes.\n" +
+	"Make sure that you only use filters when needed. If there are no filters that should be applied return null for the filter value.\n" +
+	"Make sure the limit is null unless the user asks for a specific number of documents.\n\n" +
+	"<< Example >>\n" +
+	"Data Source:\n" +
+	"```json\n" +
+	"{\n" +
+	"    \"content\": \"Lyrics of a song\",\n" +
+	"    \"attributes\": [\n" +
+	"        {\"name\": \"artist\", \"description\": \"Name of the song artist\", \"type\": \"string\"},\n" +
+	"        {\"name\": \"length\", \"description\": \"Length of the song in seconds\", \"type\": \"integer\"}\n" +
+	"    ]\n" +
+	"}\n" +
+	"```\n\n" +
+	"User Query:\n" +
+	"What are songs by Taylor Swift or Katy Perry about teenage romance under 3 minutes long?\n\n" +
+	"Structured Request:\n" +
+	"```json\n" +
+	"{\n" +
+	"    \"query\": \"teenager love\",\n" +
+	"    \"filter\": {\"operator\": \"and\", \"arguments\": [\n" +
+	"        {\"comparator\": \"in\", \"attribute\": \"artist\", \"value\": [\"Taylor Swift\", \"Katy Perry\"]},\n" +
+	"        {\"comparator\": \"lt\", \"attribute\": \"length\", \"value\": 180}\n" +
+	"    ]},\n" +
+	"    \"limit\": null\n" +
+	"}\n" +
+	"```\n\n" +
+	"<< Data Source >>\n" +
+	"```json\n" +
+	"{\n" +
+	"    \"content\": \"{{.document_contents}}\",\n" +
+	"    \"attributes\": {{.attributes}}\n" +
+	"}\n" +
+	"```\n\n" +
+	"User Query:\n" +
+	"{{.question}}\n\n" +
+	"Structured Request:\n"
@@ -0,0 +1,43 @@
+package retriever
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"text/template"
+)
+
+func TestSelfQueryPromptTemplate(t *testing.T) {
+	tmpl, err := template.New("prompt").Option("missingkey=error").Parse(SelfQueryPromptTemplate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var prompt strings.Builder
+	err = tmpl.Execute(&prompt, map[string]interface{}{
+		"question":          "Movies by Nolan after 2010",
+		"document_contents": "Brief summary of a movie",
+		"attributes":        `[{"name": "year", "description": "Release year", "type": "integer"}]`,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(prompt.String(), "User Query:\nMovies by Nolan after 2010\n\nStructured Request:\n") {
+		t.Fatalf("question missing from the end of the prompt:\n%s", prompt.String())
+	}
+
+	// every JSON block in the prompt, the filled in data source included, must be valid JSON
+	blocks := strings.Split(prompt.String(), "```json\n")[1:]
+	if len(blocks) != 4 {
+		t.Fatalf("got %d JSON blocks, want 4", len(blocks))
+	}
+	for _, block := range blocks[1:] {
+		block = block[:strings.Index(block, "```")]
+		var value map[string]interface{}
+		if err := json.Unmarshal([]byte(block), &value); err != nil {
+			t.Errorf("invalid JSON block %s: %v", block, err)
+		}
+	}
+	if !strings.Contains(blocks[3], `"content": "Brief summary of a movie"`) {
+		t.Errorf("document contents missing from the data source:\n%s", blocks[3])
+	}
+}
@@ -0,0 +1,144 @@
+package retriever
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/William-Bohm/langchain-go/langchain-go/embedding"
+	"github.com/William-Bohm/langchain-go/langchain-go/vectorstore"
+)
+
+// fakePredictChain answers every prediction with output and records the inputs it got.
+type fakePredictChain struct {
+	output string
+	err    error
+	inputs []map[string]interface{}
+}
+
+func (c *fakePredictChain) PredictWithContext(ctx context.Context, inputs map[string]interface{}) (string, error) {
+	c.inputs = append(c.inputs, inputs)
+	return c.output, c.err
+}
+
+var movieAttributes = []AttributeInfo{
+	{Name: "genre", Description: "The genre of the movie", Type: "string"},
+	{Name: "year", Description: "The year the movie was released", Type: "integer"},
+}
+
+func TestParseStructuredQuery(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  string
+		want    *StructuredQuery
+		wantErr bool
+	}{
+		{
+			"fenced json",
+			"```json\n{\"query\": \"dinosaurs\", \"filter\": {\"comparator\": \"EQ\", \"attribute\": \"genre\", \"value\": \"sci-fi\"}}\n```",
+			&StructuredQuery{Query: "dinosaurs", Filter: vectorstore.Comparison{Comparator: vectorstore.EQ, Attribute: "genre", Value: "sci-fi"}},
+			false,
+		},
+		{"null filter", `{"query": "dinosaurs", "filter": null}`, &StructuredQuery{Query: "dinosaurs"}, false},
+		{"no filter", `{"query": ""}`, &StructuredQuery{}, false},
+		{
+			"nested operations",
+			`{"query": "", "filter": {"operator": "and", "arguments": [
+				{"comparator": "gte", "attribute": "year", "value": 1990},
+				{"operator": "or", "arguments": [
+					{"comparator": "in", "attribute": "genre", "value": ["drama", "comedy"]},
+					{"operator": "not", "arguments": [{"comparator": "exists", "attribute": "genre"}]}
+				]}
+			]}}`,
+			&StructuredQuery{Filter: vectorstore.Operation{Operator: vectorstore.AND, Filters: []vectorstore.Filter{
+				vectorstore.Comparison{Comparator: vectorstore.GTE, Attribute: "year", Value: 1990.0},
+				vectorstore.Operation{Operator: vectorstore.OR, Filters: []vectorstore.Filter{
+					vectorstore.Comparison{Comparator: vectorstore.IN, Attribute: "genre", Value: []interface{}{"drama", "comedy"}},
+					vectorstore.Operation{Operator: vectorstore.NOT, Filters: []vectorstore.Filter{
+						vectorstore.Comparison{Comparator: vectorstore.EXISTS, Attribute: "genre"},
+					}},
+				}},
+			}}},
+			false,
+		},
+		{"limit", `{"query": "dinosaurs", "filter": null, "limit": 2}`, &StructuredQuery{Query: "dinosaurs", Limit: 2}, false},
+		{"unknown attribute", `{"query": "", "filter": {"comparator": "eq", "attribute": "director", "value": "Nolan"}}`, nil, true},
+		{
+			"unknown attribute inside an operation",
+			`{"query": "", "filter": {"operator": "or", "arguments": [{"comparator": "eq", "attribute": "genre", "value": "drama"}, {"comparator": "eq", "attribute": "director", "value": "Nolan"}]}}`,
+			nil,
+			true,
+		},
+		{"bad comparator", `{"query": "", "filter": {"comparator": "like", "attribute": "genre", "value": "dra%"}}`, nil, true},
+		{"bad operator", `{"query": "", "filter": {"operator": "xor", "arguments": []}}`, nil, true},
+		{"not with two arguments", `{"query": "", "filter": {"operator": "not", "arguments": [{"comparator": "exists", "attribute": "genre"}, {"comparator": "exists", "attribute": "year"}]}}`, nil, true},
+		{"neither comparison nor operation", `{"query": "", "filter": {"attribute": "genre"}}`, nil, true},
+		{"no json", "I can not answer that.", nil, true},
+		{"invalid json", `{"query": "dinosaurs",}`, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &SelfQueryRetriever{MetadataFieldInfo: movieAttributes}
+			got, err := r.ParseStructuredQuery(tt.output)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("got %+v, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSelfQueryRetriever(t *testing.T) {
+	store, err := vectorstore.NewInMemoryVectorStore(embedding.LetterEmbeddings{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.AddTexts(
+		[]string{"aaaa", "aaab", "aabb", "abbb", "bbbb"},
+		[]map[string]interface{}{{"genre": "drama"}, {"genre": "drama"}, {"genre": "drama"}, {"genre": "comedy"}, {"genre": "drama"}},
+	)
+	const output = `{"query": "a", "filter": {"comparator": "eq", "attribute": "genre", "value": "drama"}, "limit": 2}`
+
+	tests := []struct {
+		name        string
+		output      string
+		enableLimit bool
+		want        string
+	}{
+		{"limit ignored", output, false, "aaaa,aaab,aabb"},
+		{"limit enabled", output, true, "aaaa,aaab"},
+		{"empty query falls back to the question", `{"query": "", "filter": null}`, false, "bbbb,abbb,aabb"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chain := &fakePredictChain{output: tt.output}
+			r, err := NewSelfQueryRetriever(store, chain, "Movie titles", movieAttributes, SelfQueryK(3), EnableLimit(tt.enableLimit))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := r.GetRelevantDocuments(context.Background(), "bbb")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if contents(got) != tt.want {
+				t.Errorf("got %s, want %s", contents(got), tt.want)
+			}
+			if inputs := chain.inputs[0]; inputs["question"] != "bbb" || inputs["document_contents"] != "Movie titles" {
+				t.Errorf("chain got %v", inputs)
+			}
+		})
+	}
+
+	r, _ := NewSelfQueryRetriever(store, &fakePredictChain{output: `{"query": "a", "filter": {"comparator": "eq", "attribute": "director", "value": "x"}}`}, "", movieAttributes)
+	if _, err := r.GetRelevantDocuments(context.Background(), "bbb"); err == nil {
+		t.Error("filter on an undeclared attribute got no error")
+	}
+}
@@ -0,0 +1,262 @@
+package retriever
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/William-Bohm/langchain-go/langchain-go/documentStore/documentSchema"
+	"github.com/William-Bohm/langchain-go/langchain-go/vectorstore"
+)
+
+const (
+	DefaultDecayRate      = 0.01
+	DefaultTimeWeightedK  = 4
+	DefaultSalientSearchK = 100
+	LastAccessedAtKey     = "last_accessed_at"
+	CreatedAtKey          = "created_at"
+	BufferIndexKey        = "buffer_idx"
+)
+
+// TimeWeightedVectorStoreRetriever ranks documents by
+//
+//	semantic relevance + (1 - DecayRate)^hours since last access + sum of OtherScoreKeys metadata
+//
+// Every document added is also kept in the memory stream, in order, and the copies there have their
+// last_accessed_at updated whenever they are returned.
+type TimeWeightedVectorStoreRetriever struct {
+	VectorStore     vectorstore.VectorStore
+	K               int      `comment:"Number of documents to return."`
+	SearchK         int      `comment:"Number of documents fetched from the vector store before rescoring."`
+	DecayRate       float64  `comment:"Fraction of the recency score lost every hour, between 0 and 1."`
+	OtherScoreKeys  []string `comment:"Numeric metadata keys, e.g. importance, added to the score."`
+	DefaultSalience *float64 `comment:"Relevance given to recent documents the vector search did not return. Nil leaves them out."`
+	mu              sync.Mutex
+	memoryStream    []documentSchema.Document
+	now             func() time.Time
+}
+
+func NewTimeWeightedVectorStoreRetriever(vectorStore vectorstore.VectorStore, options ...TimeWeightedOption) (*TimeWeightedVectorStoreRetriever, error) {
+	if vectorStore == nil {
+		return nil, errors.New("time weighted retriever needs a vector store")
+	}
+	r := &TimeWeightedVectorStoreRetriever{
+		VectorStore: vectorStore,
+		K:           DefaultTimeWeightedK,
+		SearchK:     DefaultSalientSearchK,
+		DecayRate:   DefaultDecayRate,
+		now:         time.Now,
+	}
+	for _, option := range options {
+		if err := option(r); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// AddDocuments stamps docs with created_at, last_accessed_at and their position in the memory stream,
+// unless they already carry timestamps, and adds them to the vector store.
+func (r *TimeWeightedVectorStoreRetriever) AddDocuments(docs []documentSchema.Document) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	stamped := make([]documentSchema.Document, len(docs))
+	for i, doc := range docs {
+		metadata := make(map[string]interface{}, len(doc.Metadata)+3)
+		for key, value := range doc.Metadata {
+			metadata[key] = value
+		}
+		if _, ok := metadata[LastAccessedAtKey]; !ok {
+			metadata[LastAccessedAtKey] = now
+		}
+		if _, ok := metadata[CreatedAtKey]; !ok {
+			metadata[CreatedAtKey] = now
+		}
+		metadata[BufferIndexKey] = len(r.memoryStream) + i
+		stamped[i] = documentSchema.Document{PageContent: doc.PageContent, Metadata: metadata}
+	}
+
+	// the store gets its own metadata maps: in-memory stores keep the maps they are given, and
+	// GetRelevantDocuments updates last_accessed_at in the memory stream while the store may be reading
+	indexed := make([]documentSchema.Document, len(stamped))
+	for i, doc := range stamped {
+		indexed[i] = copyDocument(doc)
+	}
+	ids, err := r.VectorStore.AddDocuments(indexed)
+	if err != nil {
+		return nil, err
+	}
+	r.memoryStream = append(r.memoryStream, stamped...)
+	return ids, nil
+}
+
+func (r *TimeWeightedVectorStoreRetriever) GetRelevantDocuments(ctx context.Context, query string) ([]documentSchema.Document, error) {
+	docs, scores, err := r.VectorStore.SimilaritySearchWithRelevanceScores(query, r.SearchK)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// candidates by memory stream position, with their semantic relevance
+	relevance := map[int]float64{}
+	if r.DefaultSalience != nil {
+		start := len(r.memoryStream) - r.K
+		if start < 0 {
+			start = 0
+		}
+		for i := start; i < len(r.memoryStream); i++ {
+			relevance[i] = *r.DefaultSalience
+		}
+	}
+	for i, doc := range docs {
+		position, ok := toInt(doc.Metadata[BufferIndexKey])
+		if !ok || position < 0 || position >= len(r.memoryStream) {
+			continue
+		}
+		relevance[position] = scores[i]
+	}
+
+	now := r.now()
+	positions := make([]int, 0, len(relevance))
+	combined := map[int]float64{}
+	for position, score := range relevance {
+		positions = append(positions, position)
+		combined[position] = r.combinedScore(r.memoryStream[position], score, now)
+	}
+	sort.Slice(positions, func(a, b int) bool {
+		if combined[positions[a]] != combined[positions[b]] {
+			return combined[positions[a]] > combined[positions[b]]
+		}
+		// newer documents win ties
+		return positions[a] > positions[b]
+	})
+	if len(positions) > r.K {
+		positions = positions[:r.K]
+	}
+
+	result := make([]documentSchema.Document, len(positions))
+	for i, position := range positions {
+		r.memoryStream[position].Metadata[LastAccessedAtKey] = now
+		result[i] = copyDocument(r.memoryStream[position])
+	}
+	return result, nil
+}
+
+// MemoryStream returns copies of every document added, in order, with their current last_accessed_at.
+func (r *TimeWeightedVectorStoreRetriever) MemoryStream() []documentSchema.Document {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stream := make([]documentSchema.Document, len(r.memoryStream))
+	for i, doc := range r.memoryStream {
+		stream[i] = copyDocument(doc)
+	}
+	return stream
+}
+
+func (r *TimeWeightedVectorStoreRetriever) combinedScore(doc documentSchema.Document, relevance float64, now time.Time) float64 {
+	hoursPassed := 0.0
+	if lastAccessed, ok := toTime(doc.Metadata[LastAccessedAtKey]); ok {
+		hoursPassed = math.Max(0, now.Sub(lastAccessed).Hours())
+	}
+	score := math.Pow(1-r.DecayRate, hoursPassed) + relevance
+	for _, key := range r.OtherScoreKeys {
+		if value, ok := toFloat64(doc.Metadata[key]); ok {
+			score += value
+		}
+	}
+	return score
+}
+
+func copyDocument(doc documentSchema.Document) documentSchema.Document {
+	metadata := make(map[string]interface{}, len(doc.Metadata))
+	for key, value := range doc.Metadata {
+		metadata[key] = value
+	}
+	return documentSchema.Document{PageContent: doc.PageContent, Metadata: metadata}
+}
+
+// toTime accepts a time.Time or, for stores that round-trip metadata through JSON, an RFC 3339 string.
+func toTime(v interface{}) (time.Time, bool) {
+	switch t := v.(type) {
+	case time.Time:
+		return t, true
+	case string:
+		parsed, err := time.Parse(time.RFC3339Nano, t)
+		return parsed, err == nil
+	default:
+		return time.Time{}, false
+	}
+}
+
+func toFloat64(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case float32:
+		return float64(n), true
+	case float64:
+		return n, true
+	default:
+		return 0, false
+	}
+}
+
+func toInt(v interface{}) (int, bool) {
+	n, ok := toFloat64(v)
+	return int(n), ok
+}
+
+type TimeWeightedOption func(*TimeWeightedVectorStoreRetriever) error
+
+func DecayRate(rate float64) TimeWeightedOption {
+	return func(r *TimeWeightedVectorStoreRetriever) error {
+		if rate < 0 || rate > 1 {
+			return errors.New("decay rate must be between 0 and 1")
+		}
+		r.DecayRate = rate
+		return nil
+	}
+}
+
+func OtherScoreKeys(keys ...string) TimeWeightedOption {
+	return func(r *TimeWeightedVectorStoreRetriever) error {
+		r.OtherScoreKeys = keys
+		return nil
+	}
+}
+
+func DefaultSalience(salience float64) TimeWeightedOption {
+	return func(r *TimeWeightedVectorStoreRetriever) error {
+		r.DefaultSalience = &salience
+		return nil
+	}
+}
+
+func TimeWeightedK(k int) TimeWeightedOption {
+	return func(r *TimeWeightedVectorStoreRetriever) error {
+		if k <= 0 {
+			return errors.New("k must be positive")
+		}
+		r.K = k
+		return nil
+	}
+}
+
+func SalientSearchK(k int) TimeWeightedOption {
+	return func(r *TimeWeightedVectorStoreRetriever) error {
+		if k <= 0 {
+			return errors.New("search k must be positive")
+		}
+		r.SearchK = k
+		return nil
+	}
+}
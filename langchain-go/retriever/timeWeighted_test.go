@@ -0,0 +1,177 @@
+package retriever
+
+import (
+	"context"
+	"math"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/William-Bohm/langchain-go/langchain-go/documentStore/documentSchema"
+	"github.com/William-Bohm/langchain-go/langchain-go/embedding"
+	"github.com/William-Bohm/langchain-go/langchain-go/vectorstore"
+)
+
+// TestTimeWeightedConcurrentSearch is meant for go test -race: searches update last_accessed_at in
+// the memory stream while other searches read the store's metadata.
+func TestTimeWeightedConcurrentSearch(t *testing.T) {
+	store, err := vectorstore.NewInMemoryVectorStore(embedding.LetterEmbeddings{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := NewTimeWeightedVectorStoreRetriever(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.AddDocuments([]documentSchema.Document{{PageContent: "apple"}, {PageContent: "banana"}, {PageContent: "cherry"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				if _, err := r.GetRelevantDocuments(context.Background(), "apple"); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	docs, err := store.SimilaritySearch("apple", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := docs[0].Metadata[LastAccessedAtKey]; !ok {
+		t.Fatal("indexed copy lost its last_accessed_at")
+	}
+}
+
+// scoredStore returns every added document it has a relevance score for, whatever the query.
+type scoredStore struct {
+	vectorstore.VectorStore
+	added  []documentSchema.Document
+	scores map[string]float64
+}
+
+func (s *scoredStore) AddDocuments(docs []documentSchema.Document) ([]string, error) {
+	s.added = append(s.added, docs...)
+	return make([]string, len(docs)), nil
+}
+
+func (s *scoredStore) SimilaritySearchWithRelevanceScores(query string, k int) ([]documentSchema.Document, []float64, error) {
+	var docs []documentSchema.Document
+	var scores []float64
+	for _, doc := range s.added {
+		if score, ok := s.scores[doc.PageContent]; ok {
+			docs = append(docs, doc)
+			scores = append(scores, score)
+		}
+	}
+	return docs, scores, nil
+}
+
+func newTestTimeWeightedRetriever(t *testing.T, scores map[string]float64, now *time.Time, options ...TimeWeightedOption) *TimeWeightedVectorStoreRetriever {
+	t.Helper()
+	r, err := NewTimeWeightedVectorStoreRetriever(&scoredStore{scores: scores}, options...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.now = func() time.Time { return *now }
+	return r
+}
+
+func TestTimeWeightedRanking(t *testing.T) {
+	start := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+	now := start
+	r := newTestTimeWeightedRetriever(t,
+		map[string]float64{"old": 0.5, "older": 0.9, "important": 0.2, "recent": 0.6},
+		&now, TimeWeightedK(2), OtherScoreKeys("importance"))
+	_, err := r.AddDocuments([]documentSchema.Document{
+		{PageContent: "old", Metadata: map[string]interface{}{LastAccessedAtKey: start.Add(-10 * time.Hour)}},
+		{PageContent: "older", Metadata: map[string]interface{}{LastAccessedAtKey: start.Add(-100 * time.Hour).Format(time.RFC3339Nano)}},
+		{PageContent: "important", Metadata: map[string]interface{}{"importance": 0.5}},
+		{PageContent: "recent"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now = start.Add(time.Hour)
+	stream := r.MemoryStream()
+	want := math.Pow(1-DefaultDecayRate, 11) + 0.5
+	if got := r.combinedScore(stream[0], 0.5, now); math.Abs(got-want) > 1e-9 {
+		t.Errorf("old scored %f, want %f", got, want)
+	}
+	want = math.Pow(1-DefaultDecayRate, 1) + 0.2 + 0.5
+	if got := r.combinedScore(stream[2], 0.2, now); math.Abs(got-want) > 1e-9 {
+		t.Errorf("important scored %f, want %f with its importance", got, want)
+	}
+
+	// scores 1.69 important, 1.59 recent, 1.40 old, 1.26 older
+	docs, err := r.GetRelevantDocuments(context.Background(), "query")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if contents(docs) != "important,recent" {
+		t.Fatalf("got %s, want important,recent", contents(docs))
+	}
+	stream = r.MemoryStream()
+	for i, wantAccessed := range []interface{}{start.Add(-10 * time.Hour), start.Add(-100 * time.Hour).Format(time.RFC3339Nano), now, now} {
+		if stream[i].Metadata[LastAccessedAtKey] != wantAccessed {
+			t.Errorf("%s last accessed at %v, want %v", stream[i].PageContent, stream[i].Metadata[LastAccessedAtKey], wantAccessed)
+		}
+	}
+	if docs[0].Metadata[LastAccessedAtKey] != now {
+		t.Errorf("returned document last accessed at %v, want %v", docs[0].Metadata[LastAccessedAtKey], now)
+	}
+
+	// 200 hours later recency has mostly decayed: older 0.95, important 0.83, recent 0.73, old 0.62
+	now = now.Add(200 * time.Hour)
+	docs, _ = r.GetRelevantDocuments(context.Background(), "query")
+	if contents(docs) != "older,important" {
+		t.Fatalf("after 200 hours got %s, want older,important", contents(docs))
+	}
+
+	// changing the returned copies or the stream copy changes nothing
+	docs[0].Metadata["importance"] = 10.0
+	r.MemoryStream()[0].Metadata["importance"] = 10.0
+	if docs, _ = r.GetRelevantDocuments(context.Background(), "query"); contents(docs) != "older,important" {
+		t.Errorf("got %s after changing copies", contents(docs))
+	}
+}
+
+func TestTimeWeightedDefaultSalience(t *testing.T) {
+	now := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+	added := []documentSchema.Document{{PageContent: "x"}, {PageContent: "y"}, {PageContent: "z"}}
+	tests := []struct {
+		name    string
+		scores  map[string]float64
+		options []TimeWeightedOption
+		want    string
+	}{
+		{"without salience only found documents", map[string]float64{"x": 0.3}, nil, "x"},
+		// the 2 newest documents get relevance 0.8, ties go to the newer one
+		{"salience for the newest", map[string]float64{"x": 0.3}, []TimeWeightedOption{DefaultSalience(0.8)}, "z,y"},
+		{"found documents keep their own relevance", map[string]float64{"x": 0.3, "z": 0.1}, []TimeWeightedOption{DefaultSalience(0.8)}, "y,x"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newTestTimeWeightedRetriever(t, tt.scores, &now, append([]TimeWeightedOption{TimeWeightedK(2)}, tt.options...)...)
+			if _, err := r.AddDocuments(added); err != nil {
+				t.Fatal(err)
+			}
+			docs, err := r.GetRelevantDocuments(context.Background(), "query")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if contents(docs) != tt.want {
+				t.Errorf("got %s, want %s", contents(docs), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,14 @@
+package rootSchema
+
+type AgentAction struct {
+	Tool      string
+	ToolInput interface{}
+	Log       string
+	// MessageLog holds the chat messages that produced this action, for agents that replay them instead of Log
+	MessageLog []BaseMessageInterface
+}
+
+type AgentFinish struct {
+	ReturnValues map[string]interface{}
+	Log          string
+}
@@ -16,11 +16,12 @@ import (
 type MessageType string
 
 const (
-	Human  MessageType = "human"
-	AI     MessageType = "ai"
-	System MessageType = "system"
-	Chat   MessageType = "chat"
-	Base   MessageType = "base"
+	Human    MessageType = "human"
+	AI       MessageType = "ai"
+	System   MessageType = "system"
+	Chat     MessageType = "chat"
+	Function MessageType = "function"
+	Base     MessageType = "base"
 )
 
 type BaseMessageInterface interface {
@@ -106,6 +107,23 @@ func (cm *ChatMessage) Type() MessageType {
 	return Chat
 }
 
+// FunctionMessage carries the result of a function call back to the model, Name is the function that was called.
+type FunctionMessage struct {
+	BaseMessage
+	Name string
+}
+
+func NewFunctionMessage(content string, name string) *FunctionMessage {
+	return &FunctionMessage{
+		BaseMessage: *NewBaseMessage(content, Function),
+		Name:        name,
+	}
+}
+
+func (fm *FunctionMessage) Type() MessageType {
+	return Function
+}
+
 func GetBufferString(messages []BaseMessageInterface, prefixes ...string) (string, error) {
 	humanPrefix := "Human"
 	aiPrefix := "AI"
@@ -130,6 +148,8 @@ func GetBufferString(messages []BaseMessageInterface, prefixes ...string) (strin
 			role = "System"
 		case *ChatMessage:
 			role = msg.Role
+		case *FunctionMessage:
+			role = "Function"
 		default:
 			return "", fmt.Errorf("got unsupported message type: %v", m)
 		}
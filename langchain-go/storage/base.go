@@ -0,0 +1,12 @@
+package storage
+
+// ByteStore is a key-value store for raw bytes, e.g. for caching embeddings.
+type ByteStore interface {
+	// MGet returns the value of every key, nil for keys that are not set.
+	MGet(keys []string) ([][]byte, error)
+	// MSet sets keys[i] to values[i].
+	MSet(keys []string, values [][]byte) error
+	MDelete(keys []string) error
+	// YieldKeys lists the keys starting with prefix; an empty prefix lists all of them.
+	YieldKeys(prefix string) ([]string, error)
+}
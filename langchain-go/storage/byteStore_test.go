@@ -0,0 +1,63 @@
+package storage
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func byteStores(t *testing.T) map[string]ByteStore {
+	t.Helper()
+	local, err := NewLocalFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlite, err := NewSQLiteByteStore(filepath.Join(t.TempDir(), "store.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { sqlite.Close() })
+	return map[string]ByteStore{"inMemory": NewInMemoryByteStore(), "localFile": local, "sqlite": sqlite}
+}
+
+func TestByteStores(t *testing.T) {
+	for name, store := range byteStores(t) {
+		t.Run(name, func(t *testing.T) {
+			value := []byte("value")
+			if err := store.MSet([]string{"a/1", "a/2", "b/1", "empty"}, [][]byte{value, []byte("two"), []byte("three"), {}}); err != nil {
+				t.Fatal(err)
+			}
+			value[0] = 'V'
+
+			values, err := store.MGet([]string{"a/1", "missing", "empty"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(values[0]) != "value" {
+				t.Errorf("got %q, want the value as it was set", values[0])
+			}
+			if values[1] != nil {
+				t.Errorf("missing key got %q, want nil", values[1])
+			}
+			// an empty value is set, so it must not read as nil
+			if values[2] == nil || len(values[2]) != 0 {
+				t.Errorf("empty value got %#v, want an empty non-nil slice", values[2])
+			}
+
+			keys, err := store.YieldKeys("a/")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(keys, []string{"a/1", "a/2"}) {
+				t.Errorf("YieldKeys(a/) = %v", keys)
+			}
+			if err := store.MDelete([]string{"a/1", "missing"}); err != nil {
+				t.Fatal(err)
+			}
+			keys, _ = store.YieldKeys("")
+			if !reflect.DeepEqual(keys, []string{"a/2", "b/1", "empty"}) {
+				t.Errorf("after delete YieldKeys() = %v", keys)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package storage
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+type InMemoryByteStore struct {
+	mu    sync.RWMutex
+	store map[string][]byte
+}
+
+func NewInMemoryByteStore() *InMemoryByteStore {
+	return &InMemoryByteStore{store: map[string][]byte{}}
+}
+
+func (s *InMemoryByteStore) MGet(keys []string) ([][]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	values := make([][]byte, len(keys))
+	for i, key := range keys {
+		if value, ok := s.store[key]; ok {
+			values[i] = copyValue(value)
+		}
+	}
+	return values, nil
+}
+
+func (s *InMemoryByteStore) MSet(keys []string, values [][]byte) error {
+	if len(keys) != len(values) {
+		return fmt.Errorf("got %d values for %d keys", len(values), len(keys))
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, key := range keys {
+		s.store[key] = copyValue(values[i])
+	}
+	return nil
+}
+
+func (s *InMemoryByteStore) MDelete(keys []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, key := range keys {
+		delete(s.store, key)
+	}
+	return nil
+}
+
+func (s *InMemoryByteStore) YieldKeys(prefix string) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var keys []string
+	for key := range s.store {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// copyValue keeps callers from changing stored values. Unlike append to a nil slice it returns an
+// empty value as empty, not nil, which MGet reserves for keys that are not set.
+func copyValue(value []byte) []byte {
+	copied := make([]byte, len(value))
+	copy(copied, value)
+	return copied
+}
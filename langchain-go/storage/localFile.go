@@ -0,0 +1,133 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var validKey = regexp.MustCompile(`^[a-zA-Z0-9_.\-/]+$`)
+
+// LocalFileStore keeps every key in its own file under RootPath. Keys may contain "/" to create
+// subdirectories, but can not leave RootPath.
+type LocalFileStore struct {
+	RootPath string
+}
+
+func NewLocalFileStore(rootPath string) (*LocalFileStore, error) {
+	root, err := filepath.Abs(rootPath)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(root, 0755); err != nil {
+		return nil, err
+	}
+	return &LocalFileStore{RootPath: root}, nil
+}
+
+func (s *LocalFileStore) keyPath(key string) (string, error) {
+	if !validKey.MatchString(key) {
+		return "", fmt.Errorf("invalid key %q, keys may only contain letters, digits, _, ., - and /", key)
+	}
+	path := filepath.Join(s.RootPath, filepath.FromSlash(key))
+	if path == s.RootPath || !strings.HasPrefix(path, s.RootPath+string(filepath.Separator)) {
+		return "", fmt.Errorf("invalid key %q, it points outside of the store", key)
+	}
+	return path, nil
+}
+
+func (s *LocalFileStore) MGet(keys []string) ([][]byte, error) {
+	values := make([][]byte, len(keys))
+	for i, key := range keys {
+		path, err := s.keyPath(key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := os.ReadFile(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		values[i] = value
+	}
+	return values, nil
+}
+
+// MSet writes every value to a temporary file first so a crash never leaves a half-written value behind.
+func (s *LocalFileStore) MSet(keys []string, values [][]byte) error {
+	if len(keys) != len(values) {
+		return fmt.Errorf("got %d values for %d keys", len(values), len(keys))
+	}
+	for i, key := range keys {
+		path, err := s.keyPath(key)
+		if err != nil {
+			return err
+		}
+		dirPath := filepath.Dir(path)
+		if err := os.MkdirAll(dirPath, 0755); err != nil {
+			return err
+		}
+		tmp, err := os.CreateTemp(dirPath, filepath.Base(path)+".*.tmp")
+		if err != nil {
+			return err
+		}
+		if _, err := tmp.Write(values[i]); err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+			return err
+		}
+		if err := tmp.Close(); err != nil {
+			os.Remove(tmp.Name())
+			return err
+		}
+		if err := os.Rename(tmp.Name(), path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *LocalFileStore) MDelete(keys []string) error {
+	for _, key := range keys {
+		path, err := s.keyPath(key)
+		if err != nil {
+			return err
+		}
+		if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *LocalFileStore) YieldKeys(prefix string) ([]string, error) {
+	var keys []string
+	err := filepath.WalkDir(s.RootPath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || strings.HasSuffix(path, ".tmp") {
+			return nil
+		}
+		rel, err := filepath.Rel(s.RootPath, path)
+		if err != nil {
+			return err
+		}
+		if key := filepath.ToSlash(rel); strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
@@ -0,0 +1,51 @@
+package storage
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestLocalFileStoreKeyPath(t *testing.T) {
+	store, err := NewLocalFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{"a", "a"},
+		{"a/b.txt", "a/b.txt"},
+		{"a/./b", "a/b"},
+		{"a/../b", "b"},
+		{"..", ""},
+		{"../x", ""},
+		{"a/../../x", ""},
+		{".", ""},
+		{"a/..", ""},
+		{"/etc/passwd", "etc/passwd"},
+		{"", ""},
+		{`a\b`, ""},
+		{"a b", ""},
+		{"é", ""},
+	}
+	for _, tt := range tests {
+		path, err := store.keyPath(tt.key)
+		if tt.want == "" {
+			if err == nil {
+				t.Errorf("keyPath(%q) = %s, want an error", tt.key, path)
+			}
+			continue
+		}
+		if want := filepath.Join(store.RootPath, filepath.FromSlash(tt.want)); err != nil || path != want {
+			t.Errorf("keyPath(%q) = %s, %v, want %s", tt.key, path, err, want)
+		}
+	}
+
+	if err := store.MSet([]string{"../escaped"}, [][]byte{[]byte("x")}); err == nil {
+		t.Error("MSet wrote outside of the store")
+	}
+	if _, err := store.MGet([]string{"a/../../x"}); err == nil {
+		t.Error("MGet read outside of the store")
+	}
+}
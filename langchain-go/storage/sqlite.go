@@ -0,0 +1,142 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// sqliteMaxVariables is how many keys one query binds. SQLite refuses statements with more than
+// 32766 variables, or 999 when built before 3.32, so MGet queries in chunks of this size.
+const sqliteMaxVariables = 999
+
+const sqliteByteStoreSchema = `CREATE TABLE IF NOT EXISTS byte_store (
+	key TEXT PRIMARY KEY,
+	value BLOB NOT NULL
+)`
+
+// SQLiteByteStore keeps keys and values in a table of an embedded SQLite database.
+type SQLiteByteStore struct {
+	DB *sql.DB
+}
+
+// NewSQLiteByteStore opens (or creates) the database at databasePath.
+func NewSQLiteByteStore(databasePath string) (*SQLiteByteStore, error) {
+	db, err := sql.Open("sqlite3", databasePath)
+	if err != nil {
+		return nil, err
+	}
+	s, err := NewSQLiteByteStoreFromDB(db)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// NewSQLiteByteStoreFromDB uses an already opened database and creates the store table if needed.
+func NewSQLiteByteStoreFromDB(db *sql.DB) (*SQLiteByteStore, error) {
+	if _, err := db.Exec(sqliteByteStoreSchema); err != nil {
+		return nil, err
+	}
+	return &SQLiteByteStore{DB: db}, nil
+}
+
+func (s *SQLiteByteStore) MGet(keys []string) ([][]byte, error) {
+	values := make([][]byte, len(keys))
+	positions := make(map[string][]int, len(keys))
+	var unique []interface{}
+	for i, key := range keys {
+		if _, ok := positions[key]; !ok {
+			unique = append(unique, key)
+		}
+		positions[key] = append(positions[key], i)
+	}
+
+	for start := 0; start < len(unique); start += sqliteMaxVariables {
+		end := start + sqliteMaxVariables
+		if end > len(unique) {
+			end = len(unique)
+		}
+		if err := s.mget(unique[start:end], positions, values); err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+// mget looks up args, which must all be distinct keys, and puts each value found at its positions.
+func (s *SQLiteByteStore) mget(args []interface{}, positions map[string][]int, values [][]byte) error {
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(args)), ",")
+	rows, err := s.DB.Query("SELECT key, value FROM byte_store WHERE key IN ("+placeholders+")", args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var key string
+		var value []byte
+		if err := rows.Scan(&key, &value); err != nil {
+			return err
+		}
+		for _, i := range positions[key] {
+			values[i] = value
+		}
+	}
+	return rows.Err()
+}
+
+func (s *SQLiteByteStore) MSet(keys []string, values [][]byte) error {
+	if len(keys) != len(values) {
+		return fmt.Errorf("got %d values for %d keys", len(values), len(keys))
+	}
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return err
+	}
+	for i, key := range keys {
+		if _, err := tx.Exec("INSERT OR REPLACE INTO byte_store (key, value) VALUES (?, ?)", key, values[i]); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (s *SQLiteByteStore) MDelete(keys []string) error {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if _, err := tx.Exec("DELETE FROM byte_store WHERE key = ?", key); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (s *SQLiteByteStore) YieldKeys(prefix string) ([]string, error) {
+	// substr and length count characters, so both sides of the comparison must come from SQLite
+	rows, err := s.DB.Query("SELECT key FROM byte_store WHERE substr(key, 1, length(?)) = ? ORDER BY key", prefix, prefix)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var keys []string
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
+
+func (s *SQLiteByteStore) Close() error {
+	return s.DB.Close()
+}
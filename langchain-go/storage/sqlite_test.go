@@ -0,0 +1,73 @@
+package storage
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+)
+
+func TestSQLiteByteStoreMGet(t *testing.T) {
+	store, err := NewSQLiteByteStore(filepath.Join(t.TempDir(), "store.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	// more keys than SQLite binds in one statement, every other one stored
+	keys := make([]string, 40000)
+	var storedKeys []string
+	var storedValues [][]byte
+	for i := range keys {
+		keys[i] = fmt.Sprint("key", i)
+		if i%2 == 0 {
+			storedKeys = append(storedKeys, keys[i])
+			storedValues = append(storedValues, []byte(keys[i]))
+		}
+	}
+	if err := store.MSet(storedKeys, storedValues); err != nil {
+		t.Fatal(err)
+	}
+	// repeated keys get the value at every position
+	keys = append(keys, "key0", "missing", "key0")
+
+	values, err := store.MGet(keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(values) != len(keys) {
+		t.Fatalf("got %d values for %d keys", len(values), len(keys))
+	}
+	for i, key := range keys {
+		stored := i < 40000 && i%2 == 0 || key == "key0"
+		switch {
+		case stored && string(values[i]) != key:
+			t.Fatalf("value of %s = %q, want %q", key, values[i], key)
+		case !stored && values[i] != nil:
+			t.Fatalf("value of missing %s = %q, want nil", key, values[i])
+		}
+	}
+
+	if values, err := store.MGet(nil); err != nil || len(values) != 0 {
+		t.Fatalf("MGet(nil) = %v, %v", values, err)
+	}
+}
+
+func TestSQLiteByteStoreYieldKeysMultibytePrefix(t *testing.T) {
+	store, err := NewSQLiteByteStore(filepath.Join(t.TempDir(), "store.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	keys := []string{"é/a", "é/b", "e/c", "éa"}
+	if err := store.MSet(keys, [][]byte{{1}, {2}, {3}, {4}}); err != nil {
+		t.Fatal(err)
+	}
+	// "é/" is 3 bytes but 2 characters
+	got, err := store.YieldKeys("é/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0] != "é/a" || got[1] != "é/b" {
+		t.Errorf("YieldKeys(é/) = %v, want é/a and é/b", got)
+	}
+}
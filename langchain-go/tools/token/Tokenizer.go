@@ -1 +1,13 @@
 package token
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Words lowercases text and splits it on anything that is not a letter or a digit.
+func Words(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
@@ -0,0 +1,113 @@
+package toolSchema
+
+import (
+	"reflect"
+	"sort"
+
+	"github.com/William-Bohm/langchain-go/langchain-go/llm/openai/openaiClient"
+)
+
+// SingleInputArgName is the argument name used for tools without an ArgsSchema, whose input is a plain string.
+const SingleInputArgName = "__arg1"
+
+var jsonSchemaTypes = map[string]bool{
+	"string":  true,
+	"number":  true,
+	"integer": true,
+	"boolean": true,
+	"array":   true,
+	"object":  true,
+}
+
+// FormatToolToOpenAIFunction describes a tool as an OpenAI function definition.
+func FormatToolToOpenAIFunction(tool BaseTool) openaiClient.FunctionDefinition {
+	return openaiClient.FunctionDefinition{
+		Name:        tool.Name,
+		Description: tool.Description,
+		Parameters:  ArgsSchemaToJSONSchema(tool.ArgsSchema),
+	}
+}
+
+func FormatToolsToOpenAIFunctions(tools []BaseTool) []openaiClient.FunctionDefinition {
+	functions := make([]openaiClient.FunctionDefinition, len(tools))
+	for i, tool := range tools {
+		functions[i] = FormatToolToOpenAIFunction(tool)
+	}
+	return functions
+}
+
+// ArgsSchemaToJSONSchema turns a tool ArgsSchema into a JSON schema object.
+//
+// An ArgsSchema that already has "properties" is treated as a JSON schema and returned as-is.
+// Otherwise every key is an argument, and its value is either a JSON schema for that argument,
+// a JSON type name ("string", "integer", ...), or an example value whose Go kind gives the type.
+// A nil or empty ArgsSchema describes a single string argument named SingleInputArgName.
+func ArgsSchemaToJSONSchema(argsSchema map[string]interface{}) map[string]interface{} {
+	if len(argsSchema) == 0 {
+		return map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				SingleInputArgName: map[string]interface{}{"type": "string"},
+			},
+			"required": []string{SingleInputArgName},
+		}
+	}
+
+	if _, ok := argsSchema["properties"]; ok {
+		schema := make(map[string]interface{}, len(argsSchema)+1)
+		for k, v := range argsSchema {
+			schema[k] = v
+		}
+		if _, ok := schema["type"]; !ok {
+			schema["type"] = "object"
+		}
+		return schema
+	}
+
+	properties := make(map[string]interface{}, len(argsSchema))
+	required := make([]string, 0, len(argsSchema))
+	for name, value := range argsSchema {
+		properties[name] = argToJSONSchema(value)
+		required = append(required, name)
+	}
+	sort.Strings(required)
+
+	return map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+		"required":   required,
+	}
+}
+
+func argToJSONSchema(value interface{}) map[string]interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if _, ok := v["type"]; ok {
+			return v
+		}
+		return map[string]interface{}{"type": "object"}
+	case string:
+		if jsonSchemaTypes[v] {
+			return map[string]interface{}{"type": v}
+		}
+		return map[string]interface{}{"type": "string"}
+	case nil:
+		return map[string]interface{}{"type": "string"}
+	}
+
+	switch reflect.TypeOf(value).Kind() {
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array"}
+	case reflect.Map, reflect.Struct:
+		return map[string]interface{}{"type": "object"}
+	default:
+		return map[string]interface{}{"type": "string"}
+	}
+}
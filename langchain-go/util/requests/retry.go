@@ -0,0 +1,92 @@
+package requests
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	initialRetryDelay = time.Second
+	maxRetryDelay     = 60 * time.Second
+)
+
+// DoWithRetries sends the request newRequest builds, retrying failed connections, 429 and 5xx
+// responses up to maxRetries times. Every attempt gets a fresh request, so its body is sent in full
+// again. Waits honour the Retry-After header and back off exponentially otherwise. Other statuses
+// fail at once. The 200 response is returned with its body unread, for the caller to close.
+func DoWithRetries(ctx context.Context, client *http.Client, maxRetries int, newRequest func() (*http.Request, error)) (*http.Response, error) {
+	if maxRetries < 0 {
+		maxRetries = 0
+	}
+	delay := initialRetryDelay
+	for retries := 0; ; retries++ {
+		req, err := newRequest()
+		if err != nil {
+			return nil, err
+		}
+
+		var retryAfter time.Duration
+		var hasRetryAfter bool
+		resp, err := client.Do(req)
+		if err == nil {
+			if resp.StatusCode == http.StatusOK {
+				return resp, nil
+			}
+			body, _ := ioutil.ReadAll(resp.Body)
+			resp.Body.Close()
+			err = errors.New("Request failed with status: " + resp.Status + " " + strings.TrimSpace(string(body)))
+			if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode < 500 {
+				return nil, err
+			}
+			retryAfter, hasRetryAfter = RetryAfter(resp)
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if retries >= maxRetries {
+			return nil, err
+		}
+
+		wait := delay
+		if hasRetryAfter {
+			wait = retryAfter
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+		if delay *= 2; delay > maxRetryDelay {
+			delay = maxRetryDelay
+		}
+	}
+}
+
+// RetryAfter reads how long the server asked to wait from the retry-after-ms or Retry-After header,
+// the latter in seconds or as an HTTP date.
+func RetryAfter(resp *http.Response) (time.Duration, bool) {
+	if ms, err := strconv.ParseFloat(resp.Header.Get("retry-after-ms"), 64); err == nil && ms >= 0 {
+		return time.Duration(ms * float64(time.Millisecond)), true
+	}
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds >= 0 {
+		return time.Duration(seconds * float64(time.Second)), true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		if wait := time.Until(date); wait > 0 {
+			return wait, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
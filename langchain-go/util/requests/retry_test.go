@@ -0,0 +1,136 @@
+package requests
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newStatusServer answers with the given statuses in turn, then 200, and fails the test if a
+// request arrives without the full body.
+func newStatusServer(t *testing.T, body string, statuses ...int) (*httptest.Server, *int32) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, _ := ioutil.ReadAll(r.Body)
+		if string(got) != body {
+			t.Errorf("request body %q, want %q", got, body)
+		}
+		n := atomic.AddInt32(&requests, 1)
+		if int(n) <= len(statuses) {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(statuses[n-1])
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func TestDoWithRetries(t *testing.T) {
+	tests := []struct {
+		name       string
+		statuses   []int
+		maxRetries int
+		wantErr    bool
+		requests   int32
+	}{
+		{"success", nil, 2, false, 1},
+		{"rate limited then success", []int{http.StatusTooManyRequests}, 2, false, 2},
+		{"server errors then success", []int{http.StatusBadGateway, http.StatusServiceUnavailable}, 2, false, 3},
+		{"retries run out", []int{500, 500, 500}, 2, true, 3},
+		{"no retries", []int{http.StatusTooManyRequests}, 0, true, 1},
+		{"bad request is not retried", []int{http.StatusBadRequest}, 2, true, 1},
+		{"unauthorized is not retried", []int{http.StatusUnauthorized}, 2, true, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, requests := newStatusServer(t, "payload", tt.statuses...)
+			resp, err := DoWithRetries(context.Background(), server.Client(), tt.maxRetries, func() (*http.Request, error) {
+				return http.NewRequest(http.MethodPost, server.URL, bytes.NewReader([]byte("payload")))
+			})
+			if tt.wantErr {
+				if err == nil {
+					resp.Body.Close()
+					t.Fatal("got no error")
+				}
+			} else {
+				if err != nil {
+					t.Fatal(err)
+				}
+				body, _ := ioutil.ReadAll(resp.Body)
+				resp.Body.Close()
+				if string(body) != "ok" {
+					t.Fatalf("got body %q, want the unread 200 body", body)
+				}
+			}
+			if *requests != tt.requests {
+				t.Errorf("sent %d requests, want %d", *requests, tt.requests)
+			}
+		})
+	}
+}
+
+func TestDoWithRetriesErrorBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "invalid model", http.StatusBadRequest)
+	}))
+	defer server.Close()
+	_, err := DoWithRetries(context.Background(), server.Client(), 2, func() (*http.Request, error) {
+		return http.NewRequest(http.MethodGet, server.URL, nil)
+	})
+	if err == nil || !strings.Contains(err.Error(), "400") || !strings.Contains(err.Error(), "invalid model") {
+		t.Fatalf("got %v, want the status and the response body", err)
+	}
+}
+
+func TestDoWithRetriesCancelled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := DoWithRetries(ctx, server.Client(), 5, func() (*http.Request, error) {
+		return http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got %v, want the context error", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("returned after %v, want the Retry-After wait cut short", elapsed)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	tests := []struct {
+		name   string
+		header http.Header
+		want   time.Duration
+		ok     bool
+	}{
+		{"none", http.Header{}, 0, false},
+		{"seconds", http.Header{"Retry-After": {"1.5"}}, 1500 * time.Millisecond, true},
+		{"milliseconds win", http.Header{"Retry-After": {"2"}, "Retry-After-Ms": {"250"}}, 250 * time.Millisecond, true},
+		{"past date", http.Header{"Retry-After": {"Wed, 21 Oct 2015 07:28:00 GMT"}}, 0, true},
+		{"garbage", http.Header{"Retry-After": {"soon"}}, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wait, ok := RetryAfter(&http.Response{Header: tt.header})
+			if wait != tt.want || ok != tt.ok {
+				t.Errorf("got %v, %v, want %v, %v", wait, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
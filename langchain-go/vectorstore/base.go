@@ -2,6 +2,8 @@ package vectorstore
 
 import (
 	"errors"
+	"fmt"
+
 	"github.com/William-Bohm/langchain-go/langchain-go/documentStore/documentSchema"
 )
 
@@ -11,53 +13,185 @@ type VectorStore interface {
 	AddTexts([]string, []map[string]interface{}) ([]string, error)
 	AddDocuments([]documentSchema.Document) ([]string, error)
 	SimilaritySearch(string, int) ([]documentSchema.Document, error)
-	SimilaritySearchWithRelevanceScores(string, int) ([]documentSchema.Document, float64, error)
+	SimilaritySearchWithFilter(string, int, Filter) ([]documentSchema.Document, error)
+	SimilaritySearchWithRelevanceScores(string, int) ([]documentSchema.Document, []float64, error)
+	SimilaritySearchWithRelevanceScoresWithFilter(string, int, Filter) ([]documentSchema.Document, []float64, error)
 	SimilaritySearchByVector([]float64, int) ([]documentSchema.Document, error)
 	MaxMarginalRelevanceSearch(string, int, int) ([]documentSchema.Document, error)
+	MaxMarginalRelevanceSearchWithFilter(string, int, int, float64, Filter) ([]documentSchema.Document, error)
 	MaxMarginalRelevanceSearchByVector([]float64, int, int) ([]documentSchema.Document, error)
 	FromDocuments([]documentSchema.Document) (VectorStore, error)
 	FromTexts([]string) (VectorStore, error)
 	AsRetriever() (VectorStoreRetriever, error)
 }
 
+const (
+	SearchTypeSimilarity               = "similarity"
+	SearchTypeSimilarityScoreThreshold = "similarity_score_threshold"
+	SearchTypeMMR                      = "mmr"
+)
+
+// RelevanceScoreKey is the metadata key under which similarity searches report each document's
+// relevance score, between 0 and 1. MMR searches do not set it: they rank by relevance traded off
+// against diversity, so a document's relevance alone would not explain its position.
+const RelevanceScoreKey = "relevance_score"
+
 type VectorStoreRetriever struct {
-	VectorStore  VectorStore
-	SearchType   string
-	SearchKwargs map[string]interface{}
+	VectorStore    VectorStore
+	SearchType     string
+	SearchKwargs   map[string]interface{}
+	K              int      `comment:"Number of documents to return."`
+	FetchK         int      `comment:"Number of candidates MMR picks from."`
+	LambdaMult     float64  `comment:"MMR trade-off between relevance (1) and diversity (0)."`
+	ScoreThreshold *float64 `comment:"Minimum relevance score for similarity_score_threshold searches."`
 }
 
-func NewVectorStoreRetriever(vs VectorStore, st string, sk map[string]interface{}) (*VectorStoreRetriever, error) {
-	if st != "similarity" && st != "mmr" {
+// NewVectorStoreRetriever reads k, fetch_k, lambda_mult and score_threshold from sk, then applies
+// options, which take precedence.
+func NewVectorStoreRetriever(vs VectorStore, st string, sk map[string]interface{}, options ...RetrieverOption) (*VectorStoreRetriever, error) {
+	if st != SearchTypeSimilarity && st != SearchTypeSimilarityScoreThreshold && st != SearchTypeMMR {
 		return nil, errors.New("search_type of " + st + " not allowed.")
 	}
+	if sk == nil {
+		sk = map[string]interface{}{}
+	}
 
 	vsr := &VectorStoreRetriever{
 		VectorStore:  vs,
 		SearchType:   st,
 		SearchKwargs: sk,
+		K:            DefaultK,
+		FetchK:       DefaultFetchK,
+		LambdaMult:   DefaultLambdaMult,
+	}
+	if err := vsr.readSearchKwargs(); err != nil {
+		return nil, err
+	}
+	for _, option := range options {
+		if err := option(vsr); err != nil {
+			return nil, err
+		}
+	}
+	if st == SearchTypeSimilarityScoreThreshold && vsr.ScoreThreshold == nil {
+		return nil, errors.New("similarity_score_threshold search needs a score_threshold")
 	}
 	return vsr, nil
 }
 
-func (vsr *VectorStoreRetriever) GetRelevantDocuments(ctx context.Context, query string) ([]documentSchema.Document, error) {
-	var docs []documentSchema.Document
-	var err error
-
-	if vsr.SearchType == "similarity" {
-		docs, err = vsr.VectorStore.SimilaritySearch(query, 0)
-	} else if vsr.SearchType == "mmr" {
-		docs, err = vsr.VectorStore.MaxMarginalRelevanceSearch(query, 0, 0)
-	} else {
-		return nil, errors.New("search_type of " + vsr.SearchType + " not allowed.")
+func (vsr *VectorStoreRetriever) readSearchKwargs() error {
+	if v, ok := vsr.SearchKwargs["k"]; ok {
+		k, ok := toFloat(v)
+		if !ok || k <= 0 {
+			return fmt.Errorf("k must be a positive number, got %v", v)
+		}
+		vsr.K = int(k)
+	}
+	if v, ok := vsr.SearchKwargs["fetch_k"]; ok {
+		fetchK, ok := toFloat(v)
+		if !ok || fetchK <= 0 {
+			return fmt.Errorf("fetch_k must be a positive number, got %v", v)
+		}
+		vsr.FetchK = int(fetchK)
+	}
+	if v, ok := vsr.SearchKwargs["lambda_mult"]; ok {
+		lambda, ok := toFloat(v)
+		if !ok || lambda < 0 || lambda > 1 {
+			return fmt.Errorf("lambda_mult must be between 0 and 1, got %v", v)
+		}
+		vsr.LambdaMult = lambda
 	}
+	if v, ok := vsr.SearchKwargs["score_threshold"]; ok {
+		threshold, ok := toFloat(v)
+		if !ok || threshold < 0 || threshold > 1 {
+			return fmt.Errorf("score_threshold must be between 0 and 1, got %v", v)
+		}
+		vsr.ScoreThreshold = &threshold
+	}
+	return nil
+}
 
+// GetRelevantDocuments searches with SearchType. Similarity searches put each document's score under
+// RelevanceScoreKey; MMR results are returned as stored, without it.
+func (vsr *VectorStoreRetriever) GetRelevantDocuments(ctx context.Context, query string) ([]documentSchema.Document, error) {
+	filter, err := FilterFromKwargs(vsr.SearchKwargs)
 	if err != nil {
 		return nil, err
 	}
 
-	return docs, nil
+	switch vsr.SearchType {
+	case SearchTypeSimilarity, SearchTypeSimilarityScoreThreshold:
+		docs, scores, err := vsr.VectorStore.SimilaritySearchWithRelevanceScoresWithFilter(query, vsr.K, filter)
+		if err != nil {
+			return nil, err
+		}
+		var relevant []documentSchema.Document
+		for i, doc := range docs {
+			if vsr.SearchType == SearchTypeSimilarityScoreThreshold && scores[i] < *vsr.ScoreThreshold {
+				continue
+			}
+			relevant = append(relevant, withRelevanceScore(doc, scores[i]))
+		}
+		return relevant, nil
+	case SearchTypeMMR:
+		return vsr.VectorStore.MaxMarginalRelevanceSearchWithFilter(query, vsr.K, vsr.FetchK, vsr.LambdaMult, filter)
+	default:
+		return nil, errors.New("search_type of " + vsr.SearchType + " not allowed.")
+	}
+}
+
+// withRelevanceScore returns doc with score under RelevanceScoreKey, leaving the original metadata untouched.
+func withRelevanceScore(doc documentSchema.Document, score float64) documentSchema.Document {
+	metadata := make(map[string]interface{}, len(doc.Metadata)+1)
+	for key, value := range doc.Metadata {
+		metadata[key] = value
+	}
+	metadata[RelevanceScoreKey] = score
+	doc.Metadata = metadata
+	return doc
 }
 
 func (vsr *VectorStoreRetriever) AddDocuments(ctx context.Context, docs []documentSchema.Document) ([]string, error) {
 	return vsr.VectorStore.AddDocuments(docs)
 }
+
+type RetrieverOption func(*VectorStoreRetriever) error
+
+func K(k int) RetrieverOption {
+	return func(vsr *VectorStoreRetriever) error {
+		if k <= 0 {
+			return errors.New("k must be positive")
+		}
+		vsr.K = k
+		return nil
+	}
+}
+
+func FetchK(fetchK int) RetrieverOption {
+	return func(vsr *VectorStoreRetriever) error {
+		if fetchK <= 0 {
+			return errors.New("fetch_k must be positive")
+		}
+		vsr.FetchK = fetchK
+		return nil
+	}
+}
+
+func LambdaMult(lambda float64) RetrieverOption {
+	return func(vsr *VectorStoreRetriever) error {
+		if lambda < 0 || lambda > 1 {
+			return errors.New("lambda_mult must be between 0 and 1")
+		}
+		vsr.LambdaMult = lambda
+		return nil
+	}
+}
+
+func ScoreThreshold(threshold float64) RetrieverOption {
+	return func(vsr *VectorStoreRetriever) error {
+		if threshold < 0 || threshold > 1 {
+			return errors.New("score_threshold must be between 0 and 1")
+		}
+		vsr.ScoreThreshold = &threshold
+		return nil
+	}
+}
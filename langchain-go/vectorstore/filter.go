@@ -0,0 +1,327 @@
+package vectorstore
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// Filter is a condition on Document.Metadata. In-memory stores evaluate it with Match; stores backed
+// by a database translate it into their own query language with a FilterTranslator.
+type Filter interface {
+	Match(metadata map[string]interface{}) bool
+	Accept(translator FilterTranslator) (interface{}, error)
+}
+
+// FilterTranslator turns a Filter into a store-specific query, e.g. a SQL WHERE clause.
+// Translators call Accept on the children of an Operation to recurse.
+type FilterTranslator interface {
+	VisitComparison(comparison Comparison) (interface{}, error)
+	VisitOperation(operation Operation) (interface{}, error)
+}
+
+// TranslateFilter runs translator over filter. A nil filter translates to nil.
+func TranslateFilter(filter Filter, translator FilterTranslator) (interface{}, error) {
+	if filter == nil {
+		return nil, nil
+	}
+	return filter.Accept(translator)
+}
+
+// MatchFilter reports whether metadata passes filter. A nil filter matches everything.
+func MatchFilter(filter Filter, metadata map[string]interface{}) bool {
+	return filter == nil || filter.Match(metadata)
+}
+
+type Comparator string
+
+const (
+	EQ     Comparator = "eq"
+	NE     Comparator = "ne"
+	GT     Comparator = "gt"
+	GTE    Comparator = "gte"
+	LT     Comparator = "lt"
+	LTE    Comparator = "lte"
+	IN     Comparator = "in"
+	NIN    Comparator = "nin"
+	EXISTS Comparator = "exists"
+)
+
+type Operator string
+
+const (
+	AND Operator = "and"
+	OR  Operator = "or"
+	NOT Operator = "not"
+)
+
+// Comparison compares the metadata value under Attribute with Value. For IN and NIN Value is a
+// []interface{}, for EXISTS it is unused. Ordering comparators work on numbers and strings.
+type Comparison struct {
+	Comparator Comparator
+	Attribute  string
+	Value      interface{}
+}
+
+func (c Comparison) Match(metadata map[string]interface{}) bool {
+	got, ok := metadata[c.Attribute]
+	switch c.Comparator {
+	case EXISTS:
+		return ok
+	case NE:
+		return !ok || !equalValues(got, c.Value)
+	case NIN:
+		return !ok || !containsValue(toInterfaceSlice(c.Value), got)
+	}
+	if !ok {
+		return false
+	}
+	switch c.Comparator {
+	case EQ:
+		return equalValues(got, c.Value)
+	case IN:
+		return containsValue(toInterfaceSlice(c.Value), got)
+	case GT, GTE, LT, LTE:
+		order, ok := compareValues(got, c.Value)
+		if !ok {
+			return false
+		}
+		switch c.Comparator {
+		case GT:
+			return order > 0
+		case GTE:
+			return order >= 0
+		case LT:
+			return order < 0
+		default:
+			return order <= 0
+		}
+	default:
+		return false
+	}
+}
+
+func (c Comparison) Accept(translator FilterTranslator) (interface{}, error) {
+	return translator.VisitComparison(c)
+}
+
+// Operation combines filters. NOT takes exactly one filter.
+type Operation struct {
+	Operator Operator
+	Filters  []Filter
+}
+
+func (o Operation) Match(metadata map[string]interface{}) bool {
+	switch o.Operator {
+	case AND:
+		for _, f := range o.Filters {
+			if !f.Match(metadata) {
+				return false
+			}
+		}
+		return true
+	case OR:
+		for _, f := range o.Filters {
+			if f.Match(metadata) {
+				return true
+			}
+		}
+		return false
+	case NOT:
+		return len(o.Filters) == 1 && !o.Filters[0].Match(metadata)
+	default:
+		return false
+	}
+}
+
+func (o Operation) Accept(translator FilterTranslator) (interface{}, error) {
+	if o.Operator == NOT && len(o.Filters) != 1 {
+		return nil, errors.New("not takes exactly one filter")
+	}
+	return translator.VisitOperation(o)
+}
+
+func Eq(attribute string, value interface{}) Filter {
+	return Comparison{Comparator: EQ, Attribute: attribute, Value: value}
+}
+
+func Ne(attribute string, value interface{}) Filter {
+	return Comparison{Comparator: NE, Attribute: attribute, Value: value}
+}
+
+func Gt(attribute string, value interface{}) Filter {
+	return Comparison{Comparator: GT, Attribute: attribute, Value: value}
+}
+
+func Gte(attribute string, value interface{}) Filter {
+	return Comparison{Comparator: GTE, Attribute: attribute, Value: value}
+}
+
+func Lt(attribute string, value interface{}) Filter {
+	return Comparison{Comparator: LT, Attribute: attribute, Value: value}
+}
+
+func Lte(attribute string, value interface{}) Filter {
+	return Comparison{Comparator: LTE, Attribute: attribute, Value: value}
+}
+
+func In(attribute string, values ...interface{}) Filter {
+	return Comparison{Comparator: IN, Attribute: attribute, Value: values}
+}
+
+func Nin(attribute string, values ...interface{}) Filter {
+	return Comparison{Comparator: NIN, Attribute: attribute, Value: values}
+}
+
+func Exists(attribute string) Filter {
+	return Comparison{Comparator: EXISTS, Attribute: attribute}
+}
+
+// Range matches min <= value <= max. A nil bound is left open.
+func Range(attribute string, min interface{}, max interface{}) Filter {
+	var filters []Filter
+	if min != nil {
+		filters = append(filters, Gte(attribute, min))
+	}
+	if max != nil {
+		filters = append(filters, Lte(attribute, max))
+	}
+	if len(filters) == 1 {
+		return filters[0]
+	}
+	return And(filters...)
+}
+
+func And(filters ...Filter) Filter {
+	return Operation{Operator: AND, Filters: filters}
+}
+
+func Or(filters ...Filter) Filter {
+	return Operation{Operator: OR, Filters: filters}
+}
+
+func Not(filter Filter) Filter {
+	return Operation{Operator: NOT, Filters: []Filter{filter}}
+}
+
+// FilterFromMap converts the untyped equality filters of SearchKwargs: every key must equal its
+// value, and a []interface{} value matches any of its elements. Keys are sorted so translators
+// produce stable output. An empty map gives a nil filter.
+func FilterFromMap(filter map[string]interface{}) Filter {
+	if len(filter) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(filter))
+	for key := range filter {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	filters := make([]Filter, len(keys))
+	for i, key := range keys {
+		if values, ok := filter[key].([]interface{}); ok {
+			filters[i] = In(key, values...)
+		} else {
+			filters[i] = Eq(key, filter[key])
+		}
+	}
+	if len(filters) == 1 {
+		return filters[0]
+	}
+	return And(filters...)
+}
+
+// FilterFromKwargs reads the "filter" search kwarg, which may be a Filter or an equality map.
+func FilterFromKwargs(kwargs map[string]interface{}) (Filter, error) {
+	switch f := kwargs["filter"].(type) {
+	case nil:
+		return nil, nil
+	case Filter:
+		return f, nil
+	case map[string]interface{}:
+		return FilterFromMap(f), nil
+	default:
+		return nil, fmt.Errorf("filter must be a vectorstore.Filter or map[string]interface{}, got %T", f)
+	}
+}
+
+func containsValue(options []interface{}, value interface{}) bool {
+	for _, option := range options {
+		if equalValues(option, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func toInterfaceSlice(value interface{}) []interface{} {
+	if values, ok := value.([]interface{}); ok {
+		return values
+	}
+	v := reflect.ValueOf(value)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return []interface{}{value}
+	}
+	values := make([]interface{}, v.Len())
+	for i := range values {
+		values[i] = v.Index(i).Interface()
+	}
+	return values
+}
+
+// equalValues compares numbers by value so an int filter matches a float64 decoded from JSON.
+func equalValues(a, b interface{}) bool {
+	fa, aIsNum := toFloat(a)
+	fb, bIsNum := toFloat(b)
+	if aIsNum && bIsNum {
+		return fa == fb
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// compareValues orders two numbers or two strings. ok is false for anything else.
+func compareValues(a, b interface{}) (order int, ok bool) {
+	fa, aIsNum := toFloat(a)
+	fb, bIsNum := toFloat(b)
+	if aIsNum && bIsNum {
+		switch {
+		case fa < fb:
+			return -1, true
+		case fa > fb:
+			return 1, true
+		default:
+			return 0, true
+		}
+	}
+	sa, aIsString := a.(string)
+	sb, bIsString := b.(string)
+	if aIsString && bIsString {
+		switch {
+		case sa < sb:
+			return -1, true
+		case sa > sb:
+			return 1, true
+		default:
+			return 0, true
+		}
+	}
+	return 0, false
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case float32:
+		return float64(n), true
+	case float64:
+		return n, true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,141 @@
+package vectorstore
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFilterMatch(t *testing.T) {
+	// year as float64, the way it comes back from JSON
+	metadata := map[string]interface{}{"genre": "drama", "year": float64(1994), "rating": 8.5}
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   bool
+	}{
+		{"nil", nil, true},
+		{"eq", Eq("genre", "drama"), true},
+		{"eq other value", Eq("genre", "comedy"), false},
+		{"eq int against float", Eq("year", 1994), true},
+		{"eq missing key", Eq("director", "x"), false},
+		{"ne", Ne("genre", "comedy"), true},
+		{"ne same value", Ne("genre", "drama"), false},
+		{"ne missing key", Ne("director", "x"), true},
+		{"gt", Gt("year", 1990), true},
+		{"gt equal", Gt("year", 1994), false},
+		{"gte equal", Gte("year", 1994), true},
+		{"lt", Lt("rating", 9), true},
+		{"lte equal", Lte("rating", 8.5), true},
+		{"lt strings", Lt("genre", "fantasy"), true},
+		{"gt number against string", Gt("genre", 1), false},
+		{"gt missing key", Gt("director", 1), false},
+		{"in", In("genre", "comedy", "drama"), true},
+		{"in none", In("genre", "comedy", "horror"), false},
+		{"in typed slice", Comparison{Comparator: IN, Attribute: "year", Value: []int{1993, 1994}}, true},
+		{"nin", Nin("genre", "comedy", "horror"), true},
+		{"nin containing", Nin("genre", "comedy", "drama"), false},
+		{"nin missing key", Nin("director", "x"), true},
+		{"exists", Exists("rating"), true},
+		{"exists missing key", Exists("director"), false},
+		{"range", Range("year", 1990, 2000), true},
+		{"range open max", Range("year", 1995, nil), false},
+		{"range open min", Range("year", nil, 1994), true},
+		{"and", And(Eq("genre", "drama"), Gt("rating", 8)), true},
+		{"and one false", And(Eq("genre", "drama"), Gt("rating", 9)), false},
+		{"and empty", And(), true},
+		{"or", Or(Eq("genre", "comedy"), Gt("rating", 8)), true},
+		{"or all false", Or(Eq("genre", "comedy"), Gt("rating", 9)), false},
+		{"or empty", Or(), false},
+		{"not", Not(Eq("genre", "comedy")), true},
+		{"not true", Not(Eq("genre", "drama")), false},
+		{"not two filters", Operation{Operator: NOT, Filters: []Filter{Eq("genre", "comedy"), Eq("genre", "horror")}}, false},
+		{"nested", Or(And(Eq("genre", "comedy"), Exists("rating")), Not(Lt("year", 1990))), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MatchFilter(tt.filter, metadata); got != tt.want {
+				t.Errorf("MatchFilter(%+v) = %v, want %v", tt.filter, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterFromKwargs(t *testing.T) {
+	tests := []struct {
+		name    string
+		kwargs  map[string]interface{}
+		want    Filter
+		wantErr bool
+	}{
+		{"absent", map[string]interface{}{}, nil, false},
+		{"filter", map[string]interface{}{"filter": Eq("a", 1)}, Eq("a", 1), false},
+		{"empty map", map[string]interface{}{"filter": map[string]interface{}{}}, nil, false},
+		{"one key", map[string]interface{}{"filter": map[string]interface{}{"a": 1}}, Eq("a", 1), false},
+		{
+			"keys sorted, list is in",
+			map[string]interface{}{"filter": map[string]interface{}{"b": []interface{}{1, 2}, "a": "x"}},
+			And(Eq("a", "x"), In("b", 1, 2)),
+			false,
+		},
+		{"wrong type", map[string]interface{}{"filter": "a = 1"}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FilterFromKwargs(tt.kwargs)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, want error %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPgFilterTranslator(t *testing.T) {
+	tests := []struct {
+		name     string
+		filter   Filter
+		want     string
+		wantArgs []interface{}
+	}{
+		{"eq", Eq("genre", "drama"), `(COALESCE(cmetadata->$1::text = $2::jsonb, FALSE))`, []interface{}{"genre", `"drama"`}},
+		{"ne", Ne("year", 1994), `(NOT COALESCE(cmetadata->$1::text = $2::jsonb, FALSE))`, []interface{}{"year", "1994"}},
+		{"exists", Exists("genre"), `(cmetadata ? $1::text)`, []interface{}{"genre"}},
+		{
+			"in",
+			In("genre", "drama", "comedy"),
+			`(COALESCE(cmetadata->$1::text = ANY($2::jsonb[]), FALSE))`,
+			[]interface{}{"genre", []string{`"drama"`, `"comedy"`}},
+		},
+		{
+			"gt",
+			Gt("year", 1990),
+			`COALESCE(jsonb_typeof(cmetadata->$1::text) = jsonb_typeof($2::jsonb) AND cmetadata->$1::text > $2::jsonb, FALSE)`,
+			[]interface{}{"year", "1990"},
+		},
+		{
+			"and not",
+			And(Exists("a"), Not(Exists("b"))),
+			`((cmetadata ? $1::text) AND (NOT (cmetadata ? $2::text)))`,
+			[]interface{}{"a", "b"},
+		},
+		{"empty or", Or(), `FALSE`, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			translator := &pgFilterTranslator{}
+			got, err := TranslateFilter(tt.filter, translator)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+			if !reflect.DeepEqual(translator.args, tt.wantArgs) {
+				t.Errorf("got args %#v, want %#v", translator.args, tt.wantArgs)
+			}
+		})
+	}
+}
@@ -0,0 +1,384 @@
+package vectorstore
+
+import (
+	"container/heap"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultHNSWM              = 16
+	DefaultHNSWEfConstruction = 200
+	DefaultHNSWEfSearch       = 50
+)
+
+// HNSWIndex is a Hierarchical Navigable Small World graph for approximate nearest neighbour search.
+// Searches run concurrently with each other; inserts and deletes take a write lock. Deleted vectors
+// are tombstoned: they keep routing searches through the graph but are never returned.
+type HNSWIndex struct {
+	M                int              `comment:"Neighbours per node on the upper layers, layer 0 keeps 2*M."`
+	EfConstruction   int              `comment:"Candidate list size while inserting. Higher builds a better graph, slower."`
+	EfSearch         int              `comment:"Candidate list size while searching. Higher gives better recall, slower."`
+	DistanceStrategy DistanceStrategy `comment:"How vectors are compared: cosine, dot_product or l2."`
+	mu               sync.RWMutex
+	nodes            []*hnswNode
+	ids              map[string]int
+	entryPoint       int
+	maxLevel         int
+	levelMult        float64
+	deleted          int
+	rng              *rand.Rand
+}
+
+type hnswNode struct {
+	id        string
+	vector    []float64
+	neighbors [][]int // per layer
+	deleted   bool
+}
+
+func NewHNSWIndex(options ...HNSWOption) (*HNSWIndex, error) {
+	h := &HNSWIndex{
+		M:                DefaultHNSWM,
+		EfConstruction:   DefaultHNSWEfConstruction,
+		EfSearch:         DefaultHNSWEfSearch,
+		DistanceStrategy: Cosine,
+		ids:              make(map[string]int),
+		entryPoint:       -1,
+		rng:              rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+	for _, opt := range options {
+		if err := opt(h); err != nil {
+			return nil, err
+		}
+	}
+	h.levelMult = 1 / math.Log(float64(h.M))
+	return h, nil
+}
+
+// Len returns the number of live (not deleted) vectors.
+func (h *HNSWIndex) Len() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.nodes) - h.deleted
+}
+
+// Add inserts vector under id. Adding an id that already exists replaces its vector.
+func (h *HNSWIndex) Add(id string, vector []float64) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if len(h.nodes) > 0 && len(vector) != len(h.nodes[0].vector) {
+		return fmt.Errorf("vector has dimension %d, index has %d", len(vector), len(h.nodes[0].vector))
+	}
+	if old, ok := h.ids[id]; ok {
+		h.tombstone(old)
+	}
+
+	level := int(math.Floor(-math.Log(1-h.rng.Float64()) * h.levelMult))
+	node := &hnswNode{
+		id:        id,
+		vector:    vector,
+		neighbors: make([][]int, level+1),
+	}
+	idx := len(h.nodes)
+	h.nodes = append(h.nodes, node)
+	h.ids[id] = idx
+
+	if h.entryPoint == -1 {
+		h.entryPoint, h.maxLevel = idx, level
+		return nil
+	}
+
+	entry := h.entryPoint
+	// greedy descent through the layers above the new node's top layer
+	for layer := h.maxLevel; layer > level; layer-- {
+		entry = h.greedyClosest(vector, entry, layer)
+	}
+
+	top := level
+	if top > h.maxLevel {
+		top = h.maxLevel
+	}
+	for layer := top; layer >= 0; layer-- {
+		candidates := h.searchLayer(vector, []int{entry}, h.EfConstruction, layer)
+		neighbors := h.selectNeighbors(candidates, h.M)
+		node.neighbors[layer] = neighbors
+		for _, neighbor := range neighbors {
+			h.connect(neighbor, idx, layer)
+		}
+		entry = candidates[0].idx
+	}
+
+	if level > h.maxLevel {
+		h.entryPoint, h.maxLevel = idx, level
+	}
+	return nil
+}
+
+// Delete tombstones id. It reports whether the id was in the index.
+func (h *HNSWIndex) Delete(id string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	idx, ok := h.ids[id]
+	if !ok {
+		return false
+	}
+	h.tombstone(idx)
+	return true
+}
+
+func (h *HNSWIndex) tombstone(idx int) {
+	node := h.nodes[idx]
+	if node.deleted {
+		return
+	}
+	node.deleted = true
+	delete(h.ids, node.id)
+	h.deleted++
+}
+
+// Search returns the ids of the k approximate nearest neighbours of query with their
+// DistanceStrategy.Similarity scores, best first.
+func (h *HNSWIndex) Search(query []float64, k int) ([]string, []float64) {
+	return h.SearchWithEf(query, k, h.EfSearch)
+}
+
+// SearchWithEf is Search with a per-query candidate list size instead of EfSearch.
+func (h *HNSWIndex) SearchWithEf(query []float64, k int, ef int) ([]string, []float64) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if h.entryPoint == -1 || k <= 0 {
+		return []string{}, []float64{}
+	}
+	// tombstones take up room in the candidate list, so widen it by the share of deleted nodes
+	if ef < k {
+		ef = k
+	}
+	if h.deleted > 0 {
+		ef += int(float64(ef) * float64(h.deleted) / float64(len(h.nodes)-h.deleted+1))
+	}
+
+	entry := h.entryPoint
+	for layer := h.maxLevel; layer > 0; layer-- {
+		entry = h.greedyClosest(query, entry, layer)
+	}
+	candidates := h.searchLayer(query, []int{entry}, ef, 0)
+
+	ids := make([]string, 0, k)
+	scores := make([]float64, 0, k)
+	for _, c := range candidates {
+		if h.nodes[c.idx].deleted {
+			continue
+		}
+		ids = append(ids, h.nodes[c.idx].id)
+		scores = append(scores, -c.distance)
+		if len(ids) == k {
+			break
+		}
+	}
+	return ids, scores
+}
+
+func (h *HNSWIndex) distance(a, b []float64) float64 {
+	return -h.DistanceStrategy.Similarity(a, b)
+}
+
+func (h *HNSWIndex) greedyClosest(query []float64, entry int, layer int) int {
+	best := entry
+	bestDistance := h.distance(query, h.nodes[entry].vector)
+	for changed := true; changed; {
+		changed = false
+		for _, neighbor := range h.nodes[best].neighbors[layer] {
+			if d := h.distance(query, h.nodes[neighbor].vector); d < bestDistance {
+				best, bestDistance, changed = neighbor, d, true
+			}
+		}
+	}
+	return best
+}
+
+// searchLayer is the beam search of the HNSW paper. It returns up to ef candidates, closest first.
+func (h *HNSWIndex) searchLayer(query []float64, entries []int, ef int, layer int) []hnswCandidate {
+	visited := make(map[int]bool, ef*4)
+	toVisit := &candidateHeap{}        // closest first
+	found := &candidateHeap{max: true} // furthest first
+	for _, e := range entries {
+		c := hnswCandidate{idx: e, distance: h.distance(query, h.nodes[e].vector)}
+		visited[e] = true
+		heap.Push(toVisit, c)
+		heap.Push(found, c)
+	}
+
+	for toVisit.Len() > 0 {
+		current := heap.Pop(toVisit).(hnswCandidate)
+		if found.Len() >= ef && current.distance > found.items[0].distance {
+			break
+		}
+		if layer >= len(h.nodes[current.idx].neighbors) {
+			continue
+		}
+		for _, neighbor := range h.nodes[current.idx].neighbors[layer] {
+			if visited[neighbor] {
+				continue
+			}
+			visited[neighbor] = true
+			d := h.distance(query, h.nodes[neighbor].vector)
+			if found.Len() < ef || d < found.items[0].distance {
+				c := hnswCandidate{idx: neighbor, distance: d}
+				heap.Push(toVisit, c)
+				heap.Push(found, c)
+				if found.Len() > ef {
+					heap.Pop(found)
+				}
+			}
+		}
+	}
+
+	result := make([]hnswCandidate, found.Len())
+	for i := len(result) - 1; i >= 0; i-- {
+		result[i] = heap.Pop(found).(hnswCandidate)
+	}
+	return result
+}
+
+// selectNeighbors keeps candidates that are closer to the new node than to any neighbour already
+// picked, which keeps the graph navigable across clusters, then fills up with the closest rest.
+// candidates must be sorted closest first.
+func (h *HNSWIndex) selectNeighbors(candidates []hnswCandidate, m int) []int {
+	selected := make([]int, 0, m)
+	var skipped []int
+	for _, c := range candidates {
+		if len(selected) >= m {
+			break
+		}
+		keep := true
+		for _, s := range selected {
+			if h.distance(h.nodes[c.idx].vector, h.nodes[s].vector) < c.distance {
+				keep = false
+				break
+			}
+		}
+		if keep {
+			selected = append(selected, c.idx)
+		} else {
+			skipped = append(skipped, c.idx)
+		}
+	}
+	for _, idx := range skipped {
+		if len(selected) >= m {
+			break
+		}
+		selected = append(selected, idx)
+	}
+	return selected
+}
+
+// connect adds a back link from node to neighbor, pruning node's neighbours if it has too many.
+func (h *HNSWIndex) connect(node int, neighbor int, layer int) {
+	n := h.nodes[node]
+	n.neighbors[layer] = append(n.neighbors[layer], neighbor)
+	maxNeighbors := h.M
+	if layer == 0 {
+		maxNeighbors = 2 * h.M
+	}
+	if len(n.neighbors[layer]) <= maxNeighbors {
+		return
+	}
+	candidates := make([]hnswCandidate, len(n.neighbors[layer]))
+	for i, idx := range n.neighbors[layer] {
+		candidates[i] = hnswCandidate{idx: idx, distance: h.distance(n.vector, h.nodes[idx].vector)}
+	}
+	sortCandidates(candidates)
+	n.neighbors[layer] = h.selectNeighbors(candidates, maxNeighbors)
+}
+
+type hnswCandidate struct {
+	idx      int
+	distance float64
+}
+
+// candidateHeap is a min-heap on distance, or a max-heap when max is set.
+type candidateHeap struct {
+	items []hnswCandidate
+	max   bool
+}
+
+func (c *candidateHeap) Len() int { return len(c.items) }
+func (c *candidateHeap) Less(i, j int) bool {
+	if c.max {
+		return c.items[i].distance > c.items[j].distance
+	}
+	return c.items[i].distance < c.items[j].distance
+}
+func (c *candidateHeap) Swap(i, j int)      { c.items[i], c.items[j] = c.items[j], c.items[i] }
+func (c *candidateHeap) Push(x interface{}) { c.items = append(c.items, x.(hnswCandidate)) }
+func (c *candidateHeap) Pop() interface{} {
+	last := c.items[len(c.items)-1]
+	c.items = c.items[:len(c.items)-1]
+	return last
+}
+
+func sortCandidates(candidates []hnswCandidate) {
+	h := &candidateHeap{items: candidates}
+	heap.Init(h)
+	sorted := make([]hnswCandidate, 0, len(candidates))
+	for h.Len() > 0 {
+		sorted = append(sorted, heap.Pop(h).(hnswCandidate))
+	}
+	copy(candidates, sorted)
+}
+
+type HNSWOption func(*HNSWIndex) error
+
+func M(m int) HNSWOption {
+	return func(h *HNSWIndex) error {
+		if m < 2 {
+			return errors.New("M must be at least 2")
+		}
+		h.M = m
+		return nil
+	}
+}
+
+func EfConstruction(ef int) HNSWOption {
+	return func(h *HNSWIndex) error {
+		if ef < 1 {
+			return errors.New("efConstruction must be positive")
+		}
+		h.EfConstruction = ef
+		return nil
+	}
+}
+
+func EfSearch(ef int) HNSWOption {
+	return func(h *HNSWIndex) error {
+		if ef < 1 {
+			return errors.New("efSearch must be positive")
+		}
+		h.EfSearch = ef
+		return nil
+	}
+}
+
+func HNSWDistanceStrategy(strategy DistanceStrategy) HNSWOption {
+	return func(h *HNSWIndex) error {
+		if !ValidDistanceStrategy(strategy) {
+			return fmt.Errorf("unknown distance strategy: %s", strategy)
+		}
+		h.DistanceStrategy = strategy
+		return nil
+	}
+}
+
+// Seed makes level assignment, and so the built graph, reproducible.
+func Seed(seed int64) HNSWOption {
+	return func(h *HNSWIndex) error {
+		h.rng = rand.New(rand.NewSource(seed))
+		return nil
+	}
+}
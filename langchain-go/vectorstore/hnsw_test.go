@@ -0,0 +1,175 @@
+package vectorstore
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+	"testing"
+)
+
+func randomVectors(rng *rand.Rand, n int, dimension int) [][]float64 {
+	vectors := make([][]float64, n)
+	for i := range vectors {
+		vectors[i] = make([]float64, dimension)
+		for j := range vectors[i] {
+			vectors[i][j] = rng.NormFloat64()
+		}
+	}
+	return vectors
+}
+
+func buildHNSW(tb testing.TB, vectors [][]float64, options ...HNSWOption) *HNSWIndex {
+	tb.Helper()
+	index, err := NewHNSWIndex(append([]HNSWOption{Seed(1)}, options...)...)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	for i, vector := range vectors {
+		if err := index.Add(fmt.Sprint(i), vector); err != nil {
+			tb.Fatal(err)
+		}
+	}
+	return index
+}
+
+// exactSearch is the brute-force scan HNSW approximates, returning indexes into vectors.
+func exactSearch(vectors [][]float64, query []float64, k int) []int {
+	scores := make([]float64, len(vectors))
+	for i, vector := range vectors {
+		scores[i] = Cosine.Similarity(query, vector)
+	}
+	return topK(scores, k)
+}
+
+// recall is the share of the exact top k that the index also returned searching with ef.
+func recall(index *HNSWIndex, vectors [][]float64, queries [][]float64, k int, ef int) float64 {
+	hits := 0
+	for _, query := range queries {
+		ids, _ := index.SearchWithEf(query, k, ef)
+		found := make(map[string]bool, len(ids))
+		for _, id := range ids {
+			found[id] = true
+		}
+		for _, i := range exactSearch(vectors, query, k) {
+			if found[fmt.Sprint(i)] {
+				hits++
+			}
+		}
+	}
+	return float64(hits) / float64(len(queries)*k)
+}
+
+func TestHNSWRecall(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	vectors := randomVectors(rng, 2000, 32)
+	queries := randomVectors(rng, 50, 32)
+	index := buildHNSW(t, vectors)
+
+	if got := recall(index, vectors, queries, 10, DefaultHNSWEfSearch); got < 0.9 {
+		t.Fatalf("recall@10 = %.3f, want at least 0.9", got)
+	}
+}
+
+func TestHNSWDelete(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	vectors := randomVectors(rng, 500, 16)
+	index := buildHNSW(t, vectors)
+
+	for i := 0; i < len(vectors); i += 2 {
+		if !index.Delete(fmt.Sprint(i)) {
+			t.Fatalf("id %d not found", i)
+		}
+	}
+	if index.Delete("0") {
+		t.Fatal("deleted id 0 twice")
+	}
+	if index.Len() != 250 {
+		t.Fatalf("Len() = %d, want 250", index.Len())
+	}
+	for _, query := range vectors[:20] {
+		ids, _ := index.Search(query, 10)
+		if len(ids) != 10 {
+			t.Fatalf("got %d results, want 10", len(ids))
+		}
+		for _, id := range ids {
+			var i int
+			fmt.Sscan(id, &i)
+			if i%2 == 0 {
+				t.Fatalf("deleted id %s returned", id)
+			}
+		}
+	}
+}
+
+func TestHNSWConcurrentSearch(t *testing.T) {
+	rng := rand.New(rand.NewSource(3))
+	vectors := randomVectors(rng, 300, 8)
+	index := buildHNSW(t, vectors[:200])
+
+	var wg sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for _, query := range vectors[:50] {
+				index.Search(query, 5)
+			}
+		}()
+	}
+	for i := 200; i < len(vectors); i++ {
+		if err := index.Add(fmt.Sprint(i), vectors[i]); err != nil {
+			t.Error(err)
+		}
+	}
+	wg.Wait()
+	if index.Len() != len(vectors) {
+		t.Fatalf("Len() = %d, want %d", index.Len(), len(vectors))
+	}
+}
+
+const (
+	benchmarkVectors   = 10000
+	benchmarkDimension = 64
+	benchmarkK         = 10
+)
+
+var benchmarkData struct {
+	once    sync.Once
+	vectors [][]float64
+	queries [][]float64
+	index   *HNSWIndex
+}
+
+// benchmarkIndex builds the benchmark index once, so each benchmark only times searching.
+func benchmarkIndex(b *testing.B) ([][]float64, [][]float64, *HNSWIndex) {
+	benchmarkData.once.Do(func() {
+		rng := rand.New(rand.NewSource(4))
+		benchmarkData.vectors = randomVectors(rng, benchmarkVectors, benchmarkDimension)
+		benchmarkData.queries = randomVectors(rng, 100, benchmarkDimension)
+		benchmarkData.index = buildHNSW(b, benchmarkData.vectors)
+	})
+	return benchmarkData.vectors, benchmarkData.queries, benchmarkData.index
+}
+
+// BenchmarkHNSWSearch reports recall@10 against exact search next to the time per query, for a
+// few efSearch values, so the two can be traded off.
+func BenchmarkHNSWSearch(b *testing.B) {
+	vectors, queries, index := benchmarkIndex(b)
+	for _, ef := range []int{DefaultHNSWEfSearch, 100, 200} {
+		b.Run(fmt.Sprintf("ef=%d", ef), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				index.SearchWithEf(queries[i%len(queries)], benchmarkK, ef)
+			}
+			b.StopTimer()
+			b.ReportMetric(recall(index, vectors, queries, benchmarkK, ef), "recall@10")
+		})
+	}
+}
+
+func BenchmarkExactSearch(b *testing.B) {
+	vectors, queries, _ := benchmarkIndex(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		exactSearch(vectors, queries[i%len(queries)], benchmarkK)
+	}
+}
@@ -0,0 +1,384 @@
+package vectorstore
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/William-Bohm/langchain-go/langchain-go/documentStore/documentSchema"
+	"github.com/William-Bohm/langchain-go/langchain-go/embedding/embeddingSchema"
+	"github.com/google/uuid"
+)
+
+// InMemoryVectorStore keeps documents and their embeddings in memory and searches them by brute force.
+type InMemoryVectorStore struct {
+	Embeddings       embeddingSchema.BaseEmbeddings
+	DistanceStrategy DistanceStrategy `comment:"How embeddings are compared: cosine, dot_product or l2."`
+	Index            *HNSWIndex       `comment:"Optional approximate index used for searches without a filter. Nil scans every vector."`
+	mu               sync.RWMutex
+	ids              []string
+	documents        []documentSchema.Document
+	vectors          [][]float64
+	positions        map[string]int // id -> slice position, kept while Index is set
+}
+
+func NewInMemoryVectorStore(embeddings embeddingSchema.BaseEmbeddings, options ...InMemoryOption) (*InMemoryVectorStore, error) {
+	if embeddings == nil {
+		return nil, errors.New("in-memory vector store needs an embeddings model")
+	}
+	s := &InMemoryVectorStore{
+		Embeddings:       embeddings,
+		DistanceStrategy: Cosine,
+	}
+	for _, opt := range options {
+		if err := opt(s); err != nil {
+			return nil, err
+		}
+	}
+	if s.Index != nil {
+		s.Index.DistanceStrategy = s.DistanceStrategy
+	}
+	return s, nil
+}
+
+func (s *InMemoryVectorStore) AddTexts(texts []string, metadatas []map[string]interface{}) ([]string, error) {
+	vectors, err := s.embedTexts(texts, metadatas)
+	if err != nil {
+		return nil, err
+	}
+	ids, docs := newEntries(texts, metadatas)
+	if err := s.add(ids, docs, vectors); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+func (s *InMemoryVectorStore) embedTexts(texts []string, metadatas []map[string]interface{}) ([][]float64, error) {
+	if metadatas != nil && len(metadatas) != len(texts) {
+		return nil, fmt.Errorf("got %d metadatas for %d texts", len(metadatas), len(texts))
+	}
+	if len(texts) == 0 {
+		return [][]float64{}, nil
+	}
+	vectors, err := s.Embeddings.EmbedDocuments(texts)
+	if err != nil {
+		return nil, err
+	}
+	if len(vectors) != len(texts) {
+		return nil, fmt.Errorf("embeddings returned %d vectors for %d texts", len(vectors), len(texts))
+	}
+	return vectors, nil
+}
+
+// newEntries assigns fresh ids and builds the documents for texts. Metadata is copied, so callers
+// can not change stored documents through the maps they passed in.
+func newEntries(texts []string, metadatas []map[string]interface{}) ([]string, []documentSchema.Document) {
+	ids := make([]string, len(texts))
+	docs := make([]documentSchema.Document, len(texts))
+	for i, text := range texts {
+		var metadata map[string]interface{}
+		if metadatas != nil {
+			metadata = metadatas[i]
+		}
+		ids[i] = uuid.New().String()
+		docs[i] = copyDocument(documentSchema.Document{PageContent: text, Metadata: metadata})
+	}
+	return ids, docs
+}
+
+// add stores the entries all or nothing. Every vector is checked against the stored dimension
+// before anything changes, and if Index still rejects one, the ids it already took are deleted again.
+func (s *InMemoryVectorStore) add(ids []string, docs []documentSchema.Document, vectors [][]float64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(vectors) > 0 {
+		dimension := len(vectors[0])
+		if len(s.vectors) > 0 {
+			dimension = len(s.vectors[0])
+		}
+		for _, vector := range vectors {
+			if len(vector) != dimension {
+				return fmt.Errorf("embedding dimension mismatch: got %d, store has %d", len(vector), dimension)
+			}
+		}
+	}
+	if s.Index != nil {
+		for i, id := range ids {
+			if err := s.Index.Add(id, vectors[i]); err != nil {
+				for _, added := range ids[:i] {
+					s.Index.Delete(added)
+				}
+				return err
+			}
+		}
+		for i, id := range ids {
+			s.positions[id] = len(s.ids) + i
+		}
+	}
+	s.ids = append(s.ids, ids...)
+	s.documents = append(s.documents, docs...)
+	s.vectors = append(s.vectors, vectors...)
+	return nil
+}
+
+func (s *InMemoryVectorStore) AddDocuments(docs []documentSchema.Document) ([]string, error) {
+	texts := make([]string, len(docs))
+	metadatas := make([]map[string]interface{}, len(docs))
+	for i, doc := range docs {
+		texts[i] = doc.PageContent
+		metadatas[i] = doc.Metadata
+	}
+	return s.AddTexts(texts, metadatas)
+}
+
+// Delete removes the documents with the given ids. Unknown ids are ignored.
+func (s *InMemoryVectorStore) Delete(ids []string) error {
+	remove := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		remove[id] = true
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	kept := 0
+	for i, id := range s.ids {
+		if remove[id] {
+			continue
+		}
+		s.ids[kept], s.documents[kept], s.vectors[kept] = id, s.documents[i], s.vectors[i]
+		if s.Index != nil {
+			s.positions[id] = kept
+		}
+		kept++
+	}
+	s.ids, s.documents, s.vectors = s.ids[:kept], s.documents[:kept], s.vectors[:kept]
+	if s.Index != nil {
+		for id := range remove {
+			s.Index.Delete(id)
+			delete(s.positions, id)
+		}
+	}
+	return nil
+}
+
+// Len returns the number of stored documents.
+func (s *InMemoryVectorStore) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.ids)
+}
+
+func (s *InMemoryVectorStore) SimilaritySearch(query string, k int) ([]documentSchema.Document, error) {
+	return s.SimilaritySearchWithFilter(query, k, nil)
+}
+
+// SimilaritySearchWithFilter only considers documents whose metadata matches filter.
+func (s *InMemoryVectorStore) SimilaritySearchWithFilter(query string, k int, filter Filter) ([]documentSchema.Document, error) {
+	docs, _, err := s.SimilaritySearchWithScore(query, k, filter)
+	return docs, err
+}
+
+// SimilaritySearchWithScore returns the k most similar documents with their raw DistanceStrategy.Similarity scores.
+func (s *InMemoryVectorStore) SimilaritySearchWithScore(query string, k int, filter Filter) ([]documentSchema.Document, []float64, error) {
+	embedding, err := s.Embeddings.EmbedQuery(query)
+	if err != nil {
+		return nil, nil, err
+	}
+	return s.SimilaritySearchByVectorWithScore(embedding, k, filter)
+}
+
+// SimilaritySearchWithRelevanceScores returns scores between 0 (unrelated) and 1 (most similar).
+func (s *InMemoryVectorStore) SimilaritySearchWithRelevanceScores(query string, k int) ([]documentSchema.Document, []float64, error) {
+	return s.SimilaritySearchWithRelevanceScoresWithFilter(query, k, nil)
+}
+
+func (s *InMemoryVectorStore) SimilaritySearchWithRelevanceScoresWithFilter(query string, k int, filter Filter) ([]documentSchema.Document, []float64, error) {
+	docs, scores, err := s.SimilaritySearchWithScore(query, k, filter)
+	if err != nil {
+		return nil, nil, err
+	}
+	for i, score := range scores {
+		scores[i] = s.DistanceStrategy.RelevanceScore(score)
+	}
+	return docs, scores, nil
+}
+
+func (s *InMemoryVectorStore) SimilaritySearchByVector(embedding []float64, k int) ([]documentSchema.Document, error) {
+	docs, _, err := s.SimilaritySearchByVectorWithScore(embedding, k, nil)
+	return docs, err
+}
+
+func (s *InMemoryVectorStore) SimilaritySearchByVectorWithScore(embedding []float64, k int, filter Filter) ([]documentSchema.Document, []float64, error) {
+	if k <= 0 {
+		k = DefaultK
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	positions, scores := s.search(embedding, k, filter)
+	docs := make([]documentSchema.Document, len(positions))
+	for i, position := range positions {
+		docs[i] = copyDocument(s.documents[position])
+	}
+	return docs, scores, nil
+}
+
+func (s *InMemoryVectorStore) MaxMarginalRelevanceSearch(query string, k int, fetchK int) ([]documentSchema.Document, error) {
+	return s.MaxMarginalRelevanceSearchWithFilter(query, k, fetchK, DefaultLambdaMult, nil)
+}
+
+// MaxMarginalRelevanceSearchWithFilter fetches the fetchK most similar matching documents and picks k
+// of them with MaximalMarginalRelevance.
+func (s *InMemoryVectorStore) MaxMarginalRelevanceSearchWithFilter(query string, k int, fetchK int, lambdaMult float64, filter Filter) ([]documentSchema.Document, error) {
+	embedding, err := s.Embeddings.EmbedQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	return s.MaxMarginalRelevanceSearchByVectorWithFilter(embedding, k, fetchK, lambdaMult, filter)
+}
+
+func (s *InMemoryVectorStore) MaxMarginalRelevanceSearchByVector(embedding []float64, k int, fetchK int) ([]documentSchema.Document, error) {
+	return s.MaxMarginalRelevanceSearchByVectorWithFilter(embedding, k, fetchK, DefaultLambdaMult, nil)
+}
+
+func (s *InMemoryVectorStore) MaxMarginalRelevanceSearchByVectorWithFilter(embedding []float64, k int, fetchK int, lambdaMult float64, filter Filter) ([]documentSchema.Document, error) {
+	if k <= 0 {
+		k = DefaultK
+	}
+	if fetchK <= 0 {
+		fetchK = DefaultFetchK
+	}
+	if fetchK < k {
+		fetchK = k
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	fetched, _ := s.search(embedding, fetchK, filter)
+	fetchedVectors := make([][]float64, len(fetched))
+	for i, position := range fetched {
+		fetchedVectors[i] = s.vectors[position]
+	}
+
+	selected, err := MaximalMarginalRelevance(embedding, fetchedVectors, k, lambdaMult)
+	if err != nil {
+		return nil, err
+	}
+	docs := make([]documentSchema.Document, len(selected))
+	for i, sel := range selected {
+		docs[i] = copyDocument(s.documents[fetched[sel]])
+	}
+	return docs, nil
+}
+
+// FromDocuments returns a new store with the same embeddings and distance strategy holding docs.
+func (s *InMemoryVectorStore) FromDocuments(docs []documentSchema.Document) (VectorStore, error) {
+	store, err := NewInMemoryVectorStore(s.Embeddings, s.sameSettings()...)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := store.AddDocuments(docs); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+func (s *InMemoryVectorStore) FromTexts(texts []string) (VectorStore, error) {
+	store, err := NewInMemoryVectorStore(s.Embeddings, s.sameSettings()...)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := store.AddTexts(texts, nil); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+func (s *InMemoryVectorStore) AsRetriever() (VectorStoreRetriever, error) {
+	retriever, err := NewVectorStoreRetriever(s, SearchTypeSimilarity, map[string]interface{}{})
+	if err != nil {
+		return VectorStoreRetriever{}, err
+	}
+	return *retriever, nil
+}
+
+// search returns the positions of the k documents most similar to embedding that match filter,
+// with their scores, best first. It uses Index when there is no filter. Callers hold s.mu.
+func (s *InMemoryVectorStore) search(embedding []float64, k int, filter Filter) ([]int, []float64) {
+	if s.Index != nil && filter == nil {
+		ids, scores := s.Index.Search(embedding, k)
+		positions := make([]int, len(ids))
+		for i, id := range ids {
+			positions[i] = s.positions[id]
+		}
+		return positions, scores
+	}
+
+	candidates := s.filtered(filter)
+	scores := make([]float64, len(candidates))
+	for i, position := range candidates {
+		scores[i] = s.DistanceStrategy.Similarity(embedding, s.vectors[position])
+	}
+	best := topK(scores, k)
+	positions := make([]int, len(best))
+	bestScores := make([]float64, len(best))
+	for i, b := range best {
+		positions[i] = candidates[b]
+		bestScores[i] = scores[b]
+	}
+	return positions, bestScores
+}
+
+// filtered returns the indexes of the documents matching filter. Callers hold s.mu.
+func (s *InMemoryVectorStore) filtered(filter Filter) []int {
+	idxs := make([]int, 0, len(s.documents))
+	for i, doc := range s.documents {
+		if MatchFilter(filter, doc.Metadata) {
+			idxs = append(idxs, i)
+		}
+	}
+	return idxs
+}
+
+// copyDocument gives callers their own metadata map so they can not change stored documents.
+func copyDocument(doc documentSchema.Document) documentSchema.Document {
+	metadata := make(map[string]interface{}, len(doc.Metadata))
+	for k, v := range doc.Metadata {
+		metadata[k] = v
+	}
+	return documentSchema.Document{PageContent: doc.PageContent, Metadata: metadata}
+}
+
+// sameSettings returns options for a new store configured like s, with a fresh empty index.
+func (s *InMemoryVectorStore) sameSettings() []InMemoryOption {
+	options := []InMemoryOption{WithDistanceStrategy(s.DistanceStrategy)}
+	if s.Index != nil {
+		options = append(options, WithHNSWIndex(M(s.Index.M), EfConstruction(s.Index.EfConstruction), EfSearch(s.Index.EfSearch)))
+	}
+	return options
+}
+
+type InMemoryOption func(*InMemoryVectorStore) error
+
+// WithHNSWIndex searches through a new HNSWIndex using the store's distance strategy instead of
+// scanning every vector. Searches with a metadata filter still scan.
+func WithHNSWIndex(options ...HNSWOption) InMemoryOption {
+	return func(s *InMemoryVectorStore) error {
+		index, err := NewHNSWIndex(options...)
+		if err != nil {
+			return err
+		}
+		s.Index = index
+		s.positions = make(map[string]int)
+		return nil
+	}
+}
+
+func WithDistanceStrategy(strategy DistanceStrategy) InMemoryOption {
+	return func(s *InMemoryVectorStore) error {
+		if !ValidDistanceStrategy(strategy) {
+			return fmt.Errorf("unknown distance strategy: %s", strategy)
+		}
+		s.DistanceStrategy = strategy
+		return nil
+	}
+}
@@ -0,0 +1,169 @@
+package vectorstore
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/William-Bohm/langchain-go/langchain-go/embedding"
+)
+
+// shortEmbeddings embeds like LetterEmbeddings but gives "short" a two dimensional vector.
+type shortEmbeddings struct {
+	embedding.LetterEmbeddings
+}
+
+func (e shortEmbeddings) EmbedDocuments(texts []string) ([][]float64, error) {
+	vectors, _ := e.LetterEmbeddings.EmbedDocuments(texts)
+	for i, text := range texts {
+		if text == "short" {
+			vectors[i] = []float64{1, 0}
+		}
+	}
+	return vectors, nil
+}
+
+func newTestInMemoryStores(t *testing.T) map[string]*InMemoryVectorStore {
+	t.Helper()
+	stores := map[string]*InMemoryVectorStore{}
+	for name, options := range map[string][]InMemoryOption{
+		"scan": nil,
+		"hnsw": {WithHNSWIndex()},
+	} {
+		store, err := NewInMemoryVectorStore(shortEmbeddings{}, options...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		stores[name] = store
+	}
+	return stores
+}
+
+func TestInMemoryVectorStoreSimilaritySearch(t *testing.T) {
+	for name, store := range newTestInMemoryStores(t) {
+		t.Run(name, func(t *testing.T) {
+			_, err := store.AddTexts(
+				[]string{"aaaa", "aabb", "bbbb", "cccc"},
+				[]map[string]interface{}{{"n": 1}, {"n": 2}, {"n": 3}, {"n": 4}},
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			docs, scores, err := store.SimilaritySearchWithScore("aa", 2, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := pageContents(docs); !reflect.DeepEqual(got, []string{"aaaa", "aabb"}) {
+				t.Errorf("got %v", got)
+			}
+			if len(scores) != 2 || math.Abs(scores[0]-1) > 1e-9 || math.Abs(scores[1]-math.Sqrt(0.5)) > 1e-9 {
+				t.Errorf("got scores %v, want 1 and 0.707", scores)
+			}
+
+			docs, _ = store.SimilaritySearch("c", 1)
+			if len(docs) != 1 || docs[0].PageContent != "cccc" || docs[0].Metadata["n"] != 4 {
+				t.Errorf("got %+v", docs)
+			}
+		})
+	}
+}
+
+func TestInMemoryVectorStoreFilter(t *testing.T) {
+	for name, store := range newTestInMemoryStores(t) {
+		t.Run(name, func(t *testing.T) {
+			store.AddTexts(
+				[]string{"aaaa", "aaab", "aabb", "bbbb"},
+				[]map[string]interface{}{{"color": "red"}, {"color": "blue"}, {"color": "red"}, {"color": "blue"}},
+			)
+			docs, err := store.SimilaritySearchWithFilter("aa", 4, Eq("color", "blue"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := pageContents(docs); !reflect.DeepEqual(got, []string{"aaab", "bbbb"}) {
+				t.Errorf("got %v", got)
+			}
+			docs, _ = store.MaxMarginalRelevanceSearchWithFilter("aa", 1, 4, 0.5, Eq("color", "red"))
+			if got := pageContents(docs); !reflect.DeepEqual(got, []string{"aaaa"}) {
+				t.Errorf("mmr got %v", got)
+			}
+		})
+	}
+}
+
+func TestInMemoryVectorStoreMaxMarginalRelevance(t *testing.T) {
+	for name, store := range newTestInMemoryStores(t) {
+		t.Run(name, func(t *testing.T) {
+			store.AddTexts([]string{"aaaa", "aaab", "aabb"}, nil)
+			// aaab is closer to the query, but so close to aaaa that aabb adds more
+			docs, err := store.MaxMarginalRelevanceSearchWithFilter("aa", 2, 3, 0.3, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := pageContents(docs); !reflect.DeepEqual(got, []string{"aaaa", "aabb"}) {
+				t.Errorf("got %v", got)
+			}
+			docs, _ = store.SimilaritySearch("aa", 2)
+			if got := pageContents(docs); !reflect.DeepEqual(got, []string{"aaaa", "aaab"}) {
+				t.Errorf("similarity got %v", got)
+			}
+		})
+	}
+}
+
+func TestInMemoryVectorStoreDelete(t *testing.T) {
+	for name, store := range newTestInMemoryStores(t) {
+		t.Run(name, func(t *testing.T) {
+			ids, _ := store.AddTexts([]string{"aaaa", "aabb", "bbbb"}, nil)
+			if err := store.Delete([]string{ids[0], "unknown"}); err != nil {
+				t.Fatal(err)
+			}
+			if store.Len() != 2 {
+				t.Errorf("got %d documents, want 2", store.Len())
+			}
+			docs, _ := store.SimilaritySearch("aa", 3)
+			if got := pageContents(docs); !reflect.DeepEqual(got, []string{"aabb", "bbbb"}) {
+				t.Errorf("got %v", got)
+			}
+			// positions still line up after the documents moved down
+			store.AddTexts([]string{"cccc"}, nil)
+			docs, _ = store.SimilaritySearch("c", 1)
+			if len(docs) != 1 || docs[0].PageContent != "cccc" {
+				t.Errorf("got %v", pageContents(docs))
+			}
+		})
+	}
+}
+
+func TestInMemoryVectorStoreAddIsAtomic(t *testing.T) {
+	for name, store := range newTestInMemoryStores(t) {
+		t.Run(name, func(t *testing.T) {
+			store.AddTexts([]string{"aaaa"}, nil)
+			if _, err := store.AddTexts([]string{"bbbb", "short"}, nil); err == nil {
+				t.Fatal("adding a vector of another dimension got no error")
+			}
+			if store.Len() != 1 {
+				t.Errorf("got %d documents, want the failed batch left out", store.Len())
+			}
+			docs, _ := store.SimilaritySearch("b", 4)
+			if got := pageContents(docs); !reflect.DeepEqual(got, []string{"aaaa"}) {
+				t.Errorf("got %v", got)
+			}
+		})
+	}
+}
+
+func TestInMemoryVectorStoreCopiesMetadata(t *testing.T) {
+	store, _ := NewInMemoryVectorStore(embedding.LetterEmbeddings{})
+	metadata := map[string]interface{}{"color": "red"}
+	store.AddTexts([]string{"aaaa"}, []map[string]interface{}{metadata})
+	metadata["color"] = "blue"
+
+	docs, _ := store.SimilaritySearch("a", 1)
+	if docs[0].Metadata["color"] != "red" {
+		t.Errorf("changing the added metadata changed the stored document to %v", docs[0].Metadata)
+	}
+	docs[0].Metadata["color"] = "green"
+	if docs, _ := store.SimilaritySearchWithFilter("a", 1, Eq("color", "red")); len(docs) != 1 {
+		t.Error("changing a returned document changed the stored one")
+	}
+}
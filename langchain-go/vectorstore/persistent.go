@@ -0,0 +1,467 @@
+package vectorstore
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/William-Bohm/langchain-go/langchain-go/documentStore/documentSchema"
+	"github.com/William-Bohm/langchain-go/langchain-go/embedding/embeddingSchema"
+)
+
+/*
+ * On-disk format
+ *
+ * The file starts with a header followed by an append-only log of records, all little endian:
+ *
+ *   header: magic "LCVS" | version uint16 | dimension uint32
+ *   record: payload length uint32 | CRC-32 (IEEE) of the length and payload uint32 | payload
+ *   add:    kind 1 | id length uint16 | id | dimension x float32 | document JSON
+ *   delete: kind 2 | id length uint16 | id
+ *
+ * Appends and deletes only add records. Snapshot rewrites the file with just the live documents.
+ * A damaged record with no intact record after it was cut off by a crash and is dropped when the
+ * file is opened. Damage anywhere else, e.g. a flipped byte or a length running past the records
+ * that follow, fails the open, so no records after it are lost.
+ */
+
+const (
+	persistentMagic   = "LCVS"
+	persistentVersion = uint16(2)
+	headerSize        = 4 + 2 + 4
+	recordHeaderSize  = 4 + 4
+
+	recordAdd    = byte(1)
+	recordDelete = byte(2)
+)
+
+// PersistentVectorStore is an InMemoryVectorStore whose contents are kept in a single file, so the
+// index survives restarts without a database. Vectors are stored as float32.
+type PersistentVectorStore struct {
+	*InMemoryVectorStore
+	Path      string
+	Dimension int
+	fileMu    sync.Mutex
+	file      *os.File
+}
+
+// OpenPersistentVectorStore opens the store at path, creating it if it does not exist, and loads it
+// through a memory map. dimension is the embedding size; an existing file with a different dimension
+// is an error. A dimension of 0 takes it from the file, or from the first added vectors for a new file.
+func OpenPersistentVectorStore(path string, embeddings embeddingSchema.BaseEmbeddings, dimension int, options ...InMemoryOption) (*PersistentVectorStore, error) {
+	store, err := NewInMemoryVectorStore(embeddings, options...)
+	if err != nil {
+		return nil, err
+	}
+	if dimension < 0 {
+		return nil, errors.New("dimension can not be negative")
+	}
+	s := &PersistentVectorStore{
+		InMemoryVectorStore: store,
+		Path:                filepath.Clean(path),
+		Dimension:           dimension,
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.Path), 0755); err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(s.Path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.load(file); err != nil {
+		file.Close()
+		return nil, err
+	}
+	s.file = file
+	return s, nil
+}
+
+// load reads the header and replays the log into memory, then leaves the file ready for appends.
+func (s *PersistentVectorStore) load(file *os.File) error {
+	data, unmap, err := mapFile(file)
+	if err != nil {
+		return err
+	}
+	defer unmap()
+
+	if len(data) == 0 {
+		if s.Dimension > 0 {
+			return s.writeHeader(file)
+		}
+		return nil
+	}
+	if len(data) < headerSize || string(data[:4]) != persistentMagic {
+		return fmt.Errorf("%s is not a vector store file", s.Path)
+	}
+	if version := binary.LittleEndian.Uint16(data[4:6]); version != persistentVersion {
+		return fmt.Errorf("unsupported vector store version %d", version)
+	}
+	dimension := int(binary.LittleEndian.Uint32(data[6:10]))
+	if s.Dimension > 0 && s.Dimension != dimension {
+		return fmt.Errorf("embedding dimension mismatch: %s has %d, expected %d", s.Path, dimension, s.Dimension)
+	}
+	s.Dimension = dimension
+
+	var ids []string
+	var docs []documentSchema.Document
+	var vectors [][]float64
+	position := make(map[string]int)
+	deleted := make(map[int]bool)
+
+	offset := headerSize
+	for offset < len(data) {
+		payload, next, ok := readFrame(data, offset)
+		if !ok {
+			if intactRecordAfter(data, offset) {
+				return fmt.Errorf("corrupt vector store %s: damaged record at offset %d", s.Path, offset)
+			}
+			// a partial record left by a crash, drop it so appends start from a clean boundary
+			if err := file.Truncate(int64(offset)); err != nil {
+				return err
+			}
+			break
+		}
+		kind, id, vector, doc, err := s.decodeRecord(payload)
+		if err != nil {
+			return fmt.Errorf("corrupt vector store %s: record at offset %d: %w", s.Path, offset, err)
+		}
+		switch kind {
+		case recordAdd:
+			if i, ok := position[id]; ok {
+				deleted[i] = true
+			}
+			position[id] = len(ids)
+			ids = append(ids, id)
+			docs = append(docs, doc)
+			vectors = append(vectors, vector)
+		case recordDelete:
+			if i, ok := position[id]; ok {
+				deleted[i] = true
+				delete(position, id)
+			}
+		}
+		offset = next
+	}
+
+	for i := range ids {
+		if deleted[i] {
+			continue
+		}
+		if err := s.add([]string{ids[i]}, []documentSchema.Document{docs[i]}, [][]float64{vectors[i]}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readFrame returns the payload of the record at offset and where the next one starts. ok is false
+// when the record runs past the end of data or fails its checksum.
+func readFrame(data []byte, offset int) (payload []byte, next int, ok bool) {
+	if offset+recordHeaderSize > len(data) {
+		return nil, 0, false
+	}
+	length := binary.LittleEndian.Uint32(data[offset : offset+4])
+	if uint64(length) > uint64(len(data)-offset-recordHeaderSize) {
+		return nil, 0, false
+	}
+	next = offset + recordHeaderSize + int(length)
+	if recordChecksum(data[offset:offset+4], data[offset+recordHeaderSize:next]) != binary.LittleEndian.Uint32(data[offset+4:offset+8]) {
+		return nil, 0, false
+	}
+	return data[offset+recordHeaderSize : next], next, true
+}
+
+// intactRecordAfter reports whether an intact record starts anywhere after offset. A crash only cuts
+// off the end of the log, so a damaged record followed by an intact one was corrupted in place.
+func intactRecordAfter(data []byte, offset int) bool {
+	for p := offset + 1; p+recordHeaderSize <= len(data); p++ {
+		if _, _, ok := readFrame(data, p); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func recordChecksum(length []byte, payload []byte) uint32 {
+	checksum := crc32.NewIEEE()
+	checksum.Write(length)
+	checksum.Write(payload)
+	return checksum.Sum32()
+}
+
+// decodeRecord decodes the payload of a record that passed its checksum.
+func (s *PersistentVectorStore) decodeRecord(payload []byte) (kind byte, id string, vector []float64, doc documentSchema.Document, err error) {
+	if len(payload) < 3 {
+		return 0, "", nil, doc, errors.New("record too short")
+	}
+	kind = payload[0]
+	idLen := int(binary.LittleEndian.Uint16(payload[1:3]))
+	offset := 3
+	if offset+idLen > len(payload) {
+		return 0, "", nil, doc, errors.New("id runs past the end of the record")
+	}
+	id = string(payload[offset : offset+idLen])
+	offset += idLen
+	switch kind {
+	case recordDelete:
+		return kind, id, nil, doc, nil
+	case recordAdd:
+	default:
+		return 0, "", nil, doc, fmt.Errorf("unknown record kind %d", kind)
+	}
+
+	if offset+4*s.Dimension > len(payload) {
+		return 0, "", nil, doc, errors.New("vector runs past the end of the record")
+	}
+	vector = make([]float64, s.Dimension)
+	for i := range vector {
+		vector[i] = float64(math.Float32frombits(binary.LittleEndian.Uint32(payload[offset : offset+4])))
+		offset += 4
+	}
+	if err := json.Unmarshal(payload[offset:], &doc); err != nil {
+		return 0, "", nil, doc, fmt.Errorf("document of %q: %w", id, err)
+	}
+	if doc.Metadata == nil {
+		doc.Metadata = map[string]interface{}{}
+	}
+	return kind, id, vector, doc, nil
+}
+
+func (s *PersistentVectorStore) AddTexts(texts []string, metadatas []map[string]interface{}) ([]string, error) {
+	vectors, err := s.embedTexts(texts, metadatas)
+	if err != nil {
+		return nil, err
+	}
+	ids, docs := newEntries(texts, metadatas)
+
+	s.fileMu.Lock()
+	defer s.fileMu.Unlock()
+	if s.Dimension == 0 && len(vectors) > 0 {
+		s.Dimension = len(vectors[0])
+		if err := s.writeHeader(s.file); err != nil {
+			return nil, err
+		}
+	}
+
+	var buf bytes.Buffer
+	for i := range ids {
+		if len(vectors[i]) != s.Dimension {
+			return nil, fmt.Errorf("embedding dimension mismatch: got %d, store has %d", len(vectors[i]), s.Dimension)
+		}
+		// keep memory identical to what a reload would see
+		vectors[i] = toFloat32Precision(vectors[i])
+		if err := s.encodeAdd(&buf, ids[i], vectors[i], docs[i]); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.appendRecords(buf.Bytes()); err != nil {
+		return nil, err
+	}
+	if err := s.add(ids, docs, vectors); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// AddDocuments is redefined so documents go through the persistent AddTexts.
+func (s *PersistentVectorStore) AddDocuments(docs []documentSchema.Document) ([]string, error) {
+	texts := make([]string, len(docs))
+	metadatas := make([]map[string]interface{}, len(docs))
+	for i, doc := range docs {
+		texts[i] = doc.PageContent
+		metadatas[i] = doc.Metadata
+	}
+	return s.AddTexts(texts, metadatas)
+}
+
+// Delete records the deletion on disk before removing the documents from memory.
+func (s *PersistentVectorStore) Delete(ids []string) error {
+	s.fileMu.Lock()
+	defer s.fileMu.Unlock()
+	if s.Dimension == 0 {
+		// nothing was ever written, so there is nothing to delete
+		return nil
+	}
+	var buf bytes.Buffer
+	for _, id := range ids {
+		if err := encodeDelete(&buf, id); err != nil {
+			return err
+		}
+	}
+	if err := s.appendRecords(buf.Bytes()); err != nil {
+		return err
+	}
+	return s.InMemoryVectorStore.Delete(ids)
+}
+
+// Snapshot atomically rewrites the file with only the live documents, dropping deleted records.
+// The new file is written next to the old one and renamed over it, so a crash leaves either
+// the old or the new file intact.
+func (s *PersistentVectorStore) Snapshot() error {
+	s.fileMu.Lock()
+	defer s.fileMu.Unlock()
+	if s.Dimension == 0 {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	buf.Grow(headerSize)
+	s.encodeHeader(&buf)
+	s.mu.RLock()
+	for i, id := range s.ids {
+		if err := s.encodeAdd(&buf, id, s.vectors[i], s.documents[i]); err != nil {
+			s.mu.RUnlock()
+			return err
+		}
+	}
+	s.mu.RUnlock()
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.Path), filepath.Base(s.Path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(buf.Bytes()); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), s.Path); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	file, err := os.OpenFile(s.Path, os.O_RDWR, 0644)
+	if err != nil {
+		return err
+	}
+	s.file.Close()
+	s.file = file
+	return nil
+}
+
+func (s *PersistentVectorStore) Close() error {
+	s.fileMu.Lock()
+	defer s.fileMu.Unlock()
+	return s.file.Close()
+}
+
+// FromDocuments can not pick a file for the new store; open one with OpenPersistentVectorStore instead.
+func (s *PersistentVectorStore) FromDocuments(docs []documentSchema.Document) (VectorStore, error) {
+	return nil, errors.New("a persistent vector store needs a path, use OpenPersistentVectorStore and AddDocuments")
+}
+
+func (s *PersistentVectorStore) FromTexts(texts []string) (VectorStore, error) {
+	return nil, errors.New("a persistent vector store needs a path, use OpenPersistentVectorStore and AddTexts")
+}
+
+func (s *PersistentVectorStore) AsRetriever() (VectorStoreRetriever, error) {
+	retriever, err := NewVectorStoreRetriever(s, SearchTypeSimilarity, map[string]interface{}{})
+	if err != nil {
+		return VectorStoreRetriever{}, err
+	}
+	return *retriever, nil
+}
+
+func (s *PersistentVectorStore) appendRecords(records []byte) error {
+	if len(records) == 0 {
+		return nil
+	}
+	end, err := s.file.Seek(0, io.SeekEnd)
+	if err != nil {
+		return err
+	}
+	if _, err := s.file.WriteAt(records, end); err != nil {
+		// do not leave half a batch behind
+		s.file.Truncate(end)
+		return err
+	}
+	return s.file.Sync()
+}
+
+func (s *PersistentVectorStore) writeHeader(file *os.File) error {
+	var buf bytes.Buffer
+	s.encodeHeader(&buf)
+	if _, err := file.WriteAt(buf.Bytes(), 0); err != nil {
+		return err
+	}
+	return file.Sync()
+}
+
+func (s *PersistentVectorStore) encodeHeader(buf *bytes.Buffer) {
+	buf.WriteString(persistentMagic)
+	binary.Write(buf, binary.LittleEndian, persistentVersion)
+	binary.Write(buf, binary.LittleEndian, uint32(s.Dimension))
+}
+
+func (s *PersistentVectorStore) encodeAdd(buf *bytes.Buffer, id string, vector []float64, doc documentSchema.Document) error {
+	var payload bytes.Buffer
+	if err := encodeRecordID(&payload, recordAdd, id); err != nil {
+		return err
+	}
+	for _, v := range vector {
+		binary.Write(&payload, binary.LittleEndian, math.Float32bits(float32(v)))
+	}
+	docJSON, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	payload.Write(docJSON)
+	return encodeFrame(buf, payload.Bytes())
+}
+
+func encodeDelete(buf *bytes.Buffer, id string) error {
+	var payload bytes.Buffer
+	if err := encodeRecordID(&payload, recordDelete, id); err != nil {
+		return err
+	}
+	return encodeFrame(buf, payload.Bytes())
+}
+
+func encodeRecordID(buf *bytes.Buffer, kind byte, id string) error {
+	if len(id) > math.MaxUint16 {
+		return fmt.Errorf("id too long: %d bytes", len(id))
+	}
+	buf.WriteByte(kind)
+	binary.Write(buf, binary.LittleEndian, uint16(len(id)))
+	buf.WriteString(id)
+	return nil
+}
+
+// encodeFrame writes payload behind its length and checksum.
+func encodeFrame(buf *bytes.Buffer, payload []byte) error {
+	if uint64(len(payload)) > math.MaxUint32 {
+		return fmt.Errorf("record too long: %d bytes", len(payload))
+	}
+	var header [recordHeaderSize]byte
+	binary.LittleEndian.PutUint32(header[:4], uint32(len(payload)))
+	binary.LittleEndian.PutUint32(header[4:], recordChecksum(header[:4], payload))
+	buf.Write(header[:])
+	buf.Write(payload)
+	return nil
+}
+
+func toFloat32Precision(vector []float64) []float64 {
+	rounded := make([]float64, len(vector))
+	for i, v := range vector {
+		rounded[i] = float64(float32(v))
+	}
+	return rounded
+}
@@ -0,0 +1,293 @@
+package vectorstore
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/William-Bohm/langchain-go/langchain-go/documentStore/documentSchema"
+	"github.com/William-Bohm/langchain-go/langchain-go/embedding"
+)
+
+func openTestStore(t *testing.T, path string) *PersistentVectorStore {
+	t.Helper()
+	store, err := OpenPersistentVectorStore(path, embedding.LetterEmbeddings{}, 0)
+	if err != nil {
+		t.Fatalf("open %s: %v", path, err)
+	}
+	return store
+}
+
+func pageContents(docs []documentSchema.Document) []string {
+	contents := make([]string, len(docs))
+	for i, doc := range docs {
+		contents[i] = doc.PageContent
+	}
+	return contents
+}
+
+func TestPersistentVectorStoreReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "store.lcvs")
+	store := openTestStore(t, path)
+	ids, err := store.AddTexts(
+		[]string{"apple", "banana", "cherry"},
+		[]map[string]interface{}{{"color": "red"}, {"color": "yellow"}, {"color": "red"}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Delete(ids[1:2]); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened := openTestStore(t, path)
+	defer reopened.Close()
+	if reopened.Len() != 2 || reopened.Dimension != 26 {
+		t.Fatalf("got %d documents of dimension %d, want 2 of 26", reopened.Len(), reopened.Dimension)
+	}
+	docs, err := reopened.SimilaritySearch("cherry", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(docs) != 1 || docs[0].PageContent != "cherry" || docs[0].Metadata["color"] != "red" {
+		t.Fatalf("got %+v, want cherry with its metadata", docs)
+	}
+	docs, err = reopened.SimilaritySearch("banana", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, content := range pageContents(docs) {
+		if content == "banana" {
+			t.Fatal("deleted document came back after reload")
+		}
+	}
+}
+
+func TestPersistentVectorStoreSnapshot(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "store.lcvs")
+	store := openTestStore(t, path)
+	ids, err := store.AddTexts([]string{"one", "two", "three"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Delete(ids[:2]); err != nil {
+		t.Fatal(err)
+	}
+	before, _ := os.Stat(path)
+	if err := store.Snapshot(); err != nil {
+		t.Fatal(err)
+	}
+	after, _ := os.Stat(path)
+	if after.Size() >= before.Size() {
+		t.Fatalf("snapshot did not shrink the file: %d -> %d bytes", before.Size(), after.Size())
+	}
+	// appends after a snapshot go to the new file
+	if _, err := store.AddTexts([]string{"four"}, nil); err != nil {
+		t.Fatal(err)
+	}
+	store.Close()
+
+	reopened := openTestStore(t, path)
+	defer reopened.Close()
+	docs, err := reopened.SimilaritySearch("three", 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(pageContents(docs), ","); got != "three,four" {
+		t.Fatalf("got documents %s, want three,four", got)
+	}
+}
+
+func TestPersistentVectorStoreTornTail(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "store.lcvs")
+	store := openTestStore(t, path)
+	if _, err := store.AddTexts([]string{"kept"}, nil); err != nil {
+		t.Fatal(err)
+	}
+	store.Close()
+	intact, _ := os.Stat(path)
+
+	// an add record cut off halfway, as a crash mid-append leaves it
+	var record bytes.Buffer
+	if err := store.encodeAdd(&record, "torn", make([]float64, 26), documentSchema.Document{PageContent: "torn"}); err != nil {
+		t.Fatal(err)
+	}
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.Write(record.Bytes()[:record.Len()/2])
+	file.Close()
+
+	reopened := openTestStore(t, path)
+	if reopened.Len() != 1 {
+		t.Fatalf("got %d documents, want 1", reopened.Len())
+	}
+	if truncated, _ := os.Stat(path); truncated.Size() != intact.Size() {
+		t.Fatalf("torn record not truncated: %d bytes, want %d", truncated.Size(), intact.Size())
+	}
+	if _, err := reopened.AddTexts([]string{"added"}, nil); err != nil {
+		t.Fatal(err)
+	}
+	reopened.Close()
+
+	again := openTestStore(t, path)
+	defer again.Close()
+	if again.Len() != 2 {
+		t.Fatalf("got %d documents after appending to a repaired file, want 2", again.Len())
+	}
+}
+
+func TestPersistentVectorStoreCorruptRecord(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "store.lcvs")
+	store := openTestStore(t, path)
+	if _, err := store.AddTexts([]string{"first", "second"}, nil); err != nil {
+		t.Fatal(err)
+	}
+	store.Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// break the first document's JSON, keeping its length, so the second record stays readable
+	i := bytes.Index(data, []byte(`"page_content"`))
+	data[i] = '!'
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := OpenPersistentVectorStore(path, embedding.LetterEmbeddings{}, 0); err == nil {
+		t.Fatal("opened a store with a corrupt record")
+	}
+	if after, _ := os.Stat(path); after.Size() != int64(len(data)) {
+		t.Fatalf("corrupt store was truncated to %d bytes, want %d", after.Size(), len(data))
+	}
+}
+
+func TestPersistentVectorStoreDimensionMismatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "store.lcvs")
+	store := openTestStore(t, path)
+	if _, err := store.AddTexts([]string{"text"}, nil); err != nil {
+		t.Fatal(err)
+	}
+	store.Close()
+
+	if _, err := OpenPersistentVectorStore(path, embedding.LetterEmbeddings{}, 3); err == nil {
+		t.Fatal("opened a 26 dimension store as 3 dimensions")
+	}
+}
+
+// writeThreeRecords stores first, second and third and returns the file contents with the offset of
+// every record.
+func writeThreeRecords(t *testing.T, path string) ([]byte, []int) {
+	t.Helper()
+	store := openTestStore(t, path)
+	for _, text := range []string{"first", "second", "third"} {
+		if _, err := store.AddTexts([]string{text}, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	store.Close()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	offsets := []int{headerSize}
+	for len(offsets) < 3 {
+		_, next, ok := readFrame(data, offsets[len(offsets)-1])
+		if !ok {
+			t.Fatalf("record %d does not read back", len(offsets))
+		}
+		offsets = append(offsets, next)
+	}
+	return data, offsets
+}
+
+func TestPersistentVectorStoreDamagedRecords(t *testing.T) {
+	tests := []struct {
+		name string
+		// damage changes the file contents, given the offset of every record
+		damage   func(data []byte, offsets []int)
+		wantErr  bool
+		wantDocs string
+	}{
+		{
+			"middle record length overruns the file",
+			func(data []byte, offsets []int) {
+				binary.LittleEndian.PutUint32(data[offsets[1]:], uint32(len(data)))
+			},
+			true, "",
+		},
+		{
+			"middle record length shortened",
+			func(data []byte, offsets []int) {
+				binary.LittleEndian.PutUint32(data[offsets[1]:], binary.LittleEndian.Uint32(data[offsets[1]:])-1)
+			},
+			true, "",
+		},
+		{
+			"middle record byte flipped",
+			func(data []byte, offsets []int) { data[offsets[2]-1] ^= 0xff },
+			true, "",
+		},
+		{
+			"last record length overruns the file",
+			func(data []byte, offsets []int) {
+				binary.LittleEndian.PutUint32(data[offsets[2]:], uint32(len(data)))
+			},
+			false, "first,second",
+		},
+		{
+			"last record never written out",
+			func(data []byte, offsets []int) {
+				for i := offsets[2] + recordHeaderSize; i < len(data); i++ {
+					data[i] = 0
+				}
+			},
+			false, "first,second",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "store.lcvs")
+			data, offsets := writeThreeRecords(t, path)
+			tt.damage(data, offsets)
+			if err := os.WriteFile(path, data, 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			store, err := OpenPersistentVectorStore(path, embedding.LetterEmbeddings{}, 0)
+			if tt.wantErr {
+				if err == nil {
+					store.Close()
+					t.Fatal("opened a store with a damaged record in the middle")
+				}
+				if after, _ := os.Stat(path); after.Size() != int64(len(data)) {
+					t.Fatalf("damaged store was truncated to %d bytes, want %d", after.Size(), len(data))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer store.Close()
+			docs, _ := store.SimilaritySearch("first second third", 3)
+			contents := pageContents(docs)
+			sort.Strings(contents)
+			if got := strings.Join(contents, ","); got != tt.wantDocs {
+				t.Errorf("got documents %s, want %s", got, tt.wantDocs)
+			}
+			if after, _ := os.Stat(path); after.Size() != int64(offsets[2]) {
+				t.Errorf("got %d bytes, want the last record truncated to %d", after.Size(), offsets[2])
+			}
+		})
+	}
+}
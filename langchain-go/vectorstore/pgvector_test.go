@@ -0,0 +1,143 @@
+package vectorstore
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/William-Bohm/langchain-go/langchain-go/embedding"
+)
+
+// openTestPGVector connects to the Postgres in PGVECTOR_CONNECTION_STRING, see the top of
+// pgvector.go for starting one, and skips the test when it is not set. Every test gets its own
+// emptied collection.
+func openTestPGVector(t *testing.T, options ...PGVectorOption) *PGVector {
+	t.Helper()
+	connectionString := os.Getenv(pgvectorConnectionStringEnvVarName)
+	if connectionString == "" {
+		t.Skip(pgvectorConnectionStringEnvVarName + " not set")
+	}
+	options = append([]PGVectorOption{CollectionName("test_" + strings.ToLower(t.Name())), PreDeleteCollection(true)}, options...)
+	store, err := NewPGVector(connectionString, embedding.LetterEmbeddings{}, 26, options...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		store.DeleteCollection(context.Background())
+		store.Close()
+	})
+	return store
+}
+
+func TestPGVectorColumnDimension(t *testing.T) {
+	store := openTestPGVector(t)
+	var columnType string
+	query := "SELECT format_type(atttypid, atttypmod) FROM pg_attribute WHERE attrelid = $1::regclass AND attname = 'embedding';"
+	if err := store.connectionPool.QueryRow(context.Background(), query, pgEmbeddingTable).Scan(&columnType); err != nil {
+		t.Fatal(err)
+	}
+	if columnType != "vector(26)" {
+		t.Fatalf("embedding column is %s, want vector(26)", columnType)
+	}
+	if err := store.CreateIndex(context.Background(), HNSW, map[string]int{"m": 16}); err != nil {
+		t.Fatalf("index on a fixed dimension column: %v", err)
+	}
+
+	if _, err := NewPGVector(os.Getenv(pgvectorConnectionStringEnvVarName), embedding.LetterEmbeddings{}, 3); err == nil {
+		t.Fatal("opened the 26 dimension table as 3 dimensions")
+	}
+}
+
+func TestPGVectorSearch(t *testing.T) {
+	store := openTestPGVector(t)
+	ids, err := store.AddTexts(
+		[]string{"apple", "banana", "cherry"},
+		[]map[string]interface{}{{"color": "red", "price": 1}, {"color": "yellow", "price": 2}, {"color": "red", "price": 3}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	docs, scores, err := store.SimilaritySearchWithScore("cherry", 1, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(docs) != 1 || docs[0].PageContent != "cherry" || docs[0].Metadata["color"] != "red" {
+		t.Fatalf("got %+v, want cherry with its metadata", docs)
+	}
+	if scores[0] < 0.999 {
+		t.Fatalf("identical text scored %f, want 1", scores[0])
+	}
+
+	docs, err = store.SimilaritySearchWithFilter("banana", 3, And(Eq("color", "red"), Gt("price", 1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(pageContents(docs), ","); got != "cherry" {
+		t.Fatalf("filtered search got %s, want cherry", got)
+	}
+
+	if err := store.Delete(ids[2:]); err != nil {
+		t.Fatal(err)
+	}
+	docs, err = store.SimilaritySearch("cherry", 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(docs) != 2 {
+		t.Fatalf("got %d documents after deleting one of 3, want 2", len(docs))
+	}
+}
+
+func TestPGVectorUpsert(t *testing.T) {
+	store := openTestPGVector(t)
+	ctx := context.Background()
+	if _, err := store.AddTextsWithIDs(ctx, []string{"apple"}, nil, []string{"fruit"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.AddTextsWithIDs(ctx, []string{"banana"}, nil, []string{"fruit"}); err != nil {
+		t.Fatal(err)
+	}
+	docs, err := store.SimilaritySearch("apple", 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(pageContents(docs), ","); got != "banana" {
+		t.Fatalf("got %s, want the replaced text banana only", got)
+	}
+}
+
+func TestPGVectorAddIsAtomic(t *testing.T) {
+	store := openTestPGVector(t, PGBatchSize(1))
+	// JSONB rejects \u0000, so the last of three batches fails
+	_, err := store.AddTexts(
+		[]string{"apple", "banana", "cherry"},
+		[]map[string]interface{}{{"color": "red"}, {"color": "yellow"}, {"color": "\x00"}},
+	)
+	if err == nil {
+		t.Fatal("adding invalid metadata got no error")
+	}
+	docs, err := store.SimilaritySearch("apple", 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(docs) != 0 {
+		t.Fatalf("got %v, want the batches before the failed one rolled back", pageContents(docs))
+	}
+}
+
+func TestPGVectorCancelled(t *testing.T) {
+	store := openTestPGVector(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := store.AddTextsWithContext(ctx, []string{"apple"}, nil); err == nil {
+		t.Error("adding with a cancelled context got no error")
+	}
+	if _, _, err := store.SimilaritySearchWithScoreWithContext(ctx, "apple", 1, nil); err == nil {
+		t.Error("searching with a cancelled context got no error")
+	}
+	if err := store.DeleteWithContext(ctx, []string{"apple"}); err == nil {
+		t.Error("deleting with a cancelled context got no error")
+	}
+}
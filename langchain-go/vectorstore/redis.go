@@ -0,0 +1,750 @@
+package vectorstore
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/William-Bohm/langchain-go/langchain-go/documentStore/documentSchema"
+	"github.com/William-Bohm/langchain-go/langchain-go/embedding/embeddingSchema"
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+)
+
+/*
+ * RedisVectorStore needs Redis with the RediSearch module, which Redis Stack bundles. For local
+ * development one can be started with
+ *
+ *   docker run -d -p 6379:6379 redis/redis-stack-server
+ *
+ * The tests in redis_test.go run against it when REDIS_URL is set, e.g. to redis://localhost:6379/0.
+ */
+
+const (
+	redisURLEnvVarName = "REDIS_URL"
+
+	redisContentField  = "content"
+	redisMetadataField = "metadata"
+	redisVectorField   = "content_vector"
+	redisScoreField    = "vector_score"
+)
+
+// RedisVectorStore keeps documents as Redis hashes indexed by a RediSearch vector index. The whole
+// metadata is stored as JSON; the keys listed in TagFields and NumericFields are also stored as
+// their own hash fields so they can be filtered on.
+type RedisVectorStore struct {
+	Embeddings       embeddingSchema.BaseEmbeddings
+	IndexName        string
+	KeyPrefix        string           `comment:"Prefix of the document hash keys. Defaults to doc:<IndexName>:."`
+	DistanceStrategy DistanceStrategy `comment:"How embeddings are compared: cosine, dot_product or l2."`
+	Algorithm        string           `comment:"Vector index algorithm: FLAT or HNSW."`
+	TagFields        []string         `comment:"Metadata keys indexed as TAG fields, filterable with eq/ne/in/nin."`
+	NumericFields    []string         `comment:"Metadata keys indexed as NUMERIC fields, filterable with eq/ne/in/nin and ranges."`
+	TTL              *time.Duration   `comment:"Expire documents and the index after this long. Nil keeps them forever."`
+	redisClient      *redis.Client
+	indexMu          sync.Mutex
+	dimension        int
+}
+
+// NewRedisVectorStore connects to Redis at url, or REDIS_URL when url is empty, falling back to a
+// local Redis. The index is created on the first AddTexts, once the embedding dimension is known,
+// unless it already exists. A search that finds the index missing, e.g. because a TEMPORARY index
+// expired, creates it again.
+func NewRedisVectorStore(url string, indexName string, embeddings embeddingSchema.BaseEmbeddings, options ...RedisOption) (*RedisVectorStore, error) {
+	if embeddings == nil {
+		return nil, errors.New("redis vector store needs an embeddings model")
+	}
+	if indexName == "" {
+		return nil, errors.New("redis vector store needs an index name")
+	}
+	if url == "" {
+		url = os.Getenv(redisURLEnvVarName)
+	}
+	if url == "" {
+		url = "redis://localhost:6379/0"
+	}
+
+	opt, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &RedisVectorStore{
+		Embeddings:       embeddings,
+		IndexName:        indexName,
+		KeyPrefix:        "doc:" + indexName + ":",
+		DistanceStrategy: Cosine,
+		Algorithm:        "HNSW",
+		redisClient:      redis.NewClient(opt),
+	}
+	for _, option := range options {
+		if err := option(r); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+func (r *RedisVectorStore) indexExists(ctx context.Context) (bool, error) {
+	err := r.redisClient.Do(ctx, "FT.INFO", r.IndexName).Err()
+	if err == nil {
+		return true, nil
+	}
+	if isUnknownIndexError(err) {
+		return false, nil
+	}
+	return false, err
+}
+
+func isUnknownIndexError(err error) bool {
+	if err == nil {
+		return false
+	}
+	message := strings.ToLower(err.Error())
+	return strings.Contains(message, "unknown index") || strings.Contains(message, "no such index")
+}
+
+// ensureIndex creates the index for vectors of dimension on first use and checks dimension
+// against it afterwards.
+func (r *RedisVectorStore) ensureIndex(ctx context.Context, dimension int) error {
+	r.indexMu.Lock()
+	defer r.indexMu.Unlock()
+	if r.dimension == 0 {
+		if err := r.createIndexIfNotExists(ctx, dimension); err != nil {
+			return err
+		}
+		r.dimension = dimension
+	}
+	if dimension != r.dimension {
+		return fmt.Errorf("embedding dimension mismatch: got %d, index has %d", dimension, r.dimension)
+	}
+	return nil
+}
+
+// recreateIndex creates the index again after a search found it missing. FT.CREATE indexes the
+// hashes already stored under KeyPrefix, so documents added since the index expired are found.
+func (r *RedisVectorStore) recreateIndex(ctx context.Context, dimension int) error {
+	r.indexMu.Lock()
+	defer r.indexMu.Unlock()
+	if r.dimension != 0 && dimension != r.dimension {
+		return fmt.Errorf("embedding dimension mismatch: got %d, index has %d", dimension, r.dimension)
+	}
+	if err := r.createIndexIfNotExists(ctx, dimension); err != nil {
+		return err
+	}
+	r.dimension = dimension
+	return nil
+}
+
+func (r *RedisVectorStore) createIndexIfNotExists(ctx context.Context, dimension int) error {
+	exists, err := r.indexExists(ctx)
+	if err != nil || exists {
+		return err
+	}
+
+	args := []interface{}{"FT.CREATE", r.IndexName, "ON", "HASH", "PREFIX", 1, r.KeyPrefix}
+	if r.TTL != nil {
+		// a temporary index drops itself, and its documents, after TTL without use
+		args = append(args, "TEMPORARY", int64(math.Ceil(r.TTL.Seconds())))
+	}
+	args = append(args, "SCHEMA", redisContentField, "TEXT", redisMetadataField, "TEXT", "NOINDEX")
+	for _, field := range r.TagFields {
+		args = append(args, field, "TAG")
+	}
+	for _, field := range r.NumericFields {
+		args = append(args, field, "NUMERIC")
+	}
+	vectorParams := []interface{}{"TYPE", "FLOAT32", "DIM", dimension, "DISTANCE_METRIC", r.distanceMetric()}
+	args = append(args, redisVectorField, "VECTOR", r.Algorithm, len(vectorParams))
+	args = append(args, vectorParams...)
+
+	err = r.redisClient.Do(ctx, args...).Err()
+	// another client may have created it since indexExists
+	if err != nil && strings.Contains(strings.ToLower(err.Error()), "index already exists") {
+		return nil
+	}
+	return err
+}
+
+// DropIndex removes the index, and the document hashes as well when deleteDocuments is set.
+func (r *RedisVectorStore) DropIndex(ctx context.Context, deleteDocuments bool) error {
+	args := []interface{}{"FT.DROPINDEX", r.IndexName}
+	if deleteDocuments {
+		args = append(args, "DD")
+	}
+	return r.redisClient.Do(ctx, args...).Err()
+}
+
+func (r *RedisVectorStore) AddTexts(texts []string, metadatas []map[string]interface{}) ([]string, error) {
+	return r.AddTextsWithContext(context.Background(), texts, metadatas)
+}
+
+func (r *RedisVectorStore) AddTextsWithContext(ctx context.Context, texts []string, metadatas []map[string]interface{}) ([]string, error) {
+	ids := make([]string, len(texts))
+	for i := range ids {
+		ids[i] = uuid.New().String()
+	}
+	return r.AddTextsWithIDs(ctx, texts, metadatas, ids)
+}
+
+// AddTextsWithIDs stores texts under the given ids, replacing documents that already exist.
+func (r *RedisVectorStore) AddTextsWithIDs(ctx context.Context, texts []string, metadatas []map[string]interface{}, ids []string) ([]string, error) {
+	if len(ids) != len(texts) {
+		return nil, fmt.Errorf("got %d ids for %d texts", len(ids), len(texts))
+	}
+	if metadatas != nil && len(metadatas) != len(texts) {
+		return nil, fmt.Errorf("got %d metadatas for %d texts", len(metadatas), len(texts))
+	}
+	if len(texts) == 0 {
+		return []string{}, nil
+	}
+	vectors, err := r.Embeddings.EmbedDocuments(texts)
+	if err != nil {
+		return nil, err
+	}
+	if len(vectors) != len(texts) {
+		return nil, fmt.Errorf("embeddings returned %d vectors for %d texts", len(vectors), len(texts))
+	}
+	if err := r.ensureIndex(ctx, len(vectors[0])); err != nil {
+		return nil, err
+	}
+
+	pipe := r.redisClient.Pipeline()
+	for i, text := range texts {
+		if len(vectors[i]) != len(vectors[0]) {
+			return nil, fmt.Errorf("embedding dimension mismatch: got %d, index has %d", len(vectors[i]), len(vectors[0]))
+		}
+		metadata := map[string]interface{}{}
+		if metadatas != nil && metadatas[i] != nil {
+			metadata = metadatas[i]
+		}
+		fields, err := r.hashFields(text, metadata, vectors[i])
+		if err != nil {
+			return nil, err
+		}
+		key := r.KeyPrefix + ids[i]
+		pipe.Del(ctx, key)
+		pipe.HSet(ctx, key, fields)
+		if r.TTL != nil {
+			pipe.Expire(ctx, key, *r.TTL)
+		}
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+func (r *RedisVectorStore) hashFields(text string, metadata map[string]interface{}, vector []float64) (map[string]interface{}, error) {
+	metadataJSON, err := json.Marshal(metadata)
+	if err != nil {
+		return nil, err
+	}
+	fields := map[string]interface{}{
+		redisContentField:  text,
+		redisMetadataField: string(metadataJSON),
+		redisVectorField:   float32Bytes(vector),
+	}
+	for _, field := range r.TagFields {
+		if value, ok := metadata[field]; ok {
+			values := toInterfaceSlice(value)
+			tags := make([]string, len(values))
+			for i, v := range values {
+				tags[i] = fmt.Sprint(v)
+			}
+			fields[field] = strings.Join(tags, ",")
+		}
+	}
+	for _, field := range r.NumericFields {
+		if value, ok := metadata[field]; ok {
+			number, ok := toFloat(value)
+			if !ok {
+				return nil, fmt.Errorf("numeric metadata field %s has non-numeric value %v", field, value)
+			}
+			fields[field] = number
+		}
+	}
+	return fields, nil
+}
+
+func (r *RedisVectorStore) AddDocuments(docs []documentSchema.Document) ([]string, error) {
+	texts := make([]string, len(docs))
+	metadatas := make([]map[string]interface{}, len(docs))
+	for i, doc := range docs {
+		texts[i] = doc.PageContent
+		metadatas[i] = doc.Metadata
+	}
+	return r.AddTexts(texts, metadatas)
+}
+
+// Delete removes the documents with the given ids.
+func (r *RedisVectorStore) Delete(ids []string) error {
+	return r.DeleteWithContext(context.Background(), ids)
+}
+
+func (r *RedisVectorStore) DeleteWithContext(ctx context.Context, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = r.KeyPrefix + id
+	}
+	return r.redisClient.Del(ctx, keys...).Err()
+}
+
+func (r *RedisVectorStore) SimilaritySearch(query string, k int) ([]documentSchema.Document, error) {
+	return r.SimilaritySearchWithFilter(query, k, nil)
+}
+
+func (r *RedisVectorStore) SimilaritySearchWithFilter(query string, k int, filter Filter) ([]documentSchema.Document, error) {
+	docs, _, err := r.SimilaritySearchWithScore(query, k, filter)
+	return docs, err
+}
+
+// SimilaritySearchWithScore returns the k most similar documents with their DistanceStrategy.Similarity scores.
+func (r *RedisVectorStore) SimilaritySearchWithScore(query string, k int, filter Filter) ([]documentSchema.Document, []float64, error) {
+	return r.SimilaritySearchWithScoreWithContext(context.Background(), query, k, filter)
+}
+
+func (r *RedisVectorStore) SimilaritySearchWithScoreWithContext(ctx context.Context, query string, k int, filter Filter) ([]documentSchema.Document, []float64, error) {
+	embedding, err := r.Embeddings.EmbedQuery(query)
+	if err != nil {
+		return nil, nil, err
+	}
+	return r.similaritySearchByVector(ctx, embedding, k, filter)
+}
+
+func (r *RedisVectorStore) SimilaritySearchWithRelevanceScores(query string, k int) ([]documentSchema.Document, []float64, error) {
+	return r.SimilaritySearchWithRelevanceScoresWithFilter(query, k, nil)
+}
+
+func (r *RedisVectorStore) SimilaritySearchWithRelevanceScoresWithFilter(query string, k int, filter Filter) ([]documentSchema.Document, []float64, error) {
+	docs, scores, err := r.SimilaritySearchWithScore(query, k, filter)
+	if err != nil {
+		return nil, nil, err
+	}
+	for i, score := range scores {
+		scores[i] = r.DistanceStrategy.RelevanceScore(score)
+	}
+	return docs, scores, nil
+}
+
+func (r *RedisVectorStore) SimilaritySearchByVector(embedding []float64, k int) ([]documentSchema.Document, error) {
+	docs, _, err := r.SimilaritySearchByVectorWithScore(embedding, k, nil)
+	return docs, err
+}
+
+func (r *RedisVectorStore) SimilaritySearchByVectorWithScore(embedding []float64, k int, filter Filter) ([]documentSchema.Document, []float64, error) {
+	return r.similaritySearchByVector(context.Background(), embedding, k, filter)
+}
+
+func (r *RedisVectorStore) similaritySearchByVector(ctx context.Context, embedding []float64, k int, filter Filter) ([]documentSchema.Document, []float64, error) {
+	hits, err := r.knn(ctx, embedding, k, filter, false)
+	if err != nil {
+		return nil, nil, err
+	}
+	docs := make([]documentSchema.Document, len(hits))
+	scores := make([]float64, len(hits))
+	for i, hit := range hits {
+		docs[i] = hit.document
+		scores[i] = hit.similarity
+	}
+	return docs, scores, nil
+}
+
+func (r *RedisVectorStore) MaxMarginalRelevanceSearch(query string, k int, fetchK int) ([]documentSchema.Document, error) {
+	return r.MaxMarginalRelevanceSearchWithFilter(query, k, fetchK, DefaultLambdaMult, nil)
+}
+
+func (r *RedisVectorStore) MaxMarginalRelevanceSearchWithFilter(query string, k int, fetchK int, lambdaMult float64, filter Filter) ([]documentSchema.Document, error) {
+	return r.MaxMarginalRelevanceSearchWithContext(context.Background(), query, k, fetchK, lambdaMult, filter)
+}
+
+func (r *RedisVectorStore) MaxMarginalRelevanceSearchWithContext(ctx context.Context, query string, k int, fetchK int, lambdaMult float64, filter Filter) ([]documentSchema.Document, error) {
+	embedding, err := r.Embeddings.EmbedQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	return r.maxMarginalRelevanceSearchByVector(ctx, embedding, k, fetchK, lambdaMult, filter)
+}
+
+func (r *RedisVectorStore) MaxMarginalRelevanceSearchByVector(embedding []float64, k int, fetchK int) ([]documentSchema.Document, error) {
+	return r.MaxMarginalRelevanceSearchByVectorWithFilter(embedding, k, fetchK, DefaultLambdaMult, nil)
+}
+
+// MaxMarginalRelevanceSearchByVectorWithFilter fetches fetchK candidates with their vectors and runs
+// MaximalMarginalRelevance on them client-side.
+func (r *RedisVectorStore) MaxMarginalRelevanceSearchByVectorWithFilter(embedding []float64, k int, fetchK int, lambdaMult float64, filter Filter) ([]documentSchema.Document, error) {
+	return r.maxMarginalRelevanceSearchByVector(context.Background(), embedding, k, fetchK, lambdaMult, filter)
+}
+
+func (r *RedisVectorStore) maxMarginalRelevanceSearchByVector(ctx context.Context, embedding []float64, k int, fetchK int, lambdaMult float64, filter Filter) ([]documentSchema.Document, error) {
+	if k <= 0 {
+		k = DefaultK
+	}
+	if fetchK <= 0 {
+		fetchK = DefaultFetchK
+	}
+	if fetchK < k {
+		fetchK = k
+	}
+	hits, err := r.knn(ctx, embedding, fetchK, filter, true)
+	if err != nil {
+		return nil, err
+	}
+	vectors := make([][]float64, len(hits))
+	for i, hit := range hits {
+		vectors[i] = hit.embedding
+	}
+	selected, err := MaximalMarginalRelevance(embedding, vectors, k, lambdaMult)
+	if err != nil {
+		return nil, err
+	}
+	docs := make([]documentSchema.Document, len(selected))
+	for i, sel := range selected {
+		docs[i] = hits[sel].document
+	}
+	return docs, nil
+}
+
+// FromDocuments adds docs to this store's index and returns the store.
+func (r *RedisVectorStore) FromDocuments(docs []documentSchema.Document) (VectorStore, error) {
+	if _, err := r.AddDocuments(docs); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// FromTexts adds texts to this store's index and returns the store.
+func (r *RedisVectorStore) FromTexts(texts []string) (VectorStore, error) {
+	if _, err := r.AddTexts(texts, nil); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *RedisVectorStore) AsRetriever() (VectorStoreRetriever, error) {
+	retriever, err := NewVectorStoreRetriever(r, SearchTypeSimilarity, map[string]interface{}{})
+	if err != nil {
+		return VectorStoreRetriever{}, err
+	}
+	return *retriever, nil
+}
+
+func (r *RedisVectorStore) Close() error {
+	return r.redisClient.Close()
+}
+
+type redisHit struct {
+	document   documentSchema.Document
+	embedding  []float64
+	similarity float64
+}
+
+// knn runs a RediSearch KNN query, optionally pre-filtered, and returns the hits closest first.
+func (r *RedisVectorStore) knn(ctx context.Context, embedding []float64, k int, filter Filter, withVectors bool) ([]redisHit, error) {
+	if k <= 0 {
+		k = DefaultK
+	}
+	prefilter := "*"
+	if filter != nil {
+		clause, err := filter.Accept(&redisFilterTranslator{tagFields: r.TagFields, numericFields: r.NumericFields})
+		if err != nil {
+			return nil, err
+		}
+		prefilter = clause.(string)
+	}
+
+	returnFields := []interface{}{redisContentField, redisMetadataField, redisScoreField}
+	if withVectors {
+		returnFields = append(returnFields, redisVectorField)
+	}
+	args := []interface{}{
+		"FT.SEARCH", r.IndexName,
+		fmt.Sprintf("%s=>[KNN %d @%s $vector AS %s]", prefilter, k, redisVectorField, redisScoreField),
+		"PARAMS", 2, "vector", float32Bytes(embedding),
+		"SORTBY", redisScoreField, "ASC",
+		"RETURN", len(returnFields),
+	}
+	args = append(args, returnFields...)
+	args = append(args, "LIMIT", 0, k, "DIALECT", 2)
+
+	reply, err := r.redisClient.Do(ctx, args...).Slice()
+	if isUnknownIndexError(err) {
+		if err := r.recreateIndex(ctx, len(embedding)); err != nil {
+			return nil, err
+		}
+		reply, err = r.redisClient.Do(ctx, args...).Slice()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return r.parseSearchReply(reply)
+}
+
+// parseSearchReply reads the RESP2 FT.SEARCH reply: total, then key and field list pairs.
+func (r *RedisVectorStore) parseSearchReply(reply []interface{}) ([]redisHit, error) {
+	if len(reply) == 0 {
+		return nil, errors.New("empty FT.SEARCH reply")
+	}
+	var hits []redisHit
+	for i := 1; i+1 < len(reply); i += 2 {
+		fieldList, ok := reply[i+1].([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("unexpected FT.SEARCH field list %T", reply[i+1])
+		}
+		fields := make(map[string]string, len(fieldList)/2)
+		for j := 0; j+1 < len(fieldList); j += 2 {
+			fields[fmt.Sprint(fieldList[j])] = fmt.Sprint(fieldList[j+1])
+		}
+
+		metadata := map[string]interface{}{}
+		if raw := fields[redisMetadataField]; raw != "" {
+			if err := json.Unmarshal([]byte(raw), &metadata); err != nil {
+				return nil, err
+			}
+		}
+		distance, err := strconv.ParseFloat(fields[redisScoreField], 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid vector score %q: %w", fields[redisScoreField], err)
+		}
+		hit := redisHit{
+			document:   documentSchema.Document{PageContent: fields[redisContentField], Metadata: metadata},
+			similarity: r.similarityFromDistance(distance),
+		}
+		if raw, ok := fields[redisVectorField]; ok {
+			hit.embedding = float64sFromBytes([]byte(raw))
+		}
+		hits = append(hits, hit)
+	}
+	return hits, nil
+}
+
+func (r *RedisVectorStore) distanceMetric() string {
+	switch r.DistanceStrategy {
+	case EuclideanDistance:
+		return "L2"
+	case DotProduct:
+		return "IP"
+	default:
+		return "COSINE"
+	}
+}
+
+// similarityFromDistance turns a RediSearch vector score into the DistanceStrategy.Similarity scale.
+// RediSearch reports 1-cos for COSINE, 1-dot for IP and the squared distance for L2.
+func (r *RedisVectorStore) similarityFromDistance(distance float64) float64 {
+	switch r.DistanceStrategy {
+	case EuclideanDistance:
+		return -math.Sqrt(math.Max(0, distance))
+	default:
+		return 1 - distance
+	}
+}
+
+// redisFilterTranslator renders a Filter in RediSearch query syntax. Only keys declared as tag or
+// numeric fields can be filtered on.
+type redisFilterTranslator struct {
+	tagFields     []string
+	numericFields []string
+}
+
+func (t *redisFilterTranslator) fieldType(attribute string) string {
+	for _, field := range t.tagFields {
+		if field == attribute {
+			return "TAG"
+		}
+	}
+	for _, field := range t.numericFields {
+		if field == attribute {
+			return "NUMERIC"
+		}
+	}
+	return ""
+}
+
+func (t *redisFilterTranslator) VisitComparison(c Comparison) (interface{}, error) {
+	fieldType := t.fieldType(c.Attribute)
+	if fieldType == "" {
+		return nil, fmt.Errorf("metadata key %s is not a tag or numeric field of the index", c.Attribute)
+	}
+	field := "@" + c.Attribute
+
+	switch c.Comparator {
+	case EQ, NE, IN, NIN:
+		values := []interface{}{c.Value}
+		if c.Comparator == IN || c.Comparator == NIN {
+			values = toInterfaceSlice(c.Value)
+		}
+		var clause string
+		if fieldType == "TAG" {
+			tags := make([]string, len(values))
+			for i, v := range values {
+				tags[i] = escapeRedisTag(fmt.Sprint(v))
+			}
+			clause = fmt.Sprintf("%s:{%s}", field, strings.Join(tags, " | "))
+		} else {
+			ranges := make([]string, len(values))
+			for i, v := range values {
+				number, ok := toFloat(v)
+				if !ok {
+					return nil, fmt.Errorf("numeric field %s compared with non-numeric value %v", c.Attribute, v)
+				}
+				ranges[i] = fmt.Sprintf("%s:[%s %s]", field, formatRedisNumber(number), formatRedisNumber(number))
+			}
+			clause = "(" + strings.Join(ranges, " | ") + ")"
+		}
+		if c.Comparator == NE || c.Comparator == NIN {
+			return "(-" + clause + ")", nil
+		}
+		return "(" + clause + ")", nil
+	case GT, GTE, LT, LTE:
+		if fieldType != "NUMERIC" {
+			return nil, fmt.Errorf("range filters need a numeric field, %s is a tag field", c.Attribute)
+		}
+		number, ok := toFloat(c.Value)
+		if !ok {
+			return nil, fmt.Errorf("numeric field %s compared with non-numeric value %v", c.Attribute, c.Value)
+		}
+		value := formatRedisNumber(number)
+		switch c.Comparator {
+		case GT:
+			return fmt.Sprintf("(%s:[(%s +inf])", field, value), nil
+		case GTE:
+			return fmt.Sprintf("(%s:[%s +inf])", field, value), nil
+		case LT:
+			return fmt.Sprintf("(%s:[-inf (%s])", field, value), nil
+		default:
+			return fmt.Sprintf("(%s:[-inf %s])", field, value), nil
+		}
+	default:
+		return nil, fmt.Errorf("comparator %s is not supported by redis", c.Comparator)
+	}
+}
+
+func (t *redisFilterTranslator) VisitOperation(o Operation) (interface{}, error) {
+	clauses := make([]string, len(o.Filters))
+	for i, f := range o.Filters {
+		clause, err := f.Accept(t)
+		if err != nil {
+			return nil, err
+		}
+		clauses[i] = clause.(string)
+	}
+	switch o.Operator {
+	case AND:
+		if len(clauses) == 0 {
+			return "*", nil
+		}
+		return "(" + strings.Join(clauses, " ") + ")", nil
+	case OR:
+		if len(clauses) == 0 {
+			return nil, errors.New("or needs at least one filter")
+		}
+		return "(" + strings.Join(clauses, " | ") + ")", nil
+	case NOT:
+		return "(-" + clauses[0] + ")", nil
+	default:
+		return nil, fmt.Errorf("unsupported operator: %s", o.Operator)
+	}
+}
+
+// escapeRedisTag backslash-escapes the punctuation RediSearch treats as syntax inside tags.
+func escapeRedisTag(tag string) string {
+	var b strings.Builder
+	for _, r := range tag {
+		if strings.ContainsRune(",.<>{}[]\"':;!@#$%^&*()-+=~|/\\ ", r) {
+			b.WriteRune('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func formatRedisNumber(number float64) string {
+	return strconv.FormatFloat(number, 'f', -1, 64)
+}
+
+func float32Bytes(vector []float64) []byte {
+	data := make([]byte, 4*len(vector))
+	for i, v := range vector {
+		binary.LittleEndian.PutUint32(data[4*i:], math.Float32bits(float32(v)))
+	}
+	return data
+}
+
+func float64sFromBytes(data []byte) []float64 {
+	vector := make([]float64, len(data)/4)
+	for i := range vector {
+		vector[i] = float64(math.Float32frombits(binary.LittleEndian.Uint32(data[4*i:])))
+	}
+	return vector
+}
+
+type RedisOption func(*RedisVectorStore) error
+
+func RedisKeyPrefix(prefix string) RedisOption {
+	return func(r *RedisVectorStore) error {
+		if prefix == "" {
+			return errors.New("key prefix can not be empty")
+		}
+		r.KeyPrefix = prefix
+		return nil
+	}
+}
+
+func RedisDistanceStrategy(strategy DistanceStrategy) RedisOption {
+	return func(r *RedisVectorStore) error {
+		if !ValidDistanceStrategy(strategy) {
+			return fmt.Errorf("unknown distance strategy: %s", strategy)
+		}
+		r.DistanceStrategy = strategy
+		return nil
+	}
+}
+
+func RedisAlgorithm(algorithm string) RedisOption {
+	return func(r *RedisVectorStore) error {
+		algorithm = strings.ToUpper(algorithm)
+		if algorithm != "FLAT" && algorithm != "HNSW" {
+			return fmt.Errorf("unknown vector index algorithm: %s", algorithm)
+		}
+		r.Algorithm = algorithm
+		return nil
+	}
+}
+
+func TagFields(fields ...string) RedisOption {
+	return func(r *RedisVectorStore) error {
+		r.TagFields = fields
+		return nil
+	}
+}
+
+func NumericFields(fields ...string) RedisOption {
+	return func(r *RedisVectorStore) error {
+		r.NumericFields = fields
+		return nil
+	}
+}
+
+// RedisTTL makes the store ephemeral: documents expire ttl after they were written, and the index
+// is created as TEMPORARY so it drops itself after ttl without use.
+func RedisTTL(ttl time.Duration) RedisOption {
+	return func(r *RedisVectorStore) error {
+		if ttl <= 0 {
+			return errors.New("ttl must be positive")
+		}
+		r.TTL = &ttl
+		return nil
+	}
+}
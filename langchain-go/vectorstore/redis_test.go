@@ -0,0 +1,244 @@
+package vectorstore
+
+import (
+	"context"
+	"math"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/William-Bohm/langchain-go/langchain-go/documentStore/documentSchema"
+	"github.com/William-Bohm/langchain-go/langchain-go/embedding"
+)
+
+// openTestRedis connects to the Redis in REDIS_URL, see the top of redis.go for starting one, and
+// skips the test when it is not set. Every test gets its own index, dropped with its documents.
+func openTestRedis(t *testing.T, options ...RedisOption) *RedisVectorStore {
+	t.Helper()
+	url := os.Getenv(redisURLEnvVarName)
+	if url == "" {
+		t.Skip(redisURLEnvVarName + " not set")
+	}
+	store, err := NewRedisVectorStore(url, "test_"+strings.ToLower(t.Name()), embedding.LetterEmbeddings{}, options...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// left over from an earlier run that did not clean up
+	store.DropIndex(context.Background(), true)
+	t.Cleanup(func() {
+		store.DropIndex(context.Background(), true)
+		store.Close()
+	})
+	return store
+}
+
+func TestRedisFilterTranslator(t *testing.T) {
+	tests := []struct {
+		name    string
+		filter  Filter
+		want    string
+		wantErr bool
+	}{
+		{"tag eq", Eq("genre", "sci-fi"), `(@genre:{sci\-fi})`, false},
+		{"tag eq with a space", Eq("genre", "film noir"), `(@genre:{film\ noir})`, false},
+		{"tag ne", Ne("genre", "drama"), `(-@genre:{drama})`, false},
+		{"tag in", In("genre", "drama", "comedy"), `(@genre:{drama | comedy})`, false},
+		{"tag nin", Nin("genre", "drama"), `(-@genre:{drama})`, false},
+		{"numeric eq", Eq("year", 1994), `((@year:[1994 1994]))`, false},
+		{"numeric ne", Ne("year", 1994), `(-(@year:[1994 1994]))`, false},
+		{"numeric in", In("rating", 8, 8.5), `((@rating:[8 8] | @rating:[8.5 8.5]))`, false},
+		{"numeric gt", Gt("year", 1990), `(@year:[(1990 +inf])`, false},
+		{"numeric gte", Gte("year", 1990), `(@year:[1990 +inf])`, false},
+		{"numeric lt", Lt("rating", 8.5), `(@rating:[-inf (8.5])`, false},
+		{"numeric lte", Lte("rating", 8.5), `(@rating:[-inf 8.5])`, false},
+		{"range", Range("year", 1990, 2000), `((@year:[1990 +inf]) (@year:[-inf 2000]))`, false},
+		{"empty and", And(), `*`, false},
+		{"or", Or(Eq("genre", "drama"), Gt("rating", 8)), `((@genre:{drama}) | (@rating:[(8 +inf]))`, false},
+		{"not", Not(Eq("genre", "drama")), `(-(@genre:{drama}))`, false},
+		{
+			"nested",
+			Not(Or(Eq("genre", "drama"), And(Gte("year", 1990), Lt("rating", 5)))),
+			`(-((@genre:{drama}) | ((@year:[1990 +inf]) (@rating:[-inf (5]))))`,
+			false,
+		},
+		{"undeclared field", Eq("director", "x"), "", true},
+		{"range on tag", Gt("genre", "a"), "", true},
+		{"numeric against string", Eq("year", "recent"), "", true},
+		{"range against string", Lt("rating", "good"), "", true},
+		{"exists", Exists("genre"), "", true},
+		{"empty or", Or(), "", true},
+		{"error inside an operation", And(Eq("genre", "drama"), Eq("director", "x")), "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			translator := &redisFilterTranslator{tagFields: []string{"genre"}, numericFields: []string{"year", "rating"}}
+			got, err := TranslateFilter(tt.filter, translator)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, want error %v", err, tt.wantErr)
+			}
+			if err == nil && got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEscapeRedisTag(t *testing.T) {
+	tests := []struct {
+		tag  string
+		want string
+	}{
+		{"red", "red"},
+		{"", ""},
+		{"dark red", `dark\ red`},
+		{"a,b|c", `a\,b\|c`},
+		{"user@example.com", `user\@example\.com`},
+		{"{x}", `\{x\}`},
+		{`back\slash`, `back\\slash`},
+		{"état-civil", `état\-civil`},
+	}
+	for _, tt := range tests {
+		if got := escapeRedisTag(tt.tag); got != tt.want {
+			t.Errorf("escapeRedisTag(%q) = %s, want %s", tt.tag, got, tt.want)
+		}
+	}
+}
+
+func TestParseSearchReply(t *testing.T) {
+	apple := []interface{}{redisContentField, "apple", redisMetadataField, `{"color":"red"}`, redisScoreField, "0.25"}
+	tests := []struct {
+		name     string
+		strategy DistanceStrategy
+		reply    []interface{}
+		want     []redisHit
+		wantErr  bool
+	}{
+		{"no hits", Cosine, []interface{}{int64(0)}, nil, false},
+		{
+			"cosine",
+			Cosine,
+			[]interface{}{int64(2), "doc:1", apple, "doc:2", []interface{}{redisContentField, "pear", redisScoreField, "1"}},
+			[]redisHit{
+				{document: documentSchema.Document{PageContent: "apple", Metadata: map[string]interface{}{"color": "red"}}, similarity: 0.75},
+				{document: documentSchema.Document{PageContent: "pear", Metadata: map[string]interface{}{}}, similarity: 0},
+			},
+			false,
+		},
+		{
+			"l2 reports squared distances",
+			EuclideanDistance,
+			[]interface{}{int64(1), "doc:1", []interface{}{redisContentField, "apple", redisScoreField, "4"}},
+			[]redisHit{{document: documentSchema.Document{PageContent: "apple", Metadata: map[string]interface{}{}}, similarity: -2}},
+			false,
+		},
+		{
+			"with vectors",
+			DotProduct,
+			[]interface{}{int64(1), "doc:1", []interface{}{redisContentField, "apple", redisScoreField, "0.5", redisVectorField, string(float32Bytes([]float64{1, 0.5}))}},
+			[]redisHit{{document: documentSchema.Document{PageContent: "apple", Metadata: map[string]interface{}{}}, embedding: []float64{1, 0.5}, similarity: 0.5}},
+			false,
+		},
+		{"empty", Cosine, []interface{}{}, nil, true},
+		{"field list not a list", Cosine, []interface{}{int64(1), "doc:1", "apple"}, nil, true},
+		{"bad score", Cosine, []interface{}{int64(1), "doc:1", []interface{}{redisScoreField, "close"}}, nil, true},
+		{"bad metadata", Cosine, []interface{}{int64(1), "doc:1", []interface{}{redisMetadataField, "{", redisScoreField, "0"}}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &RedisVectorStore{DistanceStrategy: tt.strategy}
+			got, err := r.parseSearchReply(tt.reply)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("got %+v, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedisVectorStoreSearch(t *testing.T) {
+	store := openTestRedis(t, TagFields("color"), NumericFields("price"))
+	ids, err := store.AddTexts(
+		[]string{"apple", "banana", "cherry"},
+		[]map[string]interface{}{{"color": "red", "price": 1}, {"color": "yellow", "price": 2}, {"color": "red", "price": 3}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	docs, scores, err := store.SimilaritySearchWithScore("cherry", 1, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(docs) != 1 || docs[0].PageContent != "cherry" || docs[0].Metadata["color"] != "red" {
+		t.Fatalf("got %+v, want cherry with its metadata", docs)
+	}
+	if math.Abs(scores[0]-1) > 1e-6 {
+		t.Fatalf("identical text scored %f, want 1", scores[0])
+	}
+
+	docs, err = store.SimilaritySearchWithFilter("banana", 3, And(Eq("color", "red"), Gt("price", 1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(pageContents(docs), ","); got != "cherry" {
+		t.Fatalf("filtered search got %s, want cherry", got)
+	}
+
+	docs, err = store.MaxMarginalRelevanceSearch("apple", 2, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(docs) != 2 || docs[0].PageContent != "apple" {
+		t.Fatalf("mmr got %v, want apple first", pageContents(docs))
+	}
+
+	if err := store.Delete(ids[2:]); err != nil {
+		t.Fatal(err)
+	}
+	docs, err = store.SimilaritySearch("cherry", 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(docs) != 2 {
+		t.Fatalf("got %d documents after deleting one of 3, want 2", len(docs))
+	}
+}
+
+func TestRedisVectorStoreUpsert(t *testing.T) {
+	store := openTestRedis(t)
+	ctx := context.Background()
+	if _, err := store.AddTextsWithIDs(ctx, []string{"apple"}, nil, []string{"fruit"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.AddTextsWithIDs(ctx, []string{"banana"}, nil, []string{"fruit"}); err != nil {
+		t.Fatal(err)
+	}
+	docs, err := store.SimilaritySearch("apple", 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(pageContents(docs), ","); got != "banana" {
+		t.Fatalf("got %s, want the replaced text banana only", got)
+	}
+}
+
+func TestRedisVectorStoreCancelled(t *testing.T) {
+	store := openTestRedis(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := store.AddTextsWithContext(ctx, []string{"apple"}, nil); err == nil {
+		t.Error("adding with a cancelled context got no error")
+	}
+	if _, _, err := store.SimilaritySearchWithScoreWithContext(ctx, "apple", 1, nil); err == nil {
+		t.Error("searching with a cancelled context got no error")
+	}
+}
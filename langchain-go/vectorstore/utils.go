@@ -0,0 +1,156 @@
+package vectorstore
+
+import (
+	"errors"
+	"math"
+	"sort"
+)
+
+// DistanceStrategy selects how two embeddings are compared.
+type DistanceStrategy string
+
+const (
+	Cosine            DistanceStrategy = "cosine"
+	DotProduct        DistanceStrategy = "dot_product"
+	EuclideanDistance DistanceStrategy = "l2"
+)
+
+const (
+	DefaultK          = 4
+	DefaultFetchK     = 20
+	DefaultLambdaMult = 0.5
+)
+
+// ValidDistanceStrategy reports whether s is one of the supported strategies.
+func ValidDistanceStrategy(s DistanceStrategy) bool {
+	return s == Cosine || s == DotProduct || s == EuclideanDistance
+}
+
+// Similarity returns a score where higher always means more similar: the cosine similarity,
+// the dot product, or the negated euclidean distance.
+func (s DistanceStrategy) Similarity(a, b []float64) float64 {
+	switch s {
+	case DotProduct:
+		return Dot(a, b)
+	case EuclideanDistance:
+		return -L2Distance(a, b)
+	default:
+		return CosineSimilarity(a, b)
+	}
+}
+
+// RelevanceScore maps a Similarity score onto a 0 to 1 scale, assuming normalized embeddings.
+func (s DistanceStrategy) RelevanceScore(similarity float64) float64 {
+	var score float64
+	switch s {
+	case EuclideanDistance:
+		score = 1.0 + similarity/math.Sqrt2
+	default:
+		score = (1.0 + similarity) / 2
+	}
+	return math.Max(0, math.Min(1, score))
+}
+
+func Dot(a, b []float64) float64 {
+	n := len(a)
+	if len(b) < n {
+		n = len(b)
+	}
+	var sum float64
+	for i := 0; i < n; i++ {
+		sum += a[i] * b[i]
+	}
+	return sum
+}
+
+func L2Distance(a, b []float64) float64 {
+	if len(a) != len(b) {
+		return math.Inf(1)
+	}
+	var sum float64
+	for i := range a {
+		d := a[i] - b[i]
+		sum += d * d
+	}
+	return math.Sqrt(sum)
+}
+
+// CosineSimilarity returns 0 for mismatched or zero-length vectors.
+func CosineSimilarity(a, b []float64) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	normA := math.Sqrt(Dot(a, a))
+	normB := math.Sqrt(Dot(b, b))
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return Dot(a, b) / (normA * normB)
+}
+
+// MaximalMarginalRelevance picks k indexes of embeddings, trading similarity to the query against
+// similarity to the already picked ones. lambdaMult 1 is pure similarity, 0 is maximum diversity.
+func MaximalMarginalRelevance(queryEmbedding []float64, embeddings [][]float64, k int, lambdaMult float64) ([]int, error) {
+	if lambdaMult < 0 || lambdaMult > 1 {
+		return nil, errors.New("lambda_mult must be between 0 and 1")
+	}
+	if k <= 0 || len(embeddings) == 0 {
+		return []int{}, nil
+	}
+	if k > len(embeddings) {
+		k = len(embeddings)
+	}
+
+	querySimilarity := make([]float64, len(embeddings))
+	for i, embedding := range embeddings {
+		querySimilarity[i] = CosineSimilarity(queryEmbedding, embedding)
+	}
+
+	mostSimilar := 0
+	for i := range querySimilarity {
+		if querySimilarity[i] > querySimilarity[mostSimilar] {
+			mostSimilar = i
+		}
+	}
+	selected := []int{mostSimilar}
+	isSelected := map[int]bool{mostSimilar: true}
+
+	for len(selected) < k {
+		bestScore := math.Inf(-1)
+		bestIdx := -1
+		for i, embedding := range embeddings {
+			if isSelected[i] {
+				continue
+			}
+			redundancy := math.Inf(-1)
+			for _, j := range selected {
+				redundancy = math.Max(redundancy, CosineSimilarity(embedding, embeddings[j]))
+			}
+			score := lambdaMult*querySimilarity[i] - (1-lambdaMult)*redundancy
+			if score > bestScore {
+				bestScore, bestIdx = score, i
+			}
+		}
+		if bestIdx == -1 {
+			break
+		}
+		selected = append(selected, bestIdx)
+		isSelected[bestIdx] = true
+	}
+	return selected, nil
+}
+
+// topK returns the indexes of the k highest scores, best first.
+func topK(scores []float64, k int) []int {
+	idxs := make([]int, len(scores))
+	for i := range idxs {
+		idxs[i] = i
+	}
+	sort.SliceStable(idxs, func(a, b int) bool {
+		return scores[idxs[a]] > scores[idxs[b]]
+	})
+	if k < len(idxs) {
+		idxs = idxs[:k]
+	}
+	return idxs
+}